@@ -1,14 +1,32 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
 	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/history"
+	"github.com/jamesmichael/nagiosapi/nagios/nsca"
 	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
 	"github.com/jamesmichael/nagiosapi/server"
+	"github.com/jamesmichael/nagiosapi/service/acknowledgement"
+	"github.com/jamesmichael/nagiosapi/service/alertmanager"
+	"github.com/jamesmichael/nagiosapi/service/command"
+	"github.com/jamesmichael/nagiosapi/service/comment"
+	"github.com/jamesmichael/nagiosapi/service/downtime"
+	"github.com/jamesmichael/nagiosapi/service/heartbeat"
+	"github.com/jamesmichael/nagiosapi/service/report"
 	"github.com/jamesmichael/nagiosapi/service/submission"
+	"github.com/jamesmichael/nagiosapi/service/webhook"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -40,7 +58,96 @@ func init() {
 	viper.SetDefault("nagios.external_commands_file", "/usr/local/nagios/var/rw/nagios.cmd")
 	viper.BindPFlag("nagios.external_commands_file", serverCmd.Flags().Lookup("nagios.external-commands-file"))
 
+	var logFile string
+	serverCmd.Flags().StringVar(&logFile, "nagios.log-file", "", "path to nagios.log")
+	viper.SetDefault("nagios.log_file", "/var/log/nagios/nagios.log")
+	viper.BindPFlag("nagios.log_file", serverCmd.Flags().Lookup("nagios.log-file"))
+
+	var logArchivePath string
+	serverCmd.Flags().StringVar(&logArchivePath, "nagios.log-archive-path", "", "path to nagios log archives")
+	viper.SetDefault("nagios.log_archive_path", "/var/log/nagios/archives")
+	viper.BindPFlag("nagios.log_archive_path", serverCmd.Flags().Lookup("nagios.log-archive-path"))
+
+	var objectsCacheFile string
+	serverCmd.Flags().StringVar(&objectsCacheFile, "nagios.objects-cache-file", "", "path to objects.cache")
+	viper.SetDefault("nagios.objects_cache_file", "/usr/local/nagios/var/objects.cache")
+	viper.BindPFlag("nagios.objects_cache_file", serverCmd.Flags().Lookup("nagios.objects-cache-file"))
+
+	var spoolDir string
+	serverCmd.Flags().StringVar(&spoolDir, "nagios.spool-dir", "", "directory used to spool queued external commands")
+	viper.SetDefault("nagios.spool_dir", "")
+	viper.BindPFlag("nagios.spool_dir", serverCmd.Flags().Lookup("nagios.spool-dir"))
+
+	viper.SetDefault("nagios.spool_max_bytes", 64<<20)
+	viper.SetDefault("nagios.queue_size", cmd.DefaultWriteBufferSize)
+	viper.SetDefault("nagios.overflow_policy", "reject")
+	viper.SetDefault("nagios.delivery_policy", "all")
+	viper.SetDefault("nagios.coalesce_results", false)
+
+	viper.SetDefault("nagios.retry.initial_backoff", 1)
+	viper.SetDefault("nagios.retry.max_backoff", 60)
+	viper.SetDefault("nagios.retry.max_attempts", 0)
+	viper.SetDefault("nagios.retry.max_age", 0)
+	viper.SetDefault("nagios.dead_letter_size", cmd.DefaultDeadLetterSize)
+	viper.SetDefault("nagios.drain_timeout", 30)
+
+	var submissionTransport string
+	serverCmd.Flags().StringVar(&submissionTransport, "nagios.submission-transport", "", "transport for passive check results, fifo, checkresult or queryhandler")
+	viper.SetDefault("nagios.submission_transport", "fifo")
+	viper.BindPFlag("nagios.submission_transport", serverCmd.Flags().Lookup("nagios.submission-transport"))
+
+	var checkResultPath string
+	serverCmd.Flags().StringVar(&checkResultPath, "nagios.check-result-path", "", "path to the nagios check_result_path")
+	viper.SetDefault("nagios.check_result_path", cmd.DefaultCheckResultPath)
+	viper.BindPFlag("nagios.check_result_path", serverCmd.Flags().Lookup("nagios.check-result-path"))
+
+	var queryHandlerSocket string
+	serverCmd.Flags().StringVar(&queryHandlerSocket, "nagios.query-handler-socket", "", "path to nagios.qh")
+	viper.SetDefault("nagios.query_handler_socket", cmd.DefaultQueryHandlerSocket)
+	viper.BindPFlag("nagios.query_handler_socket", serverCmd.Flags().Lookup("nagios.query-handler-socket"))
+
+	viper.SetDefault("nagios.query_handler_timeout", 10)
+
+	viper.SetDefault("downtime.wait_timeout", 70)
+
+	viper.SetDefault("submission.max_clock_skew", int(submission.DefaultMaxClockSkew/time.Second))
+	viper.SetDefault("submission.max_output_length", submission.DefaultMaxOutputLength)
+	viper.SetDefault("submission.unknown_targets", "allow")
+	viper.SetDefault("submission.unknown_target_size", submission.DefaultUnknownTargetSize)
+
+	viper.SetDefault("alertmanager.host_template", alertmanager.DefaultHostTemplate)
+	viper.SetDefault("alertmanager.service_template", alertmanager.DefaultServiceTemplate)
+	viper.SetDefault("alertmanager.severity_label", alertmanager.DefaultSeverityLabel)
+	viper.SetDefault("alertmanager.severities", map[string]string{
+		"critical": "critical",
+		"error":    "critical",
+		"warning":  "warning",
+		"info":     "ok",
+		"none":     "ok",
+	})
+	viper.SetDefault("alertmanager.default_state", "warning")
+
+	viper.SetDefault("heartbeats.check_interval", int(heartbeat.DefaultCheckInterval/time.Second))
+	viper.SetDefault("heartbeats.expected", []interface{}{})
+
+	viper.SetDefault("hooks", []interface{}{})
+
+	viper.SetDefault("nrdp.enabled", false)
+	viper.SetDefault("nrdp.tokens", []string{})
+
+	viper.SetDefault("nsca.enabled", false)
+	viper.SetDefault("nsca.addr", ":5667")
+	viper.SetDefault("nsca.decryption_method", "xor")
+	viper.SetDefault("nsca.password", "")
+	viper.SetDefault("nsca.output_length", nsca.DefaultOutputLength)
+	viper.SetDefault("nsca.max_packet_age", int(nsca.DefaultMaxPacketAge/time.Second))
+	viper.SetDefault("nsca.timeout", int(nsca.DefaultTimeout/time.Second))
+
+	viper.SetDefault("commands.allowed", []string{})
+
 	viper.SetDefault("app.production", true)
+
+	viper.SetDefault("api.shutdown_timeout", 30)
 }
 
 func serverCmdFunc(cmd *cobra.Command, args []string) {
@@ -50,35 +157,297 @@ func serverCmdFunc(cmd *cobra.Command, args []string) {
 		log,
 	)
 
-	server.RegisterPassiveCommandService(
-		mustBuildCommandService(log),
+	commandWriter := mustBuildCommandWriter(log)
+	server.RegisterDeadLetterService(commandWriter)
+	server.RegisterWriterStatsService(commandWriter)
+
+	statusRepo := mustBuildStatusRepo(log)
+	server.RegisterStatusService(statusRepo)
+
+	submissionService := mustBuildCommandService(log, commandWriter, statusRepo)
+	server.RegisterUnknownTargetService(submissionService)
+
+	// every source of passive results goes through the heartbeats, so each
+	// result counts as seen.
+	heartbeatService := mustBuildHeartbeatService(log, submissionService)
+	server.RegisterPassiveCommandService(heartbeatService)
+	server.RegisterHeartbeatService(heartbeatService)
+
+	server.RegisterAlertmanagerService(
+		mustBuildAlertmanagerService(log, heartbeatService),
 	)
 
-	server.RegisterStatusService(
-		mustBuildStatusRepo(log),
+	server.RegisterWebhookService(
+		mustBuildWebhookService(log, heartbeatService),
 	)
 
+	if viper.GetBool("nrdp.enabled") {
+		server.RegisterNRDPService(heartbeatService, mustReadNRDPTokens(log))
+	}
+
+	var nscaServer *nsca.Server
+	if viper.GetBool("nsca.enabled") {
+		nscaServer = mustStartNSCAServer(log, heartbeatService)
+	}
+
+	server.RegisterExternalCommandService(
+		mustBuildExternalCommandService(log, commandWriter),
+	)
+
+	server.RegisterCommentService(
+		mustBuildCommentService(log, commandWriter, statusRepo),
+	)
+
+	server.RegisterAcknowledgementService(
+		mustBuildAcknowledgementService(log, commandWriter, statusRepo),
+	)
+
+	server.RegisterDowntimeService(
+		mustBuildDowntimeService(log, commandWriter, statusRepo),
+	)
+
+	server.RegisterReportService(
+		mustBuildReportService(log, statusRepo),
+	)
+
+	// signals are caught before the server starts, so one sent while it is
+	// starting still shuts it down cleanly.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		waitForShutdown(log, signals, server, nscaServer, heartbeatService, commandWriter)
+	}()
+
 	server.ServeHTTP()
+	<-stopped
 }
 
-func mustBuildCommandService(l *zap.Logger) *submission.Service {
-	commandsFile := viper.GetString("nagios.external_commands_file")
-	commandWriter, err := cmd.NewWriter(
-		cmd.WithFilename(commandsFile),
-		cmd.WithLogger(l),
-		cmd.WithNonBlocking(true),
+// waitForShutdown blocks until a signal is received, then stops the API and
+// NSCA servers and the heartbeats, then drains the external commands queue.
+func waitForShutdown(l *zap.Logger, signals chan os.Signal, s *server.Server, nscaServer *nsca.Server, heartbeatService *heartbeat.Service, commandWriter *cmd.FanOut) {
+	sig := <-signals
+	signal.Stop(signals)
+
+	l.Info("shutting down",
+		zap.String("signal", sig.String()),
 	)
+
+	timeout := time.Duration(viper.GetInt("api.shutdown_timeout")) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// stop accepting requests and results first, so no more commands are
+	// queued.
+	if err := s.Shutdown(ctx); err != nil {
+		l.Warn("unable to stop HTTP API cleanly",
+			zap.Error(err),
+		)
+	}
+
+	if nscaServer != nil {
+		if err := nscaServer.Shutdown(ctx); err != nil {
+			l.Warn("unable to stop NSCA server cleanly",
+				zap.Error(err),
+			)
+		}
+	}
+
+	heartbeatService.Close()
+
+	// the queue gets its own deadline, so a slow request does not leave it
+	// no time to drain.
+	drainTimeout := time.Duration(viper.GetInt("nagios.drain_timeout")) * time.Second
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer drainCancel()
+
+	if err := commandWriter.Close(drainCtx); err != nil {
+		l.Warn("unable to write all queued commands",
+			zap.Error(err),
+		)
+	}
+}
+
+// mustReadNRDPTokens returns the tokens which NRDP clients may authenticate
+// with.
+func mustReadNRDPTokens(l *zap.Logger) []string {
+	var tokens []string
+	for _, t := range viper.GetStringSlice("nrdp.tokens") {
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+
+	if len(tokens) == 0 {
+		l.Fatal("nrdp is enabled, but no nrdp.tokens are set")
+	}
+
+	return tokens
+}
+
+// commandTarget is an entry in the nagios.external_commands_file list.
+type commandTarget struct {
+	Name string `mapstructure:"name"`
+	File string `mapstructure:"file"`
+}
+
+// mustReadCommandTargets reads nagios.external_commands_file, which is either
+// the path to a single external commands file, or a list of named targets.
+func mustReadCommandTargets(l *zap.Logger) []commandTarget {
+	if file, ok := viper.Get("nagios.external_commands_file").(string); ok {
+		return []commandTarget{{Name: "default", File: file}}
+	}
+
+	var targets []commandTarget
+	if err := viper.UnmarshalKey("nagios.external_commands_file", &targets); err != nil {
+		l.Fatal("invalid external commands file targets",
+			zap.Error(err),
+		)
+	}
+
+	if len(targets) == 0 {
+		l.Fatal("no external commands file targets")
+	}
+
+	for _, t := range targets {
+		if t.Name == "" || t.File == "" {
+			l.Fatal("external commands file targets must have a name and a file",
+				zap.String("target", t.Name),
+			)
+		}
+	}
+
+	return targets
+}
+
+func mustBuildCommandWriter(l *zap.Logger) *cmd.FanOut {
+	targets := mustReadCommandTargets(l)
+
+	overflow, err := cmd.ParseOverflowPolicy(viper.GetString("nagios.overflow_policy"))
+	if err != nil {
+		l.Fatal("invalid external commands overflow policy",
+			zap.Error(err),
+		)
+	}
+
+	// a blocked standby would hold up writes to the other targets.
+	if overflow == cmd.OverflowBlock && len(targets) > 1 {
+		l.Fatal("the block overflow policy can not be used with multiple external commands file targets")
+	}
+
+	delivery, err := cmd.ParseDeliveryPolicy(viper.GetString("nagios.delivery_policy"))
+	if err != nil {
+		l.Fatal("invalid external commands delivery policy",
+			zap.Error(err),
+		)
+	}
+
+	writers := make([]cmd.Target, 0, len(targets))
+	for _, t := range targets {
+		opts := []cmd.WriterOption{
+			cmd.WithName(t.Name),
+			cmd.WithFilename(t.File),
+			cmd.WithLogger(l),
+			cmd.WithBufferSize(viper.GetInt("nagios.queue_size")),
+			cmd.WithOverflowPolicy(overflow),
+			cmd.WithCoalescing(viper.GetBool("nagios.coalesce_results")),
+			cmd.WithRetryPolicy(cmd.RetryPolicy{
+				InitialBackoff: time.Duration(viper.GetInt("nagios.retry.initial_backoff")) * time.Second,
+				MaxBackoff:     time.Duration(viper.GetInt("nagios.retry.max_backoff")) * time.Second,
+				MaxAttempts:    viper.GetInt("nagios.retry.max_attempts"),
+				MaxAge:         time.Duration(viper.GetInt("nagios.retry.max_age")) * time.Second,
+			}),
+			cmd.WithDeadLetterSink(cmd.NewDeadLetterBuffer(viper.GetInt("nagios.dead_letter_size"))),
+		}
+
+		if spoolDir := viper.GetString("nagios.spool_dir"); spoolDir != "" {
+			// each target keeps its own journal, so one target's backlog is
+			// not replayed to the others.
+			if len(targets) > 1 {
+				spoolDir = filepath.Join(spoolDir, t.Name)
+			}
+			opts = append(opts, cmd.WithSpool(spoolDir, viper.GetInt64("nagios.spool_max_bytes")))
+		}
+
+		w, err := cmd.NewWriter(opts...)
+		if err != nil {
+			l.Fatal("unable to open new external commands writer",
+				zap.String("target", t.Name),
+				zap.String("filename", t.File),
+				zap.Error(err),
+			)
+		}
+
+		writers = append(writers, cmd.Target{Name: t.Name, Writer: w})
+	}
+
+	commandWriter, err := cmd.NewFanOut(delivery, writers...)
 	if err != nil {
-		l.Fatal("unable to open new external commands writer",
-			zap.String("filename", commandsFile),
+		l.Fatal("unable to create external commands writer",
 			zap.Error(err),
 		)
 	}
 
-	go commandWriter.Run()
+	go commandWriter.Run(context.Background())
+
+	return commandWriter
+}
+
+func mustBuildCommandService(l *zap.Logger, commandWriter *cmd.FanOut, statusRepo *statusdata.Repository) *submission.Service {
+	var w io.Writer = commandWriter
+
+	// commands written to the external commands file must fit in a single
+	// atomic write.
+	maxCommandLength := 0
+
+	switch transport := viper.GetString("nagios.submission_transport"); transport {
+	case "fifo":
+		maxCommandLength = cmd.PipeBuf
+	case "checkresult":
+		path := viper.GetString("nagios.check_result_path")
+		checkResultWriter, err := cmd.NewCheckResultWriter(path)
+		if err != nil {
+			l.Fatal("unable to open check result path",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+		w = checkResultWriter
+	case "queryhandler":
+		path := viper.GetString("nagios.query_handler_socket")
+		queryHandlerWriter, err := cmd.NewQueryHandlerWriter(path,
+			time.Duration(viper.GetInt("nagios.query_handler_timeout"))*time.Second,
+		)
+		if err != nil {
+			l.Fatal("unable to use query handler",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+		w = queryHandlerWriter
+	default:
+		l.Fatal("unknown submission transport",
+			zap.String("transport", transport),
+		)
+	}
+
+	unknown, err := submission.ParseUnknownTargetPolicy(viper.GetString("submission.unknown_targets"))
+	if err != nil {
+		l.Fatal("invalid unknown target policy",
+			zap.Error(err),
+		)
+	}
 
 	svc, err := submission.NewService(
-		submission.WithExternalCommandsWriter(commandWriter),
+		submission.WithExternalCommandsWriter(w),
+		submission.WithRepository(statusRepo),
+		submission.WithUnknownTargetPolicy(unknown),
+		submission.WithUnknownTargetSize(viper.GetInt("submission.unknown_target_size")),
+		submission.WithMaxClockSkew(time.Duration(viper.GetInt("submission.max_clock_skew"))*time.Second),
+		submission.WithMaxOutputLength(viper.GetInt("submission.max_output_length")),
+		submission.WithMaxCommandLength(maxCommandLength),
 	)
 	if err != nil {
 		l.Fatal("unable to create submission service",
@@ -88,6 +457,274 @@ func mustBuildCommandService(l *zap.Logger) *submission.Service {
 	return svc
 }
 
+func mustBuildAlertmanagerService(l *zap.Logger, submitter alertmanager.Submitter) *alertmanager.Service {
+	severities := make(map[string]xdata.ServiceState)
+	for severity, name := range viper.GetStringMapString("alertmanager.severities") {
+		state, err := xdata.ParseServiceState([]byte(name))
+		if err != nil {
+			l.Fatal("invalid alertmanager severity state",
+				zap.String("severity", severity),
+				zap.String("state", name),
+			)
+		}
+		severities[severity] = state
+	}
+
+	defaultState, err := xdata.ParseServiceState([]byte(viper.GetString("alertmanager.default_state")))
+	if err != nil {
+		l.Fatal("invalid alertmanager default state",
+			zap.String("state", viper.GetString("alertmanager.default_state")),
+		)
+	}
+
+	svc, err := alertmanager.NewService(
+		alertmanager.WithSubmitter(submitter),
+		alertmanager.WithHostTemplate(viper.GetString("alertmanager.host_template")),
+		alertmanager.WithServiceTemplate(viper.GetString("alertmanager.service_template")),
+		alertmanager.WithSeverityLabel(viper.GetString("alertmanager.severity_label")),
+		alertmanager.WithSeverities(severities, defaultState),
+	)
+	if err != nil {
+		l.Fatal("unable to create alertmanager service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+// heartbeatConfig is an entry in the heartbeats.expected list. Heartbeats
+// without a service are for host results.
+type heartbeatConfig struct {
+	Host    string `mapstructure:"host"`
+	Service string `mapstructure:"service"`
+	Every   string `mapstructure:"every"`
+}
+
+func mustBuildHeartbeatService(l *zap.Logger, submissionService *submission.Service) *heartbeat.Service {
+	var expected []heartbeatConfig
+	if err := viper.UnmarshalKey("heartbeats.expected", &expected); err != nil {
+		l.Fatal("invalid expected heartbeats",
+			zap.Error(err),
+		)
+	}
+
+	opts := []heartbeat.ServiceOption{
+		heartbeat.WithSubmitter(submissionService),
+		heartbeat.WithCheckInterval(time.Duration(viper.GetInt("heartbeats.check_interval")) * time.Second),
+		heartbeat.WithLogger(l),
+	}
+	for _, h := range expected {
+		every, err := time.ParseDuration(h.Every)
+		if err != nil {
+			l.Fatal("invalid heartbeat interval",
+				zap.String("host", h.Host),
+				zap.String("service", h.Service),
+				zap.Error(err),
+			)
+		}
+		opts = append(opts, heartbeat.WithHeartbeat(h.Host, h.Service, every))
+	}
+
+	svc, err := heartbeat.NewService(opts...)
+	if err != nil {
+		l.Fatal("unable to create heartbeat service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+// hookConfig is an entry in the hooks list.
+type hookConfig struct {
+	Name         string            `mapstructure:"name"`
+	Host         string            `mapstructure:"host"`
+	Service      string            `mapstructure:"service"`
+	Status       string            `mapstructure:"status"`
+	Output       string            `mapstructure:"output"`
+	States       map[string]string `mapstructure:"states"`
+	Secret       string            `mapstructure:"secret"`
+	SecretHeader string            `mapstructure:"secret_header"`
+	Signature    struct {
+		Secret    string `mapstructure:"secret"`
+		Header    string `mapstructure:"header"`
+		Prefix    string `mapstructure:"prefix"`
+		Algorithm string `mapstructure:"algorithm"`
+	} `mapstructure:"signature"`
+}
+
+func mustBuildWebhookService(l *zap.Logger, submitter webhook.Submitter) *webhook.Service {
+	var hooks []hookConfig
+	if err := viper.UnmarshalKey("hooks", &hooks); err != nil {
+		l.Fatal("invalid hooks",
+			zap.Error(err),
+		)
+	}
+
+	opts := []webhook.ServiceOption{
+		webhook.WithSubmitter(submitter),
+	}
+	for _, h := range hooks {
+		opts = append(opts, webhook.WithHook(webhook.Hook{
+			Name:         h.Name,
+			Host:         h.Host,
+			Service:      h.Service,
+			Status:       h.Status,
+			Output:       h.Output,
+			States:       h.States,
+			Secret:       h.Secret,
+			SecretHeader: h.SecretHeader,
+			Signature: webhook.Signature{
+				Secret:    h.Signature.Secret,
+				Header:    h.Signature.Header,
+				Prefix:    h.Signature.Prefix,
+				Algorithm: h.Signature.Algorithm,
+			},
+		}))
+	}
+
+	svc, err := webhook.NewService(opts...)
+	if err != nil {
+		l.Fatal("unable to create webhook service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+// mustStartNSCAServer starts a listener for send_nsca clients, which submits
+// their results through the submission service.
+func mustStartNSCAServer(l *zap.Logger, svc *heartbeat.Service) *nsca.Server {
+	method, err := nsca.ParseEncryptionMethod(viper.GetString("nsca.decryption_method"))
+	if err != nil {
+		l.Fatal("invalid NSCA decryption method",
+			zap.Error(err),
+		)
+	}
+
+	s, err := nsca.NewServer(
+		nsca.WithHandler(func(p nsca.Packet) error {
+			// like the nsca daemon, results are timed when they are received.
+			if p.ServiceName == "" {
+				_, err := svc.SubmitHostResult(0, xdata.HostState(p.ReturnCode), p.Hostname, p.Output)
+				return err
+			}
+			_, err := svc.SubmitResult(0, xdata.ServiceState(p.ReturnCode), p.Hostname, p.ServiceName, p.Output)
+			return err
+		}),
+		nsca.WithEncryption(method, viper.GetString("nsca.password")),
+		nsca.WithOutputLength(viper.GetInt("nsca.output_length")),
+		nsca.WithMaxPacketAge(time.Duration(viper.GetInt("nsca.max_packet_age"))*time.Second),
+		nsca.WithTimeout(time.Duration(viper.GetInt("nsca.timeout"))*time.Second),
+		nsca.WithLogger(l.With(zap.String("listener", "nsca"))),
+	)
+	if err != nil {
+		l.Fatal("unable to create NSCA server",
+			zap.Error(err),
+		)
+	}
+
+	addr := viper.GetString("nsca.addr")
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		l.Fatal("unable to start NSCA server",
+			zap.String("addr", addr),
+			zap.Error(err),
+		)
+	}
+
+	l.Info("starting NSCA server",
+		zap.String("addr", addr),
+		zap.String("decryption method", method.String()),
+	)
+
+	go func() {
+		if err := s.Serve(listener); err != nil && !errors.Is(err, nsca.ErrServerClosed) {
+			l.Error("NSCA server stopped",
+				zap.Error(err),
+			)
+		}
+	}()
+
+	return s
+}
+
+func mustBuildExternalCommandService(l *zap.Logger, commandWriter *cmd.FanOut) *command.Service {
+	svc, err := command.NewService(
+		command.WithExternalCommandsWriter(commandWriter),
+		command.WithAllowedCommands(viper.GetStringSlice("commands.allowed")),
+	)
+	if err != nil {
+		l.Fatal("unable to create external command service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+func mustBuildCommentService(l *zap.Logger, commandWriter *cmd.FanOut, statusRepo *statusdata.Repository) *comment.Service {
+	svc, err := comment.NewService(
+		comment.WithExternalCommandsWriter(commandWriter),
+		comment.WithRepository(statusRepo),
+	)
+	if err != nil {
+		l.Fatal("unable to create comment service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+func mustBuildAcknowledgementService(l *zap.Logger, commandWriter *cmd.FanOut, statusRepo *statusdata.Repository) *acknowledgement.Service {
+	svc, err := acknowledgement.NewService(
+		acknowledgement.WithExternalCommandsWriter(commandWriter),
+		acknowledgement.WithRepository(statusRepo),
+	)
+	if err != nil {
+		l.Fatal("unable to create acknowledgement service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+func mustBuildDowntimeService(l *zap.Logger, commandWriter *cmd.FanOut, statusRepo *statusdata.Repository) *downtime.Service {
+	// new downtimes can only be found if the status file is reloaded.
+	waitTimeout := time.Duration(viper.GetInt("downtime.wait_timeout")) * time.Second
+	if !viper.GetBool("nagios.reload_status_file") {
+		waitTimeout = 0
+	}
+
+	svc, err := downtime.NewService(
+		downtime.WithExternalCommandsWriter(commandWriter),
+		downtime.WithRepository(statusRepo),
+		downtime.WithObjectsCacheFile(viper.GetString("nagios.objects_cache_file")),
+		downtime.WithWaitTimeout(waitTimeout),
+	)
+	if err != nil {
+		l.Fatal("unable to create downtime service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
+func mustBuildReportService(l *zap.Logger, statusRepo *statusdata.Repository) *report.Service {
+	svc, err := report.NewService(
+		report.WithStateHistory(&history.Archive{
+			LogFile:     viper.GetString("nagios.log_file"),
+			ArchivePath: viper.GetString("nagios.log_archive_path"),
+		}),
+		report.WithObjectsCacheFile(viper.GetString("nagios.objects_cache_file")),
+		report.WithStatusRepository(statusRepo),
+	)
+	if err != nil {
+		l.Fatal("unable to create report service",
+			zap.Error(err),
+		)
+	}
+	return svc
+}
+
 func mustBuildLog() *zap.Logger {
 	var log *zap.Logger
 	var err error
@@ -0,0 +1,139 @@
+// Jsonpath provides routines for selecting a value from a decoded JSON
+// document, using a subset of JSONPath.
+//
+// Paths start with '$', followed by any number of child names, as '.name' or
+// "['name']", and array indexes, as '[0]'. Negative indexes count from the
+// end of the array. Wildcards, slices, filters and recursive descent are not
+// supported.
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when a path does not match any value.
+var ErrNotFound = errors.New("not found")
+
+// Path is a parsed JSONPath expression.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+// step selects either a child by name, or an array element by index.
+type step struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// Parse parses a JSONPath expression.
+func Parse(expr string) (*Path, error) {
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("jsonpath '%s' must start with '$'", expr)
+	}
+	s = s[1:]
+
+	p := Path{expr: expr}
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("jsonpath '%s' has an empty name", expr)
+			}
+			p.steps = append(p.steps, step{name: s[:end]})
+			s = s[end:]
+
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath '%s' has an unclosed '['", expr)
+			}
+			st, err := parseBracket(s[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath '%s': %w", expr, err)
+			}
+			p.steps = append(p.steps, st)
+			s = s[end+1:]
+
+		default:
+			return nil, fmt.Errorf("jsonpath '%s' has an unexpected '%c'", expr, s[0])
+		}
+	}
+
+	return &p, nil
+}
+
+// parseBracket parses the inside of a bracket, either a quoted name or an
+// index.
+func parseBracket(s string) (step, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return step{name: s[1 : len(s)-1]}, nil
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return step{}, fmt.Errorf("unsupported selector '[%s]'", s)
+	}
+	return step{index: i, isIndex: true}, nil
+}
+
+// MustParse is like Parse, but panics if the expression can not be parsed.
+func MustParse(expr string) *Path {
+	p, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Get selects the value at the path from a document decoded by
+// encoding/json into an interface{}.
+//
+// An error wrapping ErrNotFound is returned if there is no value at the
+// path.
+func (p *Path) Get(doc interface{}) (interface{}, error) {
+	v := doc
+	for _, st := range p.steps {
+		if st.isIndex {
+			a, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: '%s', not an array", ErrNotFound, p.expr)
+			}
+			i := st.index
+			if i < 0 {
+				i += len(a)
+			}
+			if i < 0 || i >= len(a) {
+				return nil, fmt.Errorf("%w: '%s', index %d out of range", ErrNotFound, p.expr, st.index)
+			}
+			v = a[i]
+			continue
+		}
+
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: '%s', not an object", ErrNotFound, p.expr)
+		}
+		if v, ok = m[st.name]; !ok {
+			return nil, fmt.Errorf("%w: '%s', no '%s'", ErrNotFound, p.expr, st.name)
+		}
+	}
+
+	return v, nil
+}
+
+// String returns the expression the Path was parsed from.
+func (p *Path) String() string {
+	return p.expr
+}
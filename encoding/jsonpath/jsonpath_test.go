@@ -0,0 +1,89 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const document = `{
+	"host": {"name": "web1", "tags": ["prod", "eu"]},
+	"checks": [
+		{"name": "HTTP", "state": 2},
+		{"name": "SSH", "state": 0}
+	],
+	"dotted.key": "yes",
+	"empty": null
+}`
+
+func TestPath_Get(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		t.Fatalf("unable to decode document: %s", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"$.host.name", "web1"},
+		{"$.host.tags[1]", "eu"},
+		{"$.host.tags[-1]", "eu"},
+		{"$.checks[0].name", "HTTP"},
+		{"$.checks[1]['state']", float64(0)},
+		{`$["dotted.key"]`, "yes"},
+		{"$.empty", nil},
+		{"$.host.tags", []interface{}{"prod", "eu"}},
+	}
+
+	for _, test := range tests {
+		p, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("unexpected error parsing '%s': %s", test.input, err)
+			continue
+		}
+
+		v, err := p.Get(doc)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %s", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, test.expected) {
+			t.Errorf("unexpected value for '%s', got: '%v', want: '%v'", test.input, v, test.expected)
+		}
+	}
+}
+
+func TestPath_Get_NotFound(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		t.Fatalf("unable to decode document: %s", err)
+	}
+
+	for _, input := range []string{
+		"$.missing",
+		"$.host.name.first",
+		"$.checks[2]",
+		"$.host[0]",
+	} {
+		_, err := MustParse(input).Get(doc)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("unexpected error for '%s', got: '%v', want: '%v'", input, err, ErrNotFound)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"host.name",
+		"$.",
+		"$.checks[0",
+		"$.checks[*]",
+		"$..name",
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("expected error for '%s'", input)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package xdata
+
+import (
+	"strings"
+)
+
+// CommentEntryType identifies why a comment was added to a host or service.
+type CommentEntryType int
+
+const (
+	UserComment CommentEntryType = iota + 1
+	DowntimeComment
+	FlappingComment
+	AcknowledgementComment
+)
+
+// ParseCommentEntryType converts a byte string into a CommentEntryType.
+//
+// When an unknown type is passed in, a sensible default is returned along with ErrUnknownValue.
+func ParseCommentEntryType(t []byte) (CommentEntryType, error) {
+	switch strings.TrimSpace(strings.ToLower(string(t))) {
+	case "user", "1":
+		return UserComment, nil
+	case "downtime", "2":
+		return DowntimeComment, nil
+	case "flapping", "3":
+		return FlappingComment, nil
+	case "acknowledgement", "4":
+		return AcknowledgementComment, nil
+	default:
+		return UserComment, ErrUnknownValue
+	}
+}
+
+// String returns a string representation of the CommentEntryType.
+//
+// An empty string is returned for unknown types.
+func (t CommentEntryType) String() string {
+	switch t {
+	case UserComment:
+		return "User"
+	case DowntimeComment:
+		return "Downtime"
+	case FlappingComment:
+		return "Flapping"
+	case AcknowledgementComment:
+		return "Acknowledgement"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,65 @@
+package xdata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCommentEntryType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CommentEntryType
+	}{
+		{"User", UserComment},
+		{"Downtime", DowntimeComment},
+		{"Flapping", FlappingComment},
+		{"Acknowledgement", AcknowledgementComment},
+		{"1", UserComment},
+		{"2", DowntimeComment},
+		{"3", FlappingComment},
+		{"4", AcknowledgementComment},
+	}
+
+	for _, test := range tests {
+		et, err := ParseCommentEntryType([]byte(test.input))
+		if err != nil {
+			t.Errorf("unable to parse comment entry type '%s': %s", test.input, err)
+			continue
+		}
+
+		if et != test.expected {
+			t.Errorf("parse returned incorrect output, got: '%d', want: '%d", et, test.expected)
+		}
+	}
+
+	et, err := ParseCommentEntryType([]byte("unknown"))
+	if err == nil {
+		t.Errorf("expected error when passing in unknown type")
+	} else if !errors.Is(err, ErrUnknownValue) {
+		t.Errorf("got unknown error when passing in unknown type: %s", err)
+	}
+
+	if et != UserComment {
+		t.Errorf("parse returned incorrect output, got: '%d', want: '%d'", et, UserComment)
+	}
+}
+
+func TestCommentEntryType_String(t *testing.T) {
+	tests := []struct {
+		input    CommentEntryType
+		expected string
+	}{
+		{UserComment, "User"},
+		{DowntimeComment, "Downtime"},
+		{FlappingComment, "Flapping"},
+		{AcknowledgementComment, "Acknowledgement"},
+		{CommentEntryType(100), ""},
+	}
+
+	for _, test := range tests {
+		s := test.input.String()
+		if s != test.expected {
+			t.Errorf("unexpected string, got: '%s', want: '%s'", s, test.expected)
+		}
+	}
+}
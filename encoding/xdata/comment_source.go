@@ -0,0 +1,42 @@
+package xdata
+
+import (
+	"strings"
+)
+
+// CommentSource identifies whether a comment was added by nagios itself (Internal), or through an external command
+// (External).
+type CommentSource int
+
+const (
+	InternalComment CommentSource = iota
+	ExternalComment
+)
+
+// ParseCommentSource converts a byte string into a CommentSource.
+//
+// When an unknown type is passed in, a sensible default is returned along with ErrUnknownValue.
+func ParseCommentSource(s []byte) (CommentSource, error) {
+	switch strings.TrimSpace(strings.ToLower(string(s))) {
+	case "internal", "0":
+		return InternalComment, nil
+	case "external", "1":
+		return ExternalComment, nil
+	default:
+		return InternalComment, ErrUnknownValue
+	}
+}
+
+// String returns a string representation of the CommentSource.
+//
+// An empty string is returned for unknown types.
+func (s CommentSource) String() string {
+	switch s {
+	case InternalComment:
+		return "Internal"
+	case ExternalComment:
+		return "External"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,59 @@
+package xdata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCommentSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected CommentSource
+	}{
+		{"Internal", InternalComment},
+		{"External", ExternalComment},
+		{"0", InternalComment},
+		{"1", ExternalComment},
+	}
+
+	for _, test := range tests {
+		cs, err := ParseCommentSource([]byte(test.input))
+		if err != nil {
+			t.Errorf("unable to parse comment source '%s': %s", test.input, err)
+			continue
+		}
+
+		if cs != test.expected {
+			t.Errorf("parse returned incorrect output, got: '%d', want: '%d", cs, test.expected)
+		}
+	}
+
+	cs, err := ParseCommentSource([]byte("unknown"))
+	if err == nil {
+		t.Errorf("expected error when passing in unknown type")
+	} else if !errors.Is(err, ErrUnknownValue) {
+		t.Errorf("got unknown error when passing in unknown type: %s", err)
+	}
+
+	if cs != InternalComment {
+		t.Errorf("parse returned incorrect output, got: '%d', want: '%d'", cs, InternalComment)
+	}
+}
+
+func TestCommentSource_String(t *testing.T) {
+	tests := []struct {
+		input    CommentSource
+		expected string
+	}{
+		{InternalComment, "Internal"},
+		{ExternalComment, "External"},
+		{CommentSource(100), ""},
+	}
+
+	for _, test := range tests {
+		s := test.input.String()
+		if s != test.expected {
+			t.Errorf("unexpected string, got: '%s', want: '%s'", s, test.expected)
+		}
+	}
+}
@@ -55,6 +55,20 @@ servicecomment {
 	host_name=host1
 	service_description=service 1
 }
+
+hostdowntime {
+	host_name=host2
+	downtime_id=7
+	fixed=1
+}
+
+servicedowntime {
+	host_name=host1
+	service_description=service 2
+	downtime_id=8
+	fixed=0
+	duration=3600
+}
 `
 
 func TestDecoder_Decode(t *testing.T) {
@@ -145,6 +159,30 @@ func TestDecoder_Decode(t *testing.T) {
 			t.Errorf("incorrect servicecomment.service_name, got: %s, expected: %s", got, "service 1")
 		}
 	}
+
+	if res.HostDowntime == nil || len(res.HostDowntime) != 1 {
+		t.Errorf("failed to parse hostdowntime blocks")
+	} else {
+		if got := res.HostDowntime[0].DowntimeID; got != 7 {
+			t.Errorf("incorrect hostdowntime.downtime_id, got: %d, expected: %d", got, 7)
+		}
+
+		if got := res.HostDowntime[0].Fixed; !got {
+			t.Errorf("incorrect hostdowntime.fixed, got: %t, expected: %t", got, true)
+		}
+	}
+
+	if res.ServiceDowntime == nil || len(res.ServiceDowntime) != 1 {
+		t.Errorf("failed to parse servicedowntime blocks")
+	} else {
+		if got := res.ServiceDowntime[0].ServiceDescription; got != "service 2" {
+			t.Errorf("incorrect servicedowntime.service_description, got: %s, expected: %s", got, "service 2")
+		}
+
+		if got := res.ServiceDowntime[0].Duration; got != 3600 {
+			t.Errorf("incorrect servicedowntime.duration, got: %d, expected: %d", got, 3600)
+		}
+	}
 }
 
 func TestDecoder_Decode_InvalidReciever(t *testing.T) {
@@ -6,10 +6,10 @@ type HostComment struct {
 	CommentData string
 	CommentID   int
 	EntryTime   int
-	EntryType   int
+	EntryType   CommentEntryType
 	ExpireTime  int
 	Expires     bool
 	HostName    string
 	Persistent  bool
-	Source      int
+	Source      CommentSource
 }
@@ -0,0 +1,19 @@
+package xdata
+
+// HostDowntime represents a 'hostdowntime' entry in the Nagios state.dat file.
+type HostDowntime struct {
+	Author                string
+	Comment               string
+	CommentID             int
+	DowntimeID            int
+	Duration              int
+	EndTime               int
+	EntryTime             int
+	Fixed                 bool
+	FlexDowntimeStart     int
+	HostName              string
+	IsInEffect            bool
+	StartNotificationSent bool
+	StartTime             int
+	TriggeredBy           int
+}
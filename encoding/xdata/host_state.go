@@ -1,6 +1,7 @@
 package xdata
 
 import (
+	"bytes"
 	"strings"
 )
 
@@ -50,3 +51,15 @@ func (s HostState) String() string {
 		return ""
 	}
 }
+
+// UnmarshalJSON converts either a numeric code, or the name of a state such
+// as "DOWN", into a HostState.
+func (s *HostState) UnmarshalJSON(b []byte) error {
+	st, err := ParseHostState(bytes.Trim(b, `"`))
+	if err != nil {
+		return err
+	}
+
+	*s = st
+	return nil
+}
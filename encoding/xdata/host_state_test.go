@@ -1,6 +1,7 @@
 package xdata
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 )
@@ -60,3 +61,35 @@ func TestHostState_String(t *testing.T) {
 		}
 	}
 }
+
+func TestHostState_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected HostState
+	}{
+		{`0`, Up},
+		{`1`, Down},
+		{`"2"`, Unreachable},
+		{`"DOWN"`, Down},
+		{`"unreachable"`, Unreachable},
+	}
+
+	for _, test := range tests {
+		var st HostState
+		if err := json.Unmarshal([]byte(test.input), &st); err != nil {
+			t.Errorf("unable to unmarshal host state '%s': %s", test.input, err)
+			continue
+		}
+
+		if st != test.expected {
+			t.Errorf("unmarshal returned incorrect output, got: '%d', want: '%d'", st, test.expected)
+		}
+	}
+
+	for _, input := range []string{`3`, `"PENDING"`, `null`, `true`} {
+		var st HostState
+		if err := json.Unmarshal([]byte(input), &st); !errors.Is(err, ErrUnknownValue) {
+			t.Errorf("unexpected error for '%s', got: '%v', want: '%v'", input, err, ErrUnknownValue)
+		}
+	}
+}
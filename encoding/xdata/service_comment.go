@@ -6,11 +6,11 @@ type ServiceComment struct {
 	CommentData        string
 	CommentID          int
 	EntryTime          int
-	EntryType          int
+	EntryType          CommentEntryType
 	ExpireTime         int
 	Expires            bool
 	HostName           string
 	Persistent         bool
 	ServiceDescription string
-	Source             int
+	Source             CommentSource
 }
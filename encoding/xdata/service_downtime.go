@@ -0,0 +1,20 @@
+package xdata
+
+// ServiceDowntime represents a 'servicedowntime' entry in the Nagios state.dat file.
+type ServiceDowntime struct {
+	Author                string
+	Comment               string
+	CommentID             int
+	DowntimeID            int
+	Duration              int
+	EndTime               int
+	EntryTime             int
+	Fixed                 bool
+	FlexDowntimeStart     int
+	HostName              string
+	IsInEffect            bool
+	ServiceDescription    string
+	StartNotificationSent bool
+	StartTime             int
+	TriggeredBy           int
+}
@@ -1,6 +1,9 @@
 package xdata
 
-import "strings"
+import (
+	"bytes"
+	"strings"
+)
 
 // ServiceState represents the current statusdata of a service check.
 type (
@@ -50,3 +53,15 @@ func (s ServiceState) String() string {
 		return ""
 	}
 }
+
+// UnmarshalJSON converts either a numeric code, or the name of a state such
+// as "CRITICAL", into a ServiceState.
+func (s *ServiceState) UnmarshalJSON(b []byte) error {
+	st, err := ParseServiceState(bytes.Trim(b, `"`))
+	if err != nil {
+		return err
+	}
+
+	*s = st
+	return nil
+}
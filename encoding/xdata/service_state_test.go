@@ -1,6 +1,7 @@
 package xdata
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 )
@@ -63,3 +64,35 @@ func TestServiceState_String(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceState_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ServiceState
+	}{
+		{`0`, Ok},
+		{`2`, Critical},
+		{`"3"`, Unknown},
+		{`"WARNING"`, Warning},
+		{`"critical"`, Critical},
+	}
+
+	for _, test := range tests {
+		var st ServiceState
+		if err := json.Unmarshal([]byte(test.input), &st); err != nil {
+			t.Errorf("unable to unmarshal service state '%s': %s", test.input, err)
+			continue
+		}
+
+		if st != test.expected {
+			t.Errorf("unmarshal returned incorrect output, got: '%d', want: '%d'", st, test.expected)
+		}
+	}
+
+	for _, input := range []string{`17`, `-1`, `"PENDING"`, `null`} {
+		var st ServiceState
+		if err := json.Unmarshal([]byte(input), &st); !errors.Is(err, ErrUnknownValue) {
+			t.Errorf("unexpected error for '%s', got: '%v', want: '%v'", input, err, ErrUnknownValue)
+		}
+	}
+}
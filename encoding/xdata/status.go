@@ -1,10 +1,12 @@
 package xdata
 
 type Status struct {
-	Info           *Info
-	ProgramStatus  *ProgramStatus
-	HostStatus     []*HostStatus
-	HostComment    []*HostComment
-	ServiceStatus  []*ServiceStatus
-	ServiceComment []*ServiceComment
+	Info            *Info
+	ProgramStatus   *ProgramStatus
+	HostStatus      []*HostStatus
+	HostComment     []*HostComment
+	HostDowntime    []*HostDowntime
+	ServiceStatus   []*ServiceStatus
+	ServiceComment  []*ServiceComment
+	ServiceDowntime []*ServiceDowntime
 }
@@ -0,0 +1,348 @@
+// Availability computes the time hosts and services spent in each state,
+// following the semantics of the nagios avail.cgi report.
+package availability
+
+import (
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/history"
+)
+
+// Undetermined is used in place of a host or service state when there is
+// not enough history to know the actual state.
+const Undetermined = -1
+
+// Options controls how gaps and ambiguities in the state history are handled.
+type Options struct {
+	// IncludeSoftStates counts time spent in SOFT states. By default, only
+	// HARD state changes are considered.
+	IncludeSoftStates bool
+
+	// AssumeInitialStates uses InitialHostState or InitialServiceState for
+	// the start of the window when no earlier state can be found.
+	AssumeInitialStates bool
+
+	// InitialHostState is the xdata.HostState assumed at the start of the
+	// window. Undetermined leaves the state unspecified.
+	InitialHostState int
+
+	// InitialServiceState is the xdata.ServiceState assumed at the start of
+	// the window. Undetermined leaves the state unspecified.
+	InitialServiceState int
+
+	// CurrentState, when set, is used in preference to InitialHostState and
+	// InitialServiceState, so that the current state of each subject is
+	// assumed as its initial state.
+	CurrentState func(s Subject) (int, bool)
+
+	// AssumeStateRetention keeps the last known state when nagios restarts,
+	// rather than treating it as undetermined until the next state is logged.
+	AssumeStateRetention bool
+
+	// AssumeStatesDuringProgramDowntime keeps the last known state while
+	// nagios is not running, rather than treating it as undetermined.
+	AssumeStatesDuringProgramDowntime bool
+
+	// ScheduledDowntimeAsOK counts time spent in scheduled downtime as OK
+	// (or UP, for hosts), regardless of the actual state.
+	ScheduledDowntimeAsOK bool
+}
+
+// DefaultOptions returns the same defaults as avail.cgi.
+func DefaultOptions() Options {
+	return Options{
+		AssumeInitialStates:               true,
+		InitialHostState:                  Undetermined,
+		InitialServiceState:               Undetermined,
+		AssumeStateRetention:              true,
+		AssumeStatesDuringProgramDowntime: true,
+	}
+}
+
+// Subject identifies the host or service a report is for. Host subjects have
+// an empty ServiceDescription.
+type Subject struct {
+	HostName           string
+	ServiceDescription string
+}
+
+// IsService returns whether the subject refers to a service.
+func (s Subject) IsService() bool {
+	return s.ServiceDescription != ""
+}
+
+// StateTime holds the number of seconds spent in a state, split by whether
+// the subject was in scheduled downtime.
+type StateTime struct {
+	Scheduled   int64
+	Unscheduled int64
+}
+
+// Total returns the total number of seconds spent in the state.
+func (t StateTime) Total() int64 {
+	return t.Scheduled + t.Unscheduled
+}
+
+// Report holds the time a single host or service spent in each state between
+// Start and End.
+type Report struct {
+	Subject
+	Start int64
+	End   int64
+
+	// States is indexed by xdata.HostState for hosts and by
+	// xdata.ServiceState for services.
+	States       [4]StateTime
+	Undetermined StateTime
+}
+
+// Duration returns the length of the report window in seconds.
+func (r *Report) Duration() int64 {
+	return r.End - r.Start
+}
+
+// Time returns the time spent in the given state. Undetermined can be passed
+// to find the time with an unknown state.
+func (r *Report) Time(state int) StateTime {
+	if state >= 0 && state < len(r.States) {
+		return r.States[state]
+	}
+	return r.Undetermined
+}
+
+// Percent returns the percentage of the report window spent in the given
+// state. Undetermined can be passed to find the percentage of time with an
+// unknown state.
+func (r *Report) Percent(state int) float64 {
+	if r.Duration() <= 0 {
+		return 0
+	}
+
+	return float64(r.Time(state).Total()) * 100 / float64(r.Duration())
+}
+
+// HostStates lists the states reported for hosts.
+var HostStates = []int{int(xdata.Up), int(xdata.Down), int(xdata.Unreachable)}
+
+// ServiceStates lists the states reported for services.
+var ServiceStates = []int{int(xdata.Ok), int(xdata.Warning), int(xdata.Critical), int(xdata.Unknown)}
+
+// Calculate computes a report for each subject between start and end (unix
+// timestamps) from the events, which must be sorted by time.
+//
+// Events from before start are used to determine the state at the start of
+// the window.
+func Calculate(events []history.Event, subjects []Subject, start, end int64, opts Options) []*Report {
+	idx := index(events, end)
+
+	reports := make([]*Report, 0, len(subjects))
+	for _, s := range subjects {
+		// services also see the downtime of their host.
+		host := Subject{HostName: s.HostName}
+		matches := merge(idx[Subject{}], idx[host])
+		if s.IsService() {
+			matches = merge(matches, idx[s])
+		}
+		reports = append(reports, calculate(events, matches, s, start, end, opts))
+	}
+	return reports
+}
+
+// index groups the positions of the events up to end by the subject they
+// refer to, in a single pass. Events for the nagios process itself are
+// grouped under an empty Subject.
+func index(events []history.Event, end int64) map[Subject][]int {
+	idx := make(map[Subject][]int)
+	for i := range events {
+		e := &events[i]
+		if e.Time > end {
+			break
+		}
+
+		var s Subject
+		switch e.Type {
+		case history.ProgramStart, history.ProgramEnd:
+		default:
+			s = Subject{HostName: e.HostName, ServiceDescription: e.ServiceDescription}
+		}
+		idx[s] = append(idx[s], i)
+	}
+	return idx
+}
+
+// merge combines two ascending lists of positions into one.
+func merge(a, b []int) []int {
+	res := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0] < b[0] {
+			res, a = append(res, a[0]), a[1:]
+		} else {
+			res, b = append(res, b[0]), b[1:]
+		}
+	}
+	res = append(res, a...)
+	return append(res, b...)
+}
+
+type tracker struct {
+	opts   Options
+	report *Report
+
+	state            int
+	hostDowntime     int
+	serviceDowntime  int
+	programDown      bool
+	assumedInitial   bool
+	lastAccumulation int64
+}
+
+// calculate computes the report for a subject from the events at the given
+// positions.
+func calculate(events []history.Event, matches []int, s Subject, start, end int64, opts Options) *Report {
+	t := &tracker{
+		opts: opts,
+		report: &Report{
+			Subject: s,
+			Start:   start,
+			End:     end,
+		},
+		state:            Undetermined,
+		lastAccumulation: start,
+	}
+
+	for _, i := range matches {
+		e := &events[i]
+		if e.Time > end {
+			break
+		}
+
+		if !t.relevant(e) {
+			continue
+		}
+
+		if e.Time >= start {
+			t.assumeInitialState()
+			t.accumulate(e.Time)
+		}
+		t.apply(e)
+	}
+
+	t.assumeInitialState()
+	t.accumulate(end)
+
+	return t.report
+}
+
+func (t *tracker) relevant(e *history.Event) bool {
+	s := t.report.Subject
+	switch e.Type {
+	case history.ProgramStart, history.ProgramEnd:
+		return true
+	case history.DowntimeStart, history.DowntimeEnd:
+		// services are also considered to be in scheduled downtime when
+		// their host is.
+		if e.HostName != s.HostName {
+			return false
+		}
+		return e.IsHostEvent() || e.ServiceDescription == s.ServiceDescription
+	default:
+		return e.HostName == s.HostName && e.ServiceDescription == s.ServiceDescription
+	}
+}
+
+// assumeInitialState sets the state at the start of the window, if no state
+// could be determined from earlier events.
+func (t *tracker) assumeInitialState() {
+	if t.assumedInitial {
+		return
+	}
+	t.assumedInitial = true
+
+	if t.state != Undetermined || !t.opts.AssumeInitialStates {
+		return
+	}
+
+	s := t.report.Subject
+	if t.opts.CurrentState != nil {
+		if st, ok := t.opts.CurrentState(s); ok {
+			t.state = st
+			return
+		}
+	}
+
+	if s.IsService() {
+		t.state = t.opts.InitialServiceState
+	} else {
+		t.state = t.opts.InitialHostState
+	}
+}
+
+func (t *tracker) accumulate(until int64) {
+	from := t.lastAccumulation
+	if until <= from {
+		return
+	}
+	t.lastAccumulation = until
+
+	st := t.state
+	if t.programDown && !t.opts.AssumeStatesDuringProgramDowntime {
+		st = Undetermined
+	}
+
+	inDowntime := t.hostDowntime > 0 || t.serviceDowntime > 0
+	if inDowntime && t.opts.ScheduledDowntimeAsOK && st != Undetermined {
+		st = 0
+	}
+
+	bucket := &t.report.Undetermined
+	if st >= 0 && st < len(t.report.States) {
+		bucket = &t.report.States[st]
+	}
+
+	if inDowntime {
+		bucket.Scheduled += until - from
+	} else {
+		bucket.Unscheduled += until - from
+	}
+}
+
+func (t *tracker) apply(e *history.Event) {
+	switch e.Type {
+	case history.StateChange:
+		if e.StateType == xdata.Soft && !t.opts.IncludeSoftStates {
+			return
+		}
+		t.state = e.State
+
+	case history.DowntimeStart:
+		if e.IsHostEvent() {
+			t.hostDowntime++
+		} else {
+			t.serviceDowntime++
+		}
+
+	case history.DowntimeEnd:
+		if e.IsHostEvent() {
+			t.hostDowntime = decrement(t.hostDowntime)
+		} else {
+			t.serviceDowntime = decrement(t.serviceDowntime)
+		}
+
+	case history.ProgramEnd:
+		t.programDown = true
+
+	case history.ProgramStart:
+		t.programDown = false
+		if !t.opts.AssumeStateRetention {
+			t.state = Undetermined
+		}
+	}
+}
+
+// decrement reduces a downtime depth, ignoring the end of downtime which
+// started before the earliest available history.
+func decrement(depth int) int {
+	if depth > 0 {
+		return depth - 1
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package availability
+
+import (
+	"testing"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/history"
+)
+
+func serviceEvent(ts int64, state xdata.ServiceState, stateType xdata.StateType) history.Event {
+	return history.Event{
+		Time:               ts,
+		Type:               history.StateChange,
+		HostName:           "host1",
+		ServiceDescription: "service 1",
+		State:              int(state),
+		StateType:          stateType,
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	events := []history.Event{
+		serviceEvent(0, xdata.Ok, xdata.Hard),
+		serviceEvent(200, xdata.Critical, xdata.Soft),
+		serviceEvent(250, xdata.Critical, xdata.Hard),
+		{Time: 300, Type: history.DowntimeStart, HostName: "host1"},
+		{Time: 400, Type: history.DowntimeEnd, HostName: "host1"},
+		serviceEvent(500, xdata.Warning, xdata.Hard),
+		{Time: 600, Type: history.ProgramEnd},
+		{Time: 700, Type: history.ProgramStart},
+		serviceEvent(700, xdata.Ok, xdata.Hard),
+		serviceEvent(2000, xdata.Critical, xdata.Hard),
+	}
+	subject := Subject{HostName: "host1", ServiceDescription: "service 1"}
+
+	tests := []struct {
+		name         string
+		opts         func(o *Options)
+		expected     [4]StateTime
+		undetermined StateTime
+	}{
+		{
+			name: "defaults",
+			opts: func(o *Options) {},
+			expected: [4]StateTime{
+				xdata.Ok:       {Unscheduled: 150 + 300},
+				xdata.Warning:  {Unscheduled: 200},
+				xdata.Critical: {Scheduled: 100, Unscheduled: 150},
+			},
+		},
+		{
+			name: "soft states",
+			opts: func(o *Options) { o.IncludeSoftStates = true },
+			expected: [4]StateTime{
+				xdata.Ok:       {Unscheduled: 100 + 300},
+				xdata.Warning:  {Unscheduled: 200},
+				xdata.Critical: {Scheduled: 100, Unscheduled: 200},
+			},
+		},
+		{
+			name: "downtime as ok",
+			opts: func(o *Options) { o.ScheduledDowntimeAsOK = true },
+			expected: [4]StateTime{
+				xdata.Ok:       {Scheduled: 100, Unscheduled: 150 + 300},
+				xdata.Warning:  {Unscheduled: 200},
+				xdata.Critical: {Unscheduled: 150},
+			},
+		},
+		{
+			name: "program downtime",
+			opts: func(o *Options) { o.AssumeStatesDuringProgramDowntime = false },
+			expected: [4]StateTime{
+				xdata.Ok:       {Unscheduled: 150 + 300},
+				xdata.Warning:  {Unscheduled: 100},
+				xdata.Critical: {Scheduled: 100, Unscheduled: 150},
+			},
+			undetermined: StateTime{Unscheduled: 100},
+		},
+	}
+
+	for _, test := range tests {
+		opts := DefaultOptions()
+		test.opts(&opts)
+
+		reports := Calculate(events, []Subject{subject}, 100, 1000, opts)
+		if len(reports) != 1 {
+			t.Errorf("%s: expected 1 report, got: %d", test.name, len(reports))
+			continue
+		}
+
+		r := reports[0]
+		if r.States != test.expected {
+			t.Errorf("%s: incorrect states, got: %+v, want: %+v", test.name, r.States, test.expected)
+		}
+		if r.Undetermined != test.undetermined {
+			t.Errorf("%s: incorrect undetermined time, got: %+v, want: %+v", test.name, r.Undetermined, test.undetermined)
+		}
+	}
+}
+
+func TestCalculate_InitialState(t *testing.T) {
+	events := []history.Event{
+		serviceEvent(500, xdata.Critical, xdata.Hard),
+	}
+	subject := Subject{HostName: "host1", ServiceDescription: "service 1"}
+
+	opts := DefaultOptions()
+	r := Calculate(events, []Subject{subject}, 0, 1000, opts)[0]
+	if got := r.Undetermined.Total(); got != 500 {
+		t.Errorf("incorrect undetermined time, got: %d, want: %d", got, 500)
+	}
+
+	opts.InitialServiceState = int(xdata.Ok)
+	r = Calculate(events, []Subject{subject}, 0, 1000, opts)[0]
+	if got := r.States[xdata.Ok].Total(); got != 500 {
+		t.Errorf("incorrect ok time, got: %d, want: %d", got, 500)
+	}
+	if got := r.Percent(int(xdata.Critical)); got != 50 {
+		t.Errorf("incorrect critical percent, got: %f, want: %f", got, 50.0)
+	}
+
+	opts.AssumeInitialStates = false
+	r = Calculate(events, []Subject{subject}, 0, 1000, opts)[0]
+	if got := r.Undetermined.Total(); got != 500 {
+		t.Errorf("incorrect undetermined time, got: %d, want: %d", got, 500)
+	}
+
+	opts.AssumeInitialStates = true
+	opts.CurrentState = func(s Subject) (int, bool) {
+		return int(xdata.Warning), true
+	}
+	r = Calculate(events, []Subject{subject}, 0, 1000, opts)[0]
+	if got := r.States[xdata.Warning].Total(); got != 500 {
+		t.Errorf("incorrect warning time, got: %d, want: %d", got, 500)
+	}
+}
+
+func TestCalculate_Subjects(t *testing.T) {
+	events := []history.Event{
+		{Time: 0, Type: history.StateChange, HostName: "host1", State: int(xdata.Up), StateType: xdata.Hard},
+		serviceEvent(0, xdata.Ok, xdata.Hard),
+		{Time: 0, Type: history.StateChange, HostName: "host1", ServiceDescription: "service 2", State: int(xdata.Ok), StateType: xdata.Hard},
+		{Time: 0, Type: history.StateChange, HostName: "host2", State: int(xdata.Up), StateType: xdata.Hard},
+		{Time: 200, Type: history.StateChange, HostName: "host1", ServiceDescription: "service 2", State: int(xdata.Critical), StateType: xdata.Hard},
+		{Time: 300, Type: history.DowntimeStart, HostName: "host1"},
+		{Time: 350, Type: history.DowntimeStart, HostName: "host2"},
+		{Time: 400, Type: history.DowntimeEnd, HostName: "host1"},
+		{Time: 450, Type: history.StateChange, HostName: "host1", State: int(xdata.Down), StateType: xdata.Hard},
+		{Time: 500, Type: history.DowntimeStart, HostName: "host1", ServiceDescription: "service 1"},
+		{Time: 600, Type: history.ProgramEnd},
+		{Time: 700, Type: history.ProgramStart},
+		{Time: 800, Type: history.DowntimeEnd, HostName: "host1", ServiceDescription: "service 1"},
+		serviceEvent(900, xdata.Warning, xdata.Hard),
+		{Time: 2000, Type: history.StateChange, HostName: "host2", State: int(xdata.Down), StateType: xdata.Hard},
+	}
+	subjects := []Subject{
+		{HostName: "host1"},
+		{HostName: "host1", ServiceDescription: "service 1"},
+		{HostName: "host1", ServiceDescription: "service 2"},
+		{HostName: "host2"},
+		{HostName: "host3"},
+	}
+
+	opts := DefaultOptions()
+	opts.AssumeStatesDuringProgramDowntime = false
+	reports := Calculate(events, subjects, 100, 1000, opts)
+	if len(reports) != len(subjects) {
+		t.Fatalf("unexpected report count, got: %d, want: %d", len(reports), len(subjects))
+	}
+
+	// each report is the same as one calculated from every event.
+	all := make([]int, len(events))
+	for i := range all {
+		all[i] = i
+	}
+	for i, s := range subjects {
+		expected := calculate(events, all, s, 100, 1000, opts)
+		if *reports[i] != *expected {
+			t.Errorf("incorrect report for %+v, got: %+v, want: %+v", s, *reports[i], *expected)
+		}
+	}
+
+	if got := reports[1].States[xdata.Ok]; got != (StateTime{Scheduled: 100 + 100 + 100, Unscheduled: 200 + 100 + 100}) {
+		t.Errorf("incorrect ok time for service 1, got: %+v", got)
+	}
+}
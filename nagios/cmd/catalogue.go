@@ -0,0 +1,628 @@
+package cmd
+
+// catalogue lists the nagios external commands and their arguments, as
+// documented in the nagios external command list.
+var catalogue = map[string]Spec{}
+
+func init() {
+	for _, spec := range []Spec{
+		// comments
+		{Name: "ADD_HOST_COMMENT", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "persistent", Type: Bool},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "ADD_SVC_COMMENT", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "persistent", Type: Bool},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "DEL_HOST_COMMENT", Args: []Arg{
+			{Name: "comment_id", Type: Int},
+		}},
+		{Name: "DEL_SVC_COMMENT", Args: []Arg{
+			{Name: "comment_id", Type: Int},
+		}},
+		{Name: "DEL_ALL_HOST_COMMENTS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DEL_ALL_SVC_COMMENTS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+
+		// acknowledgements
+		{Name: "ACKNOWLEDGE_HOST_PROBLEM", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "sticky", Type: StickyAck},
+			{Name: "notify", Type: Bool},
+			{Name: "persistent", Type: Bool},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "ACKNOWLEDGE_HOST_PROBLEM_EXPIRE", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "sticky", Type: StickyAck},
+			{Name: "notify", Type: Bool},
+			{Name: "persistent", Type: Bool},
+			{Name: "timestamp", Type: Timestamp},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "ACKNOWLEDGE_SVC_PROBLEM", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "sticky", Type: StickyAck},
+			{Name: "notify", Type: Bool},
+			{Name: "persistent", Type: Bool},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "ACKNOWLEDGE_SVC_PROBLEM_EXPIRE", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "sticky", Type: StickyAck},
+			{Name: "notify", Type: Bool},
+			{Name: "persistent", Type: Bool},
+			{Name: "timestamp", Type: Timestamp},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "REMOVE_HOST_ACKNOWLEDGEMENT", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "REMOVE_SVC_ACKNOWLEDGEMENT", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+
+		// downtime
+		{Name: "SCHEDULE_HOST_DOWNTIME", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_SVC_DOWNTIME", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_HOST_SVC_DOWNTIME", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_AND_PROPAGATE_HOST_DOWNTIME", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_AND_PROPAGATE_TRIGGERED_HOST_DOWNTIME", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_HOSTGROUP_HOST_DOWNTIME", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_HOSTGROUP_SVC_DOWNTIME", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_SERVICEGROUP_HOST_DOWNTIME", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SCHEDULE_SERVICEGROUP_SVC_DOWNTIME", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+			{Name: "start_time", Type: Timestamp},
+			{Name: "end_time", Type: Timestamp},
+			{Name: "fixed", Type: Bool},
+			{Name: "trigger_id", Type: Int},
+			{Name: "duration", Type: Duration},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "DEL_HOST_DOWNTIME", Args: []Arg{
+			{Name: "downtime_id", Type: Int},
+		}},
+		{Name: "DEL_SVC_DOWNTIME", Args: []Arg{
+			{Name: "downtime_id", Type: Int},
+		}},
+		{Name: "DEL_DOWNTIME_BY_HOST_NAME", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription, Optional: true},
+			{Name: "start_time", Type: Timestamp, Optional: true},
+			{Name: "comment", Type: Text, Optional: true},
+		}},
+		{Name: "DEL_DOWNTIME_BY_HOSTGROUP_NAME", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+			{Name: "host_name", Type: HostName, Optional: true},
+			{Name: "service_description", Type: ServiceDescription, Optional: true},
+			{Name: "start_time", Type: Timestamp, Optional: true},
+			{Name: "comment", Type: Text, Optional: true},
+		}},
+		{Name: "DEL_DOWNTIME_BY_START_TIME_COMMENT", Args: []Arg{
+			{Name: "start_time", Type: Timestamp, Optional: true},
+			{Name: "comment", Type: Text, Optional: true},
+		}},
+
+		// checks
+		{Name: "PROCESS_HOST_CHECK_RESULT", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "status_code", Type: HostStatus},
+			{Name: "plugin_output", Type: PluginOutput},
+		}},
+		{Name: "PROCESS_SERVICE_CHECK_RESULT", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "return_code", Type: ServiceStatus},
+			{Name: "plugin_output", Type: PluginOutput},
+		}},
+		{Name: "SCHEDULE_HOST_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_time", Type: Timestamp},
+		}},
+		{Name: "SCHEDULE_FORCED_HOST_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_time", Type: Timestamp},
+		}},
+		{Name: "SCHEDULE_HOST_SVC_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_time", Type: Timestamp},
+		}},
+		{Name: "SCHEDULE_FORCED_HOST_SVC_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_time", Type: Timestamp},
+		}},
+		{Name: "SCHEDULE_SVC_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_time", Type: Timestamp},
+		}},
+		{Name: "SCHEDULE_FORCED_SVC_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_time", Type: Timestamp},
+		}},
+		{Name: "ENABLE_HOST_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_SVC_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "ENABLE_HOST_SVC_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_PASSIVE_HOST_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_PASSIVE_SVC_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "ENABLE_HOSTGROUP_HOST_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "ENABLE_HOSTGROUP_SVC_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "ENABLE_HOSTGROUP_PASSIVE_HOST_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "ENABLE_HOSTGROUP_PASSIVE_SVC_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "ENABLE_SERVICEGROUP_HOST_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "ENABLE_SERVICEGROUP_SVC_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "ENABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "ENABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "ENABLE_HOST_EVENT_HANDLER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_SVC_EVENT_HANDLER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "ENABLE_HOST_FLAP_DETECTION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_SVC_FLAP_DETECTION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "DISABLE_HOST_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_SVC_CHECK", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "DISABLE_HOST_SVC_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_PASSIVE_HOST_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_PASSIVE_SVC_CHECKS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "DISABLE_HOSTGROUP_HOST_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "DISABLE_HOSTGROUP_SVC_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "DISABLE_HOSTGROUP_PASSIVE_HOST_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "DISABLE_HOSTGROUP_PASSIVE_SVC_CHECKS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "DISABLE_SERVICEGROUP_HOST_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "DISABLE_SERVICEGROUP_SVC_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "DISABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "DISABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "DISABLE_HOST_EVENT_HANDLER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_SVC_EVENT_HANDLER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "DISABLE_HOST_FLAP_DETECTION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_SVC_FLAP_DETECTION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "START_OBSESSING_OVER_HOST", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "START_OBSESSING_OVER_SVC", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "STOP_OBSESSING_OVER_HOST", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "STOP_OBSESSING_OVER_SVC", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+
+		// notifications
+		{Name: "ENABLE_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "ENABLE_HOST_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_HOST_AND_CHILD_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_ALL_NOTIFICATIONS_BEYOND_HOST", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "ENABLE_HOSTGROUP_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "ENABLE_HOSTGROUP_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "ENABLE_SERVICEGROUP_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "ENABLE_SERVICEGROUP_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "ENABLE_CONTACT_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+		}},
+		{Name: "ENABLE_CONTACT_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+		}},
+		{Name: "ENABLE_CONTACTGROUP_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "contactgroup_name", Type: ContactGroupName},
+		}},
+		{Name: "ENABLE_CONTACTGROUP_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "contactgroup_name", Type: ContactGroupName},
+		}},
+		{Name: "DISABLE_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+		}},
+		{Name: "DISABLE_HOST_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_HOST_AND_CHILD_NOTIFICATIONS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_ALL_NOTIFICATIONS_BEYOND_HOST", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+		}},
+		{Name: "DISABLE_HOSTGROUP_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "DISABLE_HOSTGROUP_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "hostgroup_name", Type: HostGroupName},
+		}},
+		{Name: "DISABLE_SERVICEGROUP_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "DISABLE_SERVICEGROUP_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "servicegroup_name", Type: ServiceGroupName},
+		}},
+		{Name: "DISABLE_CONTACT_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+		}},
+		{Name: "DISABLE_CONTACT_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+		}},
+		{Name: "DISABLE_CONTACTGROUP_HOST_NOTIFICATIONS", Args: []Arg{
+			{Name: "contactgroup_name", Type: ContactGroupName},
+		}},
+		{Name: "DISABLE_CONTACTGROUP_SVC_NOTIFICATIONS", Args: []Arg{
+			{Name: "contactgroup_name", Type: ContactGroupName},
+		}},
+		{Name: "DELAY_HOST_NOTIFICATION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "notification_time", Type: Timestamp},
+		}},
+		{Name: "DELAY_SVC_NOTIFICATION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "notification_time", Type: Timestamp},
+		}},
+		{Name: "SEND_CUSTOM_HOST_NOTIFICATION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "options", Type: Int},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SEND_CUSTOM_SVC_NOTIFICATION", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "options", Type: Int},
+			{Name: "author", Type: Text},
+			{Name: "comment", Type: Text},
+		}},
+		{Name: "SET_HOST_NOTIFICATION_NUMBER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "notification_number", Type: Int},
+		}},
+		{Name: "SET_SVC_NOTIFICATION_NUMBER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "notification_number", Type: Int},
+		}},
+
+		// attributes
+		{Name: "CHANGE_HOST_CHECK_COMMAND", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_command", Type: CommandName},
+		}},
+		{Name: "CHANGE_SVC_CHECK_COMMAND", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_command", Type: CommandName},
+		}},
+		{Name: "CHANGE_HOST_CHECK_TIMEPERIOD", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_timeperiod", Type: TimePeriodName},
+		}},
+		{Name: "CHANGE_SVC_CHECK_TIMEPERIOD", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_timeperiod", Type: TimePeriodName},
+		}},
+		{Name: "CHANGE_HOST_NOTIFICATION_TIMEPERIOD", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "notification_timeperiod", Type: TimePeriodName},
+		}},
+		{Name: "CHANGE_SVC_NOTIFICATION_TIMEPERIOD", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "notification_timeperiod", Type: TimePeriodName},
+		}},
+		{Name: "CHANGE_CONTACT_HOST_NOTIFICATION_TIMEPERIOD", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+			{Name: "notification_timeperiod", Type: TimePeriodName},
+		}},
+		{Name: "CHANGE_CONTACT_SVC_NOTIFICATION_TIMEPERIOD", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+			{Name: "notification_timeperiod", Type: TimePeriodName},
+		}},
+		{Name: "CHANGE_HOST_EVENT_HANDLER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "event_handler_command", Type: CommandName},
+		}},
+		{Name: "CHANGE_SVC_EVENT_HANDLER", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "event_handler_command", Type: CommandName},
+		}},
+		{Name: "CHANGE_GLOBAL_HOST_EVENT_HANDLER", Args: []Arg{
+			{Name: "event_handler_command", Type: CommandName},
+		}},
+		{Name: "CHANGE_GLOBAL_SVC_EVENT_HANDLER", Args: []Arg{
+			{Name: "event_handler_command", Type: CommandName},
+		}},
+		{Name: "CHANGE_MAX_HOST_CHECK_ATTEMPTS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_attempts", Type: Int},
+		}},
+		{Name: "CHANGE_MAX_SVC_CHECK_ATTEMPTS", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_attempts", Type: Int},
+		}},
+		{Name: "CHANGE_NORMAL_HOST_CHECK_INTERVAL", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_interval", Type: Float},
+		}},
+		{Name: "CHANGE_NORMAL_SVC_CHECK_INTERVAL", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_interval", Type: Float},
+		}},
+		{Name: "CHANGE_RETRY_HOST_CHECK_INTERVAL", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "check_interval", Type: Float},
+		}},
+		{Name: "CHANGE_RETRY_SVC_CHECK_INTERVAL", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "check_interval", Type: Float},
+		}},
+		{Name: "CHANGE_HOST_MODATTR", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "value", Type: ModifiedAttributes},
+		}},
+		{Name: "CHANGE_SVC_MODATTR", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "value", Type: ModifiedAttributes},
+		}},
+		{Name: "CHANGE_CONTACT_MODATTR", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+			{Name: "value", Type: ModifiedAttributes},
+		}},
+		{Name: "CHANGE_CONTACT_MODHATTR", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+			{Name: "value", Type: ModifiedAttributes},
+		}},
+		{Name: "CHANGE_CONTACT_MODSATTR", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+			{Name: "value", Type: ModifiedAttributes},
+		}},
+		{Name: "CHANGE_CUSTOM_HOST_VAR", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "varname", Type: VariableName},
+			{Name: "varvalue", Type: Text},
+		}},
+		{Name: "CHANGE_CUSTOM_SVC_VAR", Args: []Arg{
+			{Name: "host_name", Type: HostName},
+			{Name: "service_description", Type: ServiceDescription},
+			{Name: "varname", Type: VariableName},
+			{Name: "varvalue", Type: Text},
+		}},
+		{Name: "CHANGE_CUSTOM_CONTACT_VAR", Args: []Arg{
+			{Name: "contact_name", Type: ContactName},
+			{Name: "varname", Type: VariableName},
+			{Name: "varvalue", Type: Text},
+		}},
+
+		// program
+		{Name: "ENABLE_NOTIFICATIONS"},
+		{Name: "ENABLE_EVENT_HANDLERS"},
+		{Name: "ENABLE_FLAP_DETECTION"},
+		{Name: "ENABLE_FAILURE_PREDICTION"},
+		{Name: "ENABLE_PERFORMANCE_DATA"},
+		{Name: "ENABLE_HOST_FRESHNESS_CHECKS"},
+		{Name: "ENABLE_SERVICE_FRESHNESS_CHECKS"},
+		{Name: "DISABLE_NOTIFICATIONS"},
+		{Name: "DISABLE_EVENT_HANDLERS"},
+		{Name: "DISABLE_FLAP_DETECTION"},
+		{Name: "DISABLE_FAILURE_PREDICTION"},
+		{Name: "DISABLE_PERFORMANCE_DATA"},
+		{Name: "DISABLE_HOST_FRESHNESS_CHECKS"},
+		{Name: "DISABLE_SERVICE_FRESHNESS_CHECKS"},
+		{Name: "START_EXECUTING_HOST_CHECKS"},
+		{Name: "START_EXECUTING_SVC_CHECKS"},
+		{Name: "START_ACCEPTING_PASSIVE_HOST_CHECKS"},
+		{Name: "START_ACCEPTING_PASSIVE_SVC_CHECKS"},
+		{Name: "START_OBSESSING_OVER_HOST_CHECKS"},
+		{Name: "START_OBSESSING_OVER_SVC_CHECKS"},
+		{Name: "STOP_EXECUTING_HOST_CHECKS"},
+		{Name: "STOP_EXECUTING_SVC_CHECKS"},
+		{Name: "STOP_ACCEPTING_PASSIVE_HOST_CHECKS"},
+		{Name: "STOP_ACCEPTING_PASSIVE_SVC_CHECKS"},
+		{Name: "STOP_OBSESSING_OVER_HOST_CHECKS"},
+		{Name: "STOP_OBSESSING_OVER_SVC_CHECKS"},
+		{Name: "PROCESS_FILE", Args: []Arg{
+			{Name: "file_name", Type: FileName},
+			{Name: "delete", Type: Bool},
+		}},
+		{Name: "READ_STATE_INFORMATION"},
+		{Name: "SAVE_STATE_INFORMATION"},
+		{Name: "RESTART_PROGRAM"},
+		{Name: "SHUTDOWN_PROGRAM"},
+	} {
+		catalogue[spec.Name] = spec
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCheckResultPath is the default nagios check_result_path.
+const DefaultCheckResultPath = "/usr/local/nagios/var/spool/checkresults"
+
+// ErrUnsupportedCommand is returned by CheckResultWriter for commands other
+// than passive check results.
+var ErrUnsupportedCommand = errors.New("unsupported command")
+
+// CheckResultWriter writes passive check results as files in the nagios
+// check_result_path, instead of through the external commands file.
+//
+// Unlike the external commands file, there is no limit on the size of a
+// result, and nagios picks up the results even if it is restarted while
+// they are being written.
+//
+// CheckResultWriter implements io.Writer. Only PROCESS_SERVICE_CHECK_RESULT
+// and PROCESS_HOST_CHECK_RESULT commands can be written.
+type CheckResultWriter struct {
+	path string
+}
+
+// NewCheckResultWriter constructs an instance of CheckResultWriter, writing
+// to the given check_result_path.
+func NewCheckResultWriter(path string) (*CheckResultWriter, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("check result path '%s' is not a directory", path)
+	}
+
+	return &CheckResultWriter{
+		path: path,
+	}, nil
+}
+
+// Write converts a passive check result command into a check result file.
+//
+// The file is written in full before its .ok marker is created, as nagios
+// ignores check result files without one.
+//
+// It is assumed that the input is a full, valid, nagios command, not
+// terminated by a new-line.
+func (w *CheckResultWriter) Write(cmd []byte) (int, error) {
+	contents, err := checkResultFile(string(cmd))
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := w.create()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Write(contents); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return 0, err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return 0, err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return 0, err
+	}
+
+	ok, err := os.OpenFile(f.Name()+".ok", os.O_CREATE|os.O_WRONLY, 0660)
+	if err != nil {
+		os.Remove(f.Name())
+		return 0, err
+	}
+	ok.Close()
+
+	return len(cmd), nil
+}
+
+// create makes a new file named like the ones nagios creates, 'c' followed
+// by six random characters, as nagios ignores files with other names.
+func (w *CheckResultWriter) create() (*os.File, error) {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	for i := 0; i < 100; i++ {
+		name := make([]byte, 7)
+		name[0] = 'c'
+		for j := 1; j < len(name); j++ {
+			name[j] = chars[rand.Intn(len(chars))]
+		}
+
+		f, err := os.OpenFile(filepath.Join(w.path, string(name)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0660)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+
+	return nil, fmt.Errorf("unable to create check result file in '%s'", w.path)
+}
+
+// checkResultFile converts a passive check result command into the contents
+// of a check result file.
+func checkResultFile(cmd string) ([]byte, error) {
+	if !strings.HasPrefix(cmd, "[") {
+		return nil, fmt.Errorf("invalid command '%s'", cmd)
+	}
+
+	end := strings.Index(cmd, "] ")
+	if end == -1 {
+		return nil, fmt.Errorf("invalid command '%s'", cmd)
+	}
+
+	ts, err := strconv.ParseInt(cmd[1:end], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command timestamp '%s'", cmd[1:end])
+	}
+
+	var host, service, code, output string
+	parts := strings.SplitN(cmd[end+2:], ";", 2)
+	switch parts[0] {
+	case "PROCESS_SERVICE_CHECK_RESULT":
+		args := strings.SplitN(parts[len(parts)-1], ";", 4)
+		if len(parts) != 2 || len(args) != 4 {
+			return nil, fmt.Errorf("invalid command '%s'", cmd)
+		}
+		host, service, code, output = args[0], args[1], args[2], args[3]
+
+	case "PROCESS_HOST_CHECK_RESULT":
+		args := strings.SplitN(parts[len(parts)-1], ";", 3)
+		if len(parts) != 2 || len(args) != 3 {
+			return nil, fmt.Errorf("invalid command '%s'", cmd)
+		}
+		host, code, output = args[0], args[1], args[2]
+
+	default:
+		return nil, fmt.Errorf("%w '%s'", ErrUnsupportedCommand, parts[0])
+	}
+
+	checkTime := time.Unix(ts, 0)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "### Passive Check Result File ###\n")
+	fmt.Fprintf(&buf, "file_time=%d\n\n", time.Now().Unix())
+	if service == "" {
+		fmt.Fprintf(&buf, "### Nagios Host Check Result ###\n")
+	} else {
+		fmt.Fprintf(&buf, "### Nagios Service Check Result ###\n")
+	}
+	fmt.Fprintf(&buf, "# Time: %s\n", checkTime.Format(time.ANSIC))
+	fmt.Fprintf(&buf, "host_name=%s\n", host)
+	if service != "" {
+		fmt.Fprintf(&buf, "service_description=%s\n", service)
+	}
+	fmt.Fprintf(&buf, "check_type=1\n")
+	fmt.Fprintf(&buf, "check_options=0\n")
+	fmt.Fprintf(&buf, "scheduled_check=0\n")
+	fmt.Fprintf(&buf, "reschedule_check=0\n")
+	fmt.Fprintf(&buf, "latency=0.0\n")
+	fmt.Fprintf(&buf, "start_time=%d.0\n", ts)
+	fmt.Fprintf(&buf, "finish_time=%d.0\n", ts)
+	fmt.Fprintf(&buf, "early_timeout=0\n")
+	fmt.Fprintf(&buf, "exited_ok=1\n")
+	fmt.Fprintf(&buf, "return_code=%s\n", code)
+	fmt.Fprintf(&buf, "output=%s\n", output)
+
+	return buf.Bytes(), nil
+}
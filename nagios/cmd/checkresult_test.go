@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckResultWriter_Write(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkresults")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewCheckResultWriter(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+		absent   []string
+	}{
+		{
+			"[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;2;HTTP CRITICAL\\nlong output|time=1s",
+			[]string{
+				"### Nagios Service Check Result ###\n",
+				"host_name=web01\n",
+				"service_description=HTTP\n",
+				"check_type=1\n",
+				"start_time=1600000000.0\n",
+				"finish_time=1600000000.0\n",
+				"return_code=2\n",
+				"output=HTTP CRITICAL\\nlong output|time=1s\n",
+			},
+			nil,
+		},
+		{
+			"[1600000000] PROCESS_HOST_CHECK_RESULT;web01;1;PING CRITICAL",
+			[]string{
+				"### Nagios Host Check Result ###\n",
+				"host_name=web01\n",
+				"return_code=1\n",
+				"output=PING CRITICAL\n",
+			},
+			[]string{"service_description="},
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := w.Write([]byte(test.input)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "c??????"))
+		if err != nil || len(files) != 1 {
+			t.Fatalf("unexpected check result files, got: '%v'", files)
+		}
+
+		if _, err := os.Stat(files[0] + ".ok"); err != nil {
+			t.Errorf("expected .ok file: %s", err)
+		}
+
+		contents, err := ioutil.ReadFile(files[0])
+		if err != nil {
+			t.Fatalf("unable to read check result file: %s", err)
+		}
+
+		for _, line := range test.expected {
+			if !strings.Contains(string(contents), line) {
+				t.Errorf("expected '%s' in check result file, got: '%s'", line, contents)
+			}
+		}
+		for _, line := range test.absent {
+			if strings.Contains(string(contents), line) {
+				t.Errorf("unexpected '%s' in check result file, got: '%s'", line, contents)
+			}
+		}
+
+		os.Remove(files[0])
+		os.Remove(files[0] + ".ok")
+	}
+}
+
+func TestCheckResultWriter_Write_Unsupported(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkresults")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewCheckResultWriter(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write([]byte("[1600000000] DISABLE_NOTIFICATIONS")); !errors.Is(err, ErrUnsupportedCommand) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrUnsupportedCommand)
+	}
+
+	if _, err := w.Write([]byte("[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP")); err == nil {
+		t.Errorf("expected error for truncated command")
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("unexpected files written, got: '%d'", len(files))
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import "strings"
+
+// coalesceKey returns the key under which a queued command may be replaced
+// by a newer one, when the Writer is coalescing.
+//
+// Only passive check results are coalesced, keyed by host and service, as
+// nagios only keeps the latest result for each. Other commands return false.
+func coalesceKey(cmd string) (string, bool) {
+	end := strings.Index(cmd, "] ")
+	if !strings.HasPrefix(cmd, "[") || end == -1 {
+		return "", false
+	}
+
+	parts := strings.SplitN(cmd[end+2:], ";", 4)
+	switch {
+	case parts[0] == "PROCESS_SERVICE_CHECK_RESULT" && len(parts) == 4:
+		return "S;" + parts[1] + ";" + parts[2], true
+	case parts[0] == "PROCESS_HOST_CHECK_RESULT" && len(parts) >= 3:
+		return "H;" + parts[1], true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+var (
+	// ErrUnknownCommand is returned when a command is not in the catalogue.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrInvalidArgument is returned when a command argument fails
+	// validation.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// ArgumentError describes why a command argument failed validation.
+type ArgumentError struct {
+	Command  string
+	Argument string
+	Reason   string
+}
+
+func (e *ArgumentError) Error() string {
+	if e.Argument == "" {
+		return fmt.Sprintf("invalid arguments for %s: %s", e.Command, e.Reason)
+	}
+	return fmt.Sprintf("invalid argument '%s' for %s: %s", e.Argument, e.Command, e.Reason)
+}
+
+// Unwrap allows errors.Is to match ErrInvalidArgument.
+func (e *ArgumentError) Unwrap() error {
+	return ErrInvalidArgument
+}
+
+// ArgType identifies the type of an external command argument.
+type ArgType int
+
+const (
+	// Text is free text, such as an author, comment or plugin output.
+	Text ArgType = iota
+	HostName
+	ServiceDescription
+	HostGroupName
+	ServiceGroupName
+	ContactName
+	ContactGroupName
+	TimePeriodName
+	CommandName
+	VariableName
+	FileName
+
+	// Bool is rendered as 0 or 1.
+	Bool
+
+	// Int is a non-negative integer, such as an id or a count.
+	Int
+
+	// Float is a non-negative number, such as a check interval in minutes.
+	Float
+
+	// Timestamp is a unix timestamp.
+	Timestamp
+
+	// Duration is a number of seconds.
+	Duration
+
+	// HostStatus is a host check return code, 0 to 2.
+	HostStatus
+
+	// ServiceStatus is a service check return code, 0 to 3.
+	ServiceStatus
+
+	// StickyAck is an acknowledgement type, where 2 is sticky.
+	StickyAck
+
+	// ModifiedAttributes is a bitmask of xdata.ModifiedAttribute values.
+	ModifiedAttributes
+
+	// PluginOutput is the output of a check result, which may include
+	// performance data. It must be the last argument, as nagios reads it up
+	// to the end of the line, so it may contain ';'.
+	PluginOutput
+)
+
+// String returns a string representation of the ArgType.
+//
+// An empty string is returned for unknown types.
+func (t ArgType) String() string {
+	switch t {
+	case Text:
+		return "text"
+	case HostName:
+		return "host name"
+	case ServiceDescription:
+		return "service description"
+	case HostGroupName:
+		return "hostgroup name"
+	case ServiceGroupName:
+		return "servicegroup name"
+	case ContactName:
+		return "contact name"
+	case ContactGroupName:
+		return "contactgroup name"
+	case TimePeriodName:
+		return "timeperiod name"
+	case CommandName:
+		return "command name"
+	case VariableName:
+		return "variable name"
+	case FileName:
+		return "file name"
+	case Bool:
+		return "bool"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Timestamp:
+		return "timestamp"
+	case Duration:
+		return "duration"
+	case HostStatus:
+		return "host status"
+	case ServiceStatus:
+		return "service status"
+	case StickyAck:
+		return "sticky"
+	case ModifiedAttributes:
+		return "modified attributes"
+	case PluginOutput:
+		return "plugin output"
+	default:
+		return ""
+	}
+}
+
+// Arg describes a single argument of an external command.
+type Arg struct {
+	Name string
+	Type ArgType
+
+	// Optional arguments may be left empty. Trailing empty optional
+	// arguments are not rendered.
+	Optional bool
+}
+
+// Spec describes an external command and its arguments.
+type Spec struct {
+	Name string
+	Args []Arg
+}
+
+// Lookup returns the spec for the named external command.
+func Lookup(name string) (Spec, bool) {
+	spec, ok := catalogue[name]
+	return spec, ok
+}
+
+// Command is a validated nagios external command.
+type Command struct {
+	Name string
+	Args []string
+
+	// Time is rendered as the command timestamp. The current time is used
+	// if it is not set.
+	Time time.Time
+}
+
+// At returns a copy of the command with its timestamp set to t.
+func (c Command) At(t time.Time) Command {
+	c.Time = t
+	return c
+}
+
+// String renders the command in the form expected by the nagios external
+// commands file, '[time] NAME;arg1;arg2', without a trailing new-line.
+func (c Command) String() string {
+	t := c.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%d] %s", t.Unix(), c.Name)
+	for _, arg := range c.Args {
+		b.WriteByte(';')
+		b.WriteString(arg)
+	}
+	return b.String()
+}
+
+// WriteTo writes the rendered command to w, which is usually a Writer.
+func (c Command) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write([]byte(c.String()))
+	return int64(n), err
+}
+
+// Parse validates the arguments of the named external command, given in
+// their rendered form, and returns the command.
+func Parse(name string, args []string) (Command, error) {
+	spec, ok := Lookup(name)
+	if !ok {
+		return Command{}, fmt.Errorf("%w '%s'", ErrUnknownCommand, name)
+	}
+
+	return spec.build(args)
+}
+
+// newCommand formats and validates args against the catalogue entry for the
+// named command.
+func newCommand(name string, args ...interface{}) (Command, error) {
+	spec, ok := Lookup(name)
+	if !ok {
+		return Command{}, fmt.Errorf("%w '%s'", ErrUnknownCommand, name)
+	}
+
+	strs := make([]string, 0, len(args))
+	for i, arg := range args {
+		s, err := formatArg(arg)
+		if err != nil {
+			argName := ""
+			if i < len(spec.Args) {
+				argName = spec.Args[i].Name
+			}
+			return Command{}, &ArgumentError{Command: name, Argument: argName, Reason: err.Error()}
+		}
+		strs = append(strs, s)
+	}
+
+	return spec.build(strs)
+}
+
+func (s Spec) build(args []string) (Command, error) {
+	required := 0
+	for _, arg := range s.Args {
+		if !arg.Optional {
+			required++
+		}
+	}
+
+	if len(args) < required || len(args) > len(s.Args) {
+		reason := fmt.Sprintf("expected %d arguments, got %d", len(s.Args), len(args))
+		if required != len(s.Args) {
+			reason = fmt.Sprintf("expected %d to %d arguments, got %d", required, len(s.Args), len(args))
+		}
+		return Command{}, &ArgumentError{Command: s.Name, Reason: reason}
+	}
+
+	// trailing empty optional arguments are left off the command.
+	for len(args) > required && args[len(args)-1] == "" {
+		args = args[:len(args)-1]
+	}
+
+	rendered := make([]string, 0, len(args))
+	for i, value := range args {
+		arg := s.Args[i]
+		if value == "" && arg.Optional {
+			rendered = append(rendered, "")
+			continue
+		}
+
+		if err := validateArg(arg.Type, value); err != nil {
+			return Command{}, &ArgumentError{Command: s.Name, Argument: arg.Name, Reason: err.Error()}
+		}
+		if arg.Type == PluginOutput {
+			rendered = append(rendered, SanitizeOutput(value))
+			continue
+		}
+		rendered = append(rendered, Sanitize(value))
+	}
+
+	return Command{
+		Name: s.Name,
+		Args: rendered,
+	}, nil
+}
+
+// formatArg converts a typed constructor argument into its rendered form.
+func formatArg(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		if v.IsZero() {
+			return "", nil
+		}
+		return strconv.FormatInt(v.Unix(), 10), nil
+	case time.Duration:
+		return strconv.FormatInt(int64(v/time.Second), 10), nil
+	case xdata.HostState:
+		return strconv.Itoa(int(v)), nil
+	case xdata.ServiceState:
+		return strconv.Itoa(int(v)), nil
+	case xdata.AcknowledgementType:
+		if v == xdata.Sticky {
+			return "2", nil
+		}
+		return "1", nil
+	case xdata.ModifiedAttribute:
+		return strconv.Itoa(int(v)), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+func validateArg(t ArgType, v string) error {
+	switch t {
+	case Text, PluginOutput:
+		return nil
+
+	case HostName, ServiceDescription, HostGroupName, ServiceGroupName, ContactName,
+		ContactGroupName, TimePeriodName, CommandName, VariableName, FileName:
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("must not be empty")
+		}
+		return nil
+
+	case Bool:
+		if v != "0" && v != "1" {
+			return fmt.Errorf("must be 0 or 1")
+		}
+		return nil
+
+	case Int, Duration, ModifiedAttributes:
+		return validateRange(v, 0, -1)
+
+	case Float:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("must be a non-negative number")
+		}
+		return nil
+
+	case Timestamp:
+		if err := validateRange(v, 1, -1); err != nil {
+			return fmt.Errorf("must be a unix timestamp")
+		}
+		return nil
+
+	case HostStatus:
+		return validateRange(v, 0, 2)
+
+	case ServiceStatus:
+		return validateRange(v, 0, 3)
+
+	case StickyAck:
+		return validateRange(v, 0, 2)
+
+	default:
+		return fmt.Errorf("unknown argument type")
+	}
+}
+
+// validateRange checks v is an integer between min and max. A negative max
+// means there is no upper bound.
+func validateRange(v string, min, max int64) error {
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("must be an integer")
+	}
+
+	if i < min || (max >= 0 && i > max) {
+		if max < 0 {
+			return fmt.Errorf("must be at least %d", min)
+		}
+		return fmt.Errorf("must be between %d and %d", min, max)
+	}
+
+	return nil
+}
@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+func TestCommand_String(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	start := time.Unix(1600000100, 0)
+	end := time.Unix(1600003700, 0)
+
+	must := func(c Command, err error) Command {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		input    Command
+		expected string
+	}{
+		{
+			// nagios reads the plugin output to the end of the line, so only
+			// new-lines are escaped.
+			must(ProcessServiceCheckResult("web01", "HTTP", xdata.Critical, "down;\nbadly")),
+			"[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;2;down;\\nbadly",
+		},
+		{
+			must(ProcessServiceCheckResult("web01", "HTTP", xdata.Ok, "up|'time'=0.5s;1;2;0")),
+			"[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;0;up|'time'=0.5s;1;2;0",
+		},
+		{
+			must(ProcessHostCheckResult("web01", xdata.Down, "unreachable")),
+			"[1600000000] PROCESS_HOST_CHECK_RESULT;web01;1;unreachable",
+		},
+		{
+			must(AddSvcComment("web01", "HTTP", true, "admin", "looking")),
+			"[1600000000] ADD_SVC_COMMENT;web01;HTTP;1;admin;looking",
+		},
+		{
+			must(DelHostComment(12)),
+			"[1600000000] DEL_HOST_COMMENT;12",
+		},
+		{
+			must(AcknowledgeHostProblem("web01", xdata.Sticky, true, false, "admin", "on it")),
+			"[1600000000] ACKNOWLEDGE_HOST_PROBLEM;web01;2;1;0;admin;on it",
+		},
+		{
+			must(AcknowledgeSvcProblemExpire("web01", "HTTP", xdata.Normal, false, true, end, "admin", "on it")),
+			"[1600000000] ACKNOWLEDGE_SVC_PROBLEM_EXPIRE;web01;HTTP;1;0;1;1600003700;admin;on it",
+		},
+		{
+			must(ScheduleSvcDowntime("web01", "HTTP", start, end, false, 0, 30*time.Minute, "admin", "patching")),
+			"[1600000000] SCHEDULE_SVC_DOWNTIME;web01;HTTP;1600000100;1600003700;0;0;1800;admin;patching",
+		},
+		{
+			must(DelDowntimeByHostName("web01", "", time.Time{}, "")),
+			"[1600000000] DEL_DOWNTIME_BY_HOST_NAME;web01",
+		},
+		{
+			must(DelDowntimeByHostName("web01", "", start, "")),
+			"[1600000000] DEL_DOWNTIME_BY_HOST_NAME;web01;;1600000100",
+		},
+		{
+			must(ScheduleForcedSvcCheck("web01", "HTTP", start)),
+			"[1600000000] SCHEDULE_FORCED_SVC_CHECK;web01;HTTP;1600000100",
+		},
+		{
+			must(ChangeNormalSvcCheckInterval("web01", "HTTP", 2.5)),
+			"[1600000000] CHANGE_NORMAL_SVC_CHECK_INTERVAL;web01;HTTP;2.5",
+		},
+		{
+			must(ChangeHostModattr("web01", xdata.NotificationsEnabled|xdata.ActiveChecksEnabled)),
+			"[1600000000] CHANGE_HOST_MODATTR;web01;3",
+		},
+		{
+			must(ChangeCustomHostVar("web01", "_OWNER", "ops")),
+			"[1600000000] CHANGE_CUSTOM_HOST_VAR;web01;_OWNER;ops",
+		},
+		{
+			must(SendCustomSvcNotification("web01", "HTTP", 3, "admin", "test")),
+			"[1600000000] SEND_CUSTOM_SVC_NOTIFICATION;web01;HTTP;3;admin;test",
+		},
+		{
+			must(DisableHostgroupSvcChecks("web")),
+			"[1600000000] DISABLE_HOSTGROUP_SVC_CHECKS;web",
+		},
+		{
+			must(ProcessFile("/tmp/commands", true)),
+			"[1600000000] PROCESS_FILE;/tmp/commands;1",
+		},
+		{
+			DisableNotifications(),
+			"[1600000000] DISABLE_NOTIFICATIONS",
+		},
+	}
+
+	for _, test := range tests {
+		s := test.input.At(now).String()
+		if s != test.expected {
+			t.Errorf("unexpected command, got: '%s', want: '%s'", s, test.expected)
+		}
+	}
+}
+
+func TestCommand_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (Command, error)
+		argument string
+	}{
+		{"empty host", func() (Command, error) { return DisableHostCheck("") }, "host_name"},
+		{"empty service", func() (Command, error) { return DisableSvcCheck("web01", " ") }, "service_description"},
+		{"service state", func() (Command, error) {
+			return ProcessServiceCheckResult("web01", "HTTP", xdata.ServiceState(4), "")
+		}, "return_code"},
+		{"host state", func() (Command, error) {
+			return ProcessHostCheckResult("web01", xdata.Unreachable+1, "")
+		}, "status_code"},
+		{"negative id", func() (Command, error) { return DelSvcDowntime(-1) }, "downtime_id"},
+		{"zero timestamp", func() (Command, error) { return ScheduleHostCheck("web01", time.Time{}) }, "check_time"},
+		{"negative interval", func() (Command, error) { return ChangeRetryHostCheckInterval("web01", -1) }, "check_interval"},
+	}
+
+	for _, test := range tests {
+		_, err := test.fn()
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, ErrInvalidArgument)
+			continue
+		}
+
+		var argErr *ArgumentError
+		if !errors.As(err, &argErr) || argErr.Argument != test.argument {
+			t.Errorf("%s: unexpected argument, got: '%v', want: '%s'", test.name, err, test.argument)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		err      error
+	}{
+		{"SCHEDULE_HOST_CHECK", []string{"web01", "1600000100"}, "[1600000000] SCHEDULE_HOST_CHECK;web01;1600000100", nil},
+		{"ENABLE_NOTIFICATIONS", nil, "[1600000000] ENABLE_NOTIFICATIONS", nil},
+		{"ADD_HOST_COMMENT", []string{"web01", "yes", "admin", "text"}, "", ErrInvalidArgument},
+		{"ADD_HOST_COMMENT", []string{"web01", "1", "admin"}, "", ErrInvalidArgument},
+		{"DEL_DOWNTIME_BY_HOST_NAME", []string{"web01", "", "", ""}, "[1600000000] DEL_DOWNTIME_BY_HOST_NAME;web01", nil},
+		{"NOT_A_COMMAND", nil, "", ErrUnknownCommand},
+	}
+
+	for _, test := range tests {
+		c, err := Parse(test.name, test.args)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if s := c.At(time.Unix(1600000000, 0)).String(); s != test.expected {
+			t.Errorf("%s: unexpected command, got: '%s', want: '%s'", test.name, s, test.expected)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if len(catalogue) < 150 {
+		t.Errorf("unexpected catalogue size, got: '%d', want at least: '%d'", len(catalogue), 150)
+	}
+
+	for name, spec := range catalogue {
+		if spec.Name != name {
+			t.Errorf("unexpected spec name, got: '%s', want: '%s'", spec.Name, name)
+		}
+		for _, arg := range spec.Args {
+			if arg.Type.String() == "" {
+				t.Errorf("%s: unknown type for argument '%s'", name, arg.Name)
+			}
+		}
+	}
+}
+
+// TestCatalogue checks each catalogue entry against the argument list
+// documented for it, so that a missing or misordered argument fails.
+func TestCatalogue(t *testing.T) {
+	tests := []struct {
+		name     string
+		types    []ArgType
+		expected string
+	}{
+		{"ADD_HOST_COMMENT", []ArgType{HostName, Bool, Text, Text}, "[1600000000] ADD_HOST_COMMENT;web01;1;admin;comment"},
+		{"ADD_SVC_COMMENT", []ArgType{HostName, ServiceDescription, Bool, Text, Text}, "[1600000000] ADD_SVC_COMMENT;web01;HTTP;1;admin;comment"},
+		{"DEL_HOST_COMMENT", []ArgType{Int}, "[1600000000] DEL_HOST_COMMENT;3"},
+		{"DEL_SVC_COMMENT", []ArgType{Int}, "[1600000000] DEL_SVC_COMMENT;3"},
+		{"DEL_ALL_HOST_COMMENTS", []ArgType{HostName}, "[1600000000] DEL_ALL_HOST_COMMENTS;web01"},
+		{"DEL_ALL_SVC_COMMENTS", []ArgType{HostName, ServiceDescription}, "[1600000000] DEL_ALL_SVC_COMMENTS;web01;HTTP"},
+		{"ACKNOWLEDGE_HOST_PROBLEM", []ArgType{HostName, StickyAck, Bool, Bool, Text, Text}, "[1600000000] ACKNOWLEDGE_HOST_PROBLEM;web01;2;1;0;admin;comment"},
+		{"ACKNOWLEDGE_HOST_PROBLEM_EXPIRE", []ArgType{HostName, StickyAck, Bool, Bool, Timestamp, Text, Text}, "[1600000000] ACKNOWLEDGE_HOST_PROBLEM_EXPIRE;web01;2;1;0;1600000100;admin;comment"},
+		{"ACKNOWLEDGE_SVC_PROBLEM", []ArgType{HostName, ServiceDescription, StickyAck, Bool, Bool, Text, Text}, "[1600000000] ACKNOWLEDGE_SVC_PROBLEM;web01;HTTP;2;1;0;admin;comment"},
+		{"ACKNOWLEDGE_SVC_PROBLEM_EXPIRE", []ArgType{HostName, ServiceDescription, StickyAck, Bool, Bool, Timestamp, Text, Text}, "[1600000000] ACKNOWLEDGE_SVC_PROBLEM_EXPIRE;web01;HTTP;2;1;0;1600000100;admin;comment"},
+		{"REMOVE_HOST_ACKNOWLEDGEMENT", []ArgType{HostName}, "[1600000000] REMOVE_HOST_ACKNOWLEDGEMENT;web01"},
+		{"REMOVE_SVC_ACKNOWLEDGEMENT", []ArgType{HostName, ServiceDescription}, "[1600000000] REMOVE_SVC_ACKNOWLEDGEMENT;web01;HTTP"},
+		{"SCHEDULE_HOST_DOWNTIME", []ArgType{HostName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_HOST_DOWNTIME;web01;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_SVC_DOWNTIME", []ArgType{HostName, ServiceDescription, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_SVC_DOWNTIME;web01;HTTP;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_HOST_SVC_DOWNTIME", []ArgType{HostName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_HOST_SVC_DOWNTIME;web01;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_AND_PROPAGATE_HOST_DOWNTIME", []ArgType{HostName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_AND_PROPAGATE_HOST_DOWNTIME;web01;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_AND_PROPAGATE_TRIGGERED_HOST_DOWNTIME", []ArgType{HostName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_AND_PROPAGATE_TRIGGERED_HOST_DOWNTIME;web01;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_HOSTGROUP_HOST_DOWNTIME", []ArgType{HostGroupName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_HOSTGROUP_HOST_DOWNTIME;web;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_HOSTGROUP_SVC_DOWNTIME", []ArgType{HostGroupName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_HOSTGROUP_SVC_DOWNTIME;web;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_SERVICEGROUP_HOST_DOWNTIME", []ArgType{ServiceGroupName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_SERVICEGROUP_HOST_DOWNTIME;http;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"SCHEDULE_SERVICEGROUP_SVC_DOWNTIME", []ArgType{ServiceGroupName, Timestamp, Timestamp, Bool, Int, Duration, Text, Text}, "[1600000000] SCHEDULE_SERVICEGROUP_SVC_DOWNTIME;http;1600000100;1600003700;1;3;1800;admin;comment"},
+		{"DEL_HOST_DOWNTIME", []ArgType{Int}, "[1600000000] DEL_HOST_DOWNTIME;3"},
+		{"DEL_SVC_DOWNTIME", []ArgType{Int}, "[1600000000] DEL_SVC_DOWNTIME;3"},
+		{"DEL_DOWNTIME_BY_HOST_NAME", []ArgType{HostName, ServiceDescription, Timestamp, Text}, "[1600000000] DEL_DOWNTIME_BY_HOST_NAME;web01;HTTP;1600000100;admin"},
+		{"DEL_DOWNTIME_BY_HOSTGROUP_NAME", []ArgType{HostGroupName, HostName, ServiceDescription, Timestamp, Text}, "[1600000000] DEL_DOWNTIME_BY_HOSTGROUP_NAME;web;web01;HTTP;1600000100;admin"},
+		{"DEL_DOWNTIME_BY_START_TIME_COMMENT", []ArgType{Timestamp, Text}, "[1600000000] DEL_DOWNTIME_BY_START_TIME_COMMENT;1600000100;admin"},
+		{"PROCESS_HOST_CHECK_RESULT", []ArgType{HostName, HostStatus, PluginOutput}, "[1600000000] PROCESS_HOST_CHECK_RESULT;web01;1;down;badly"},
+		{"PROCESS_SERVICE_CHECK_RESULT", []ArgType{HostName, ServiceDescription, ServiceStatus, PluginOutput}, "[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;2;down;badly"},
+		{"SCHEDULE_HOST_CHECK", []ArgType{HostName, Timestamp}, "[1600000000] SCHEDULE_HOST_CHECK;web01;1600000100"},
+		{"SCHEDULE_FORCED_HOST_CHECK", []ArgType{HostName, Timestamp}, "[1600000000] SCHEDULE_FORCED_HOST_CHECK;web01;1600000100"},
+		{"SCHEDULE_HOST_SVC_CHECKS", []ArgType{HostName, Timestamp}, "[1600000000] SCHEDULE_HOST_SVC_CHECKS;web01;1600000100"},
+		{"SCHEDULE_FORCED_HOST_SVC_CHECKS", []ArgType{HostName, Timestamp}, "[1600000000] SCHEDULE_FORCED_HOST_SVC_CHECKS;web01;1600000100"},
+		{"SCHEDULE_SVC_CHECK", []ArgType{HostName, ServiceDescription, Timestamp}, "[1600000000] SCHEDULE_SVC_CHECK;web01;HTTP;1600000100"},
+		{"SCHEDULE_FORCED_SVC_CHECK", []ArgType{HostName, ServiceDescription, Timestamp}, "[1600000000] SCHEDULE_FORCED_SVC_CHECK;web01;HTTP;1600000100"},
+		{"ENABLE_HOST_CHECK", []ArgType{HostName}, "[1600000000] ENABLE_HOST_CHECK;web01"},
+		{"ENABLE_SVC_CHECK", []ArgType{HostName, ServiceDescription}, "[1600000000] ENABLE_SVC_CHECK;web01;HTTP"},
+		{"ENABLE_HOST_SVC_CHECKS", []ArgType{HostName}, "[1600000000] ENABLE_HOST_SVC_CHECKS;web01"},
+		{"ENABLE_PASSIVE_HOST_CHECKS", []ArgType{HostName}, "[1600000000] ENABLE_PASSIVE_HOST_CHECKS;web01"},
+		{"ENABLE_PASSIVE_SVC_CHECKS", []ArgType{HostName, ServiceDescription}, "[1600000000] ENABLE_PASSIVE_SVC_CHECKS;web01;HTTP"},
+		{"ENABLE_HOSTGROUP_HOST_CHECKS", []ArgType{HostGroupName}, "[1600000000] ENABLE_HOSTGROUP_HOST_CHECKS;web"},
+		{"ENABLE_HOSTGROUP_SVC_CHECKS", []ArgType{HostGroupName}, "[1600000000] ENABLE_HOSTGROUP_SVC_CHECKS;web"},
+		{"ENABLE_HOSTGROUP_PASSIVE_HOST_CHECKS", []ArgType{HostGroupName}, "[1600000000] ENABLE_HOSTGROUP_PASSIVE_HOST_CHECKS;web"},
+		{"ENABLE_HOSTGROUP_PASSIVE_SVC_CHECKS", []ArgType{HostGroupName}, "[1600000000] ENABLE_HOSTGROUP_PASSIVE_SVC_CHECKS;web"},
+		{"ENABLE_SERVICEGROUP_HOST_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] ENABLE_SERVICEGROUP_HOST_CHECKS;http"},
+		{"ENABLE_SERVICEGROUP_SVC_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] ENABLE_SERVICEGROUP_SVC_CHECKS;http"},
+		{"ENABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] ENABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS;http"},
+		{"ENABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] ENABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS;http"},
+		{"ENABLE_HOST_EVENT_HANDLER", []ArgType{HostName}, "[1600000000] ENABLE_HOST_EVENT_HANDLER;web01"},
+		{"ENABLE_SVC_EVENT_HANDLER", []ArgType{HostName, ServiceDescription}, "[1600000000] ENABLE_SVC_EVENT_HANDLER;web01;HTTP"},
+		{"ENABLE_HOST_FLAP_DETECTION", []ArgType{HostName}, "[1600000000] ENABLE_HOST_FLAP_DETECTION;web01"},
+		{"ENABLE_SVC_FLAP_DETECTION", []ArgType{HostName, ServiceDescription}, "[1600000000] ENABLE_SVC_FLAP_DETECTION;web01;HTTP"},
+		{"DISABLE_HOST_CHECK", []ArgType{HostName}, "[1600000000] DISABLE_HOST_CHECK;web01"},
+		{"DISABLE_SVC_CHECK", []ArgType{HostName, ServiceDescription}, "[1600000000] DISABLE_SVC_CHECK;web01;HTTP"},
+		{"DISABLE_HOST_SVC_CHECKS", []ArgType{HostName}, "[1600000000] DISABLE_HOST_SVC_CHECKS;web01"},
+		{"DISABLE_PASSIVE_HOST_CHECKS", []ArgType{HostName}, "[1600000000] DISABLE_PASSIVE_HOST_CHECKS;web01"},
+		{"DISABLE_PASSIVE_SVC_CHECKS", []ArgType{HostName, ServiceDescription}, "[1600000000] DISABLE_PASSIVE_SVC_CHECKS;web01;HTTP"},
+		{"DISABLE_HOSTGROUP_HOST_CHECKS", []ArgType{HostGroupName}, "[1600000000] DISABLE_HOSTGROUP_HOST_CHECKS;web"},
+		{"DISABLE_HOSTGROUP_SVC_CHECKS", []ArgType{HostGroupName}, "[1600000000] DISABLE_HOSTGROUP_SVC_CHECKS;web"},
+		{"DISABLE_HOSTGROUP_PASSIVE_HOST_CHECKS", []ArgType{HostGroupName}, "[1600000000] DISABLE_HOSTGROUP_PASSIVE_HOST_CHECKS;web"},
+		{"DISABLE_HOSTGROUP_PASSIVE_SVC_CHECKS", []ArgType{HostGroupName}, "[1600000000] DISABLE_HOSTGROUP_PASSIVE_SVC_CHECKS;web"},
+		{"DISABLE_SERVICEGROUP_HOST_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] DISABLE_SERVICEGROUP_HOST_CHECKS;http"},
+		{"DISABLE_SERVICEGROUP_SVC_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] DISABLE_SERVICEGROUP_SVC_CHECKS;http"},
+		{"DISABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] DISABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS;http"},
+		{"DISABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS", []ArgType{ServiceGroupName}, "[1600000000] DISABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS;http"},
+		{"DISABLE_HOST_EVENT_HANDLER", []ArgType{HostName}, "[1600000000] DISABLE_HOST_EVENT_HANDLER;web01"},
+		{"DISABLE_SVC_EVENT_HANDLER", []ArgType{HostName, ServiceDescription}, "[1600000000] DISABLE_SVC_EVENT_HANDLER;web01;HTTP"},
+		{"DISABLE_HOST_FLAP_DETECTION", []ArgType{HostName}, "[1600000000] DISABLE_HOST_FLAP_DETECTION;web01"},
+		{"DISABLE_SVC_FLAP_DETECTION", []ArgType{HostName, ServiceDescription}, "[1600000000] DISABLE_SVC_FLAP_DETECTION;web01;HTTP"},
+		{"START_OBSESSING_OVER_HOST", []ArgType{HostName}, "[1600000000] START_OBSESSING_OVER_HOST;web01"},
+		{"START_OBSESSING_OVER_SVC", []ArgType{HostName, ServiceDescription}, "[1600000000] START_OBSESSING_OVER_SVC;web01;HTTP"},
+		{"STOP_OBSESSING_OVER_HOST", []ArgType{HostName}, "[1600000000] STOP_OBSESSING_OVER_HOST;web01"},
+		{"STOP_OBSESSING_OVER_SVC", []ArgType{HostName, ServiceDescription}, "[1600000000] STOP_OBSESSING_OVER_SVC;web01;HTTP"},
+		{"ENABLE_HOST_NOTIFICATIONS", []ArgType{HostName}, "[1600000000] ENABLE_HOST_NOTIFICATIONS;web01"},
+		{"ENABLE_SVC_NOTIFICATIONS", []ArgType{HostName, ServiceDescription}, "[1600000000] ENABLE_SVC_NOTIFICATIONS;web01;HTTP"},
+		{"ENABLE_HOST_SVC_NOTIFICATIONS", []ArgType{HostName}, "[1600000000] ENABLE_HOST_SVC_NOTIFICATIONS;web01"},
+		{"ENABLE_HOST_AND_CHILD_NOTIFICATIONS", []ArgType{HostName}, "[1600000000] ENABLE_HOST_AND_CHILD_NOTIFICATIONS;web01"},
+		{"ENABLE_ALL_NOTIFICATIONS_BEYOND_HOST", []ArgType{HostName}, "[1600000000] ENABLE_ALL_NOTIFICATIONS_BEYOND_HOST;web01"},
+		{"ENABLE_HOSTGROUP_HOST_NOTIFICATIONS", []ArgType{HostGroupName}, "[1600000000] ENABLE_HOSTGROUP_HOST_NOTIFICATIONS;web"},
+		{"ENABLE_HOSTGROUP_SVC_NOTIFICATIONS", []ArgType{HostGroupName}, "[1600000000] ENABLE_HOSTGROUP_SVC_NOTIFICATIONS;web"},
+		{"ENABLE_SERVICEGROUP_HOST_NOTIFICATIONS", []ArgType{ServiceGroupName}, "[1600000000] ENABLE_SERVICEGROUP_HOST_NOTIFICATIONS;http"},
+		{"ENABLE_SERVICEGROUP_SVC_NOTIFICATIONS", []ArgType{ServiceGroupName}, "[1600000000] ENABLE_SERVICEGROUP_SVC_NOTIFICATIONS;http"},
+		{"ENABLE_CONTACT_HOST_NOTIFICATIONS", []ArgType{ContactName}, "[1600000000] ENABLE_CONTACT_HOST_NOTIFICATIONS;admin"},
+		{"ENABLE_CONTACT_SVC_NOTIFICATIONS", []ArgType{ContactName}, "[1600000000] ENABLE_CONTACT_SVC_NOTIFICATIONS;admin"},
+		{"ENABLE_CONTACTGROUP_HOST_NOTIFICATIONS", []ArgType{ContactGroupName}, "[1600000000] ENABLE_CONTACTGROUP_HOST_NOTIFICATIONS;admins"},
+		{"ENABLE_CONTACTGROUP_SVC_NOTIFICATIONS", []ArgType{ContactGroupName}, "[1600000000] ENABLE_CONTACTGROUP_SVC_NOTIFICATIONS;admins"},
+		{"DISABLE_HOST_NOTIFICATIONS", []ArgType{HostName}, "[1600000000] DISABLE_HOST_NOTIFICATIONS;web01"},
+		{"DISABLE_SVC_NOTIFICATIONS", []ArgType{HostName, ServiceDescription}, "[1600000000] DISABLE_SVC_NOTIFICATIONS;web01;HTTP"},
+		{"DISABLE_HOST_SVC_NOTIFICATIONS", []ArgType{HostName}, "[1600000000] DISABLE_HOST_SVC_NOTIFICATIONS;web01"},
+		{"DISABLE_HOST_AND_CHILD_NOTIFICATIONS", []ArgType{HostName}, "[1600000000] DISABLE_HOST_AND_CHILD_NOTIFICATIONS;web01"},
+		{"DISABLE_ALL_NOTIFICATIONS_BEYOND_HOST", []ArgType{HostName}, "[1600000000] DISABLE_ALL_NOTIFICATIONS_BEYOND_HOST;web01"},
+		{"DISABLE_HOSTGROUP_HOST_NOTIFICATIONS", []ArgType{HostGroupName}, "[1600000000] DISABLE_HOSTGROUP_HOST_NOTIFICATIONS;web"},
+		{"DISABLE_HOSTGROUP_SVC_NOTIFICATIONS", []ArgType{HostGroupName}, "[1600000000] DISABLE_HOSTGROUP_SVC_NOTIFICATIONS;web"},
+		{"DISABLE_SERVICEGROUP_HOST_NOTIFICATIONS", []ArgType{ServiceGroupName}, "[1600000000] DISABLE_SERVICEGROUP_HOST_NOTIFICATIONS;http"},
+		{"DISABLE_SERVICEGROUP_SVC_NOTIFICATIONS", []ArgType{ServiceGroupName}, "[1600000000] DISABLE_SERVICEGROUP_SVC_NOTIFICATIONS;http"},
+		{"DISABLE_CONTACT_HOST_NOTIFICATIONS", []ArgType{ContactName}, "[1600000000] DISABLE_CONTACT_HOST_NOTIFICATIONS;admin"},
+		{"DISABLE_CONTACT_SVC_NOTIFICATIONS", []ArgType{ContactName}, "[1600000000] DISABLE_CONTACT_SVC_NOTIFICATIONS;admin"},
+		{"DISABLE_CONTACTGROUP_HOST_NOTIFICATIONS", []ArgType{ContactGroupName}, "[1600000000] DISABLE_CONTACTGROUP_HOST_NOTIFICATIONS;admins"},
+		{"DISABLE_CONTACTGROUP_SVC_NOTIFICATIONS", []ArgType{ContactGroupName}, "[1600000000] DISABLE_CONTACTGROUP_SVC_NOTIFICATIONS;admins"},
+		{"DELAY_HOST_NOTIFICATION", []ArgType{HostName, Timestamp}, "[1600000000] DELAY_HOST_NOTIFICATION;web01;1600000100"},
+		{"DELAY_SVC_NOTIFICATION", []ArgType{HostName, ServiceDescription, Timestamp}, "[1600000000] DELAY_SVC_NOTIFICATION;web01;HTTP;1600000100"},
+		{"SEND_CUSTOM_HOST_NOTIFICATION", []ArgType{HostName, Int, Text, Text}, "[1600000000] SEND_CUSTOM_HOST_NOTIFICATION;web01;3;admin;comment"},
+		{"SEND_CUSTOM_SVC_NOTIFICATION", []ArgType{HostName, ServiceDescription, Int, Text, Text}, "[1600000000] SEND_CUSTOM_SVC_NOTIFICATION;web01;HTTP;3;admin;comment"},
+		{"SET_HOST_NOTIFICATION_NUMBER", []ArgType{HostName, Int}, "[1600000000] SET_HOST_NOTIFICATION_NUMBER;web01;3"},
+		{"SET_SVC_NOTIFICATION_NUMBER", []ArgType{HostName, ServiceDescription, Int}, "[1600000000] SET_SVC_NOTIFICATION_NUMBER;web01;HTTP;3"},
+		{"CHANGE_HOST_CHECK_COMMAND", []ArgType{HostName, CommandName}, "[1600000000] CHANGE_HOST_CHECK_COMMAND;web01;check_http"},
+		{"CHANGE_SVC_CHECK_COMMAND", []ArgType{HostName, ServiceDescription, CommandName}, "[1600000000] CHANGE_SVC_CHECK_COMMAND;web01;HTTP;check_http"},
+		{"CHANGE_HOST_CHECK_TIMEPERIOD", []ArgType{HostName, TimePeriodName}, "[1600000000] CHANGE_HOST_CHECK_TIMEPERIOD;web01;24x7"},
+		{"CHANGE_SVC_CHECK_TIMEPERIOD", []ArgType{HostName, ServiceDescription, TimePeriodName}, "[1600000000] CHANGE_SVC_CHECK_TIMEPERIOD;web01;HTTP;24x7"},
+		{"CHANGE_HOST_NOTIFICATION_TIMEPERIOD", []ArgType{HostName, TimePeriodName}, "[1600000000] CHANGE_HOST_NOTIFICATION_TIMEPERIOD;web01;24x7"},
+		{"CHANGE_SVC_NOTIFICATION_TIMEPERIOD", []ArgType{HostName, ServiceDescription, TimePeriodName}, "[1600000000] CHANGE_SVC_NOTIFICATION_TIMEPERIOD;web01;HTTP;24x7"},
+		{"CHANGE_CONTACT_HOST_NOTIFICATION_TIMEPERIOD", []ArgType{ContactName, TimePeriodName}, "[1600000000] CHANGE_CONTACT_HOST_NOTIFICATION_TIMEPERIOD;admin;24x7"},
+		{"CHANGE_CONTACT_SVC_NOTIFICATION_TIMEPERIOD", []ArgType{ContactName, TimePeriodName}, "[1600000000] CHANGE_CONTACT_SVC_NOTIFICATION_TIMEPERIOD;admin;24x7"},
+		{"CHANGE_HOST_EVENT_HANDLER", []ArgType{HostName, CommandName}, "[1600000000] CHANGE_HOST_EVENT_HANDLER;web01;check_http"},
+		{"CHANGE_SVC_EVENT_HANDLER", []ArgType{HostName, ServiceDescription, CommandName}, "[1600000000] CHANGE_SVC_EVENT_HANDLER;web01;HTTP;check_http"},
+		{"CHANGE_GLOBAL_HOST_EVENT_HANDLER", []ArgType{CommandName}, "[1600000000] CHANGE_GLOBAL_HOST_EVENT_HANDLER;check_http"},
+		{"CHANGE_GLOBAL_SVC_EVENT_HANDLER", []ArgType{CommandName}, "[1600000000] CHANGE_GLOBAL_SVC_EVENT_HANDLER;check_http"},
+		{"CHANGE_MAX_HOST_CHECK_ATTEMPTS", []ArgType{HostName, Int}, "[1600000000] CHANGE_MAX_HOST_CHECK_ATTEMPTS;web01;3"},
+		{"CHANGE_MAX_SVC_CHECK_ATTEMPTS", []ArgType{HostName, ServiceDescription, Int}, "[1600000000] CHANGE_MAX_SVC_CHECK_ATTEMPTS;web01;HTTP;3"},
+		{"CHANGE_NORMAL_HOST_CHECK_INTERVAL", []ArgType{HostName, Float}, "[1600000000] CHANGE_NORMAL_HOST_CHECK_INTERVAL;web01;2.5"},
+		{"CHANGE_NORMAL_SVC_CHECK_INTERVAL", []ArgType{HostName, ServiceDescription, Float}, "[1600000000] CHANGE_NORMAL_SVC_CHECK_INTERVAL;web01;HTTP;2.5"},
+		{"CHANGE_RETRY_HOST_CHECK_INTERVAL", []ArgType{HostName, Float}, "[1600000000] CHANGE_RETRY_HOST_CHECK_INTERVAL;web01;2.5"},
+		{"CHANGE_RETRY_SVC_CHECK_INTERVAL", []ArgType{HostName, ServiceDescription, Float}, "[1600000000] CHANGE_RETRY_SVC_CHECK_INTERVAL;web01;HTTP;2.5"},
+		{"CHANGE_HOST_MODATTR", []ArgType{HostName, ModifiedAttributes}, "[1600000000] CHANGE_HOST_MODATTR;web01;3"},
+		{"CHANGE_SVC_MODATTR", []ArgType{HostName, ServiceDescription, ModifiedAttributes}, "[1600000000] CHANGE_SVC_MODATTR;web01;HTTP;3"},
+		{"CHANGE_CONTACT_MODATTR", []ArgType{ContactName, ModifiedAttributes}, "[1600000000] CHANGE_CONTACT_MODATTR;admin;3"},
+		{"CHANGE_CONTACT_MODHATTR", []ArgType{ContactName, ModifiedAttributes}, "[1600000000] CHANGE_CONTACT_MODHATTR;admin;3"},
+		{"CHANGE_CONTACT_MODSATTR", []ArgType{ContactName, ModifiedAttributes}, "[1600000000] CHANGE_CONTACT_MODSATTR;admin;3"},
+		{"CHANGE_CUSTOM_HOST_VAR", []ArgType{HostName, VariableName, Text}, "[1600000000] CHANGE_CUSTOM_HOST_VAR;web01;_OWNER;admin"},
+		{"CHANGE_CUSTOM_SVC_VAR", []ArgType{HostName, ServiceDescription, VariableName, Text}, "[1600000000] CHANGE_CUSTOM_SVC_VAR;web01;HTTP;_OWNER;admin"},
+		{"CHANGE_CUSTOM_CONTACT_VAR", []ArgType{ContactName, VariableName, Text}, "[1600000000] CHANGE_CUSTOM_CONTACT_VAR;admin;_OWNER;admin"},
+		{"ENABLE_NOTIFICATIONS", []ArgType{}, "[1600000000] ENABLE_NOTIFICATIONS"},
+		{"ENABLE_EVENT_HANDLERS", []ArgType{}, "[1600000000] ENABLE_EVENT_HANDLERS"},
+		{"ENABLE_FLAP_DETECTION", []ArgType{}, "[1600000000] ENABLE_FLAP_DETECTION"},
+		{"ENABLE_FAILURE_PREDICTION", []ArgType{}, "[1600000000] ENABLE_FAILURE_PREDICTION"},
+		{"ENABLE_PERFORMANCE_DATA", []ArgType{}, "[1600000000] ENABLE_PERFORMANCE_DATA"},
+		{"ENABLE_HOST_FRESHNESS_CHECKS", []ArgType{}, "[1600000000] ENABLE_HOST_FRESHNESS_CHECKS"},
+		{"ENABLE_SERVICE_FRESHNESS_CHECKS", []ArgType{}, "[1600000000] ENABLE_SERVICE_FRESHNESS_CHECKS"},
+		{"DISABLE_NOTIFICATIONS", []ArgType{}, "[1600000000] DISABLE_NOTIFICATIONS"},
+		{"DISABLE_EVENT_HANDLERS", []ArgType{}, "[1600000000] DISABLE_EVENT_HANDLERS"},
+		{"DISABLE_FLAP_DETECTION", []ArgType{}, "[1600000000] DISABLE_FLAP_DETECTION"},
+		{"DISABLE_FAILURE_PREDICTION", []ArgType{}, "[1600000000] DISABLE_FAILURE_PREDICTION"},
+		{"DISABLE_PERFORMANCE_DATA", []ArgType{}, "[1600000000] DISABLE_PERFORMANCE_DATA"},
+		{"DISABLE_HOST_FRESHNESS_CHECKS", []ArgType{}, "[1600000000] DISABLE_HOST_FRESHNESS_CHECKS"},
+		{"DISABLE_SERVICE_FRESHNESS_CHECKS", []ArgType{}, "[1600000000] DISABLE_SERVICE_FRESHNESS_CHECKS"},
+		{"START_EXECUTING_HOST_CHECKS", []ArgType{}, "[1600000000] START_EXECUTING_HOST_CHECKS"},
+		{"START_EXECUTING_SVC_CHECKS", []ArgType{}, "[1600000000] START_EXECUTING_SVC_CHECKS"},
+		{"START_ACCEPTING_PASSIVE_HOST_CHECKS", []ArgType{}, "[1600000000] START_ACCEPTING_PASSIVE_HOST_CHECKS"},
+		{"START_ACCEPTING_PASSIVE_SVC_CHECKS", []ArgType{}, "[1600000000] START_ACCEPTING_PASSIVE_SVC_CHECKS"},
+		{"START_OBSESSING_OVER_HOST_CHECKS", []ArgType{}, "[1600000000] START_OBSESSING_OVER_HOST_CHECKS"},
+		{"START_OBSESSING_OVER_SVC_CHECKS", []ArgType{}, "[1600000000] START_OBSESSING_OVER_SVC_CHECKS"},
+		{"STOP_EXECUTING_HOST_CHECKS", []ArgType{}, "[1600000000] STOP_EXECUTING_HOST_CHECKS"},
+		{"STOP_EXECUTING_SVC_CHECKS", []ArgType{}, "[1600000000] STOP_EXECUTING_SVC_CHECKS"},
+		{"STOP_ACCEPTING_PASSIVE_HOST_CHECKS", []ArgType{}, "[1600000000] STOP_ACCEPTING_PASSIVE_HOST_CHECKS"},
+		{"STOP_ACCEPTING_PASSIVE_SVC_CHECKS", []ArgType{}, "[1600000000] STOP_ACCEPTING_PASSIVE_SVC_CHECKS"},
+		{"STOP_OBSESSING_OVER_HOST_CHECKS", []ArgType{}, "[1600000000] STOP_OBSESSING_OVER_HOST_CHECKS"},
+		{"STOP_OBSESSING_OVER_SVC_CHECKS", []ArgType{}, "[1600000000] STOP_OBSESSING_OVER_SVC_CHECKS"},
+		{"PROCESS_FILE", []ArgType{FileName, Bool}, "[1600000000] PROCESS_FILE;/tmp/commands;1"},
+		{"READ_STATE_INFORMATION", []ArgType{}, "[1600000000] READ_STATE_INFORMATION"},
+		{"SAVE_STATE_INFORMATION", []ArgType{}, "[1600000000] SAVE_STATE_INFORMATION"},
+		{"RESTART_PROGRAM", []ArgType{}, "[1600000000] RESTART_PROGRAM"},
+		{"SHUTDOWN_PROGRAM", []ArgType{}, "[1600000000] SHUTDOWN_PROGRAM"},
+	}
+
+	documented := make(map[string]bool, len(tests))
+	for _, test := range tests {
+		documented[test.name] = true
+
+		spec, ok := Lookup(test.name)
+		if !ok {
+			t.Errorf("%s: missing from catalogue", test.name)
+			continue
+		}
+
+		if len(spec.Args) != len(test.types) {
+			t.Errorf("%s: unexpected argument count, got: '%d', want: '%d'", test.name, len(spec.Args), len(test.types))
+			continue
+		}
+		for i, arg := range spec.Args {
+			if arg.Type != test.types[i] {
+				t.Errorf("%s: unexpected type for argument %d '%s', got: '%s', want: '%s'", test.name, i, arg.Name, arg.Type, test.types[i])
+			}
+		}
+
+		// the arguments are taken from the expected line. plugin output is
+		// always last, so may contain ';'.
+		var args []string
+		if len(test.types) > 0 {
+			args = strings.SplitN(strings.TrimPrefix(test.expected, "[1600000000] "+test.name+";"), ";", len(test.types))
+		}
+
+		c, err := Parse(test.name, args)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if s := c.At(time.Unix(1600000000, 0)).String(); s != test.expected {
+			t.Errorf("%s: unexpected command, got: '%s', want: '%s'", test.name, s, test.expected)
+		}
+	}
+
+	for name := range catalogue {
+		if !documented[name] {
+			t.Errorf("%s: not checked against the documented arguments", name)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+// AcknowledgeHostProblem acknowledges a host problem.
+func AcknowledgeHostProblem(host string, ackType xdata.AcknowledgementType, notify, persistent bool, author, comment string) (Command, error) {
+	return newCommand("ACKNOWLEDGE_HOST_PROBLEM", host, ackType, notify, persistent, author, comment)
+}
+
+// AcknowledgeHostProblemExpire acknowledges a host problem until the expire
+// time. It requires nagios 4.4 or newer.
+func AcknowledgeHostProblemExpire(host string, ackType xdata.AcknowledgementType, notify, persistent bool, expire time.Time, author, comment string) (Command, error) {
+	return newCommand("ACKNOWLEDGE_HOST_PROBLEM_EXPIRE", host, ackType, notify, persistent, expire, author, comment)
+}
+
+// AcknowledgeSvcProblem acknowledges a service problem.
+func AcknowledgeSvcProblem(host, service string, ackType xdata.AcknowledgementType, notify, persistent bool, author, comment string) (Command, error) {
+	return newCommand("ACKNOWLEDGE_SVC_PROBLEM", host, service, ackType, notify, persistent, author, comment)
+}
+
+// AcknowledgeSvcProblemExpire acknowledges a service problem until the expire
+// time. It requires nagios 4.4 or newer.
+func AcknowledgeSvcProblemExpire(host, service string, ackType xdata.AcknowledgementType, notify, persistent bool, expire time.Time, author, comment string) (Command, error) {
+	return newCommand("ACKNOWLEDGE_SVC_PROBLEM_EXPIRE", host, service, ackType, notify, persistent, expire, author, comment)
+}
+
+// RemoveHostAcknowledgement removes the acknowledgement of a host problem.
+func RemoveHostAcknowledgement(host string) (Command, error) {
+	return newCommand("REMOVE_HOST_ACKNOWLEDGEMENT", host)
+}
+
+// RemoveSvcAcknowledgement removes the acknowledgement of a service problem.
+func RemoveSvcAcknowledgement(host, service string) (Command, error) {
+	return newCommand("REMOVE_SVC_ACKNOWLEDGEMENT", host, service)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+// ChangeHostCheckCommand changes the check command of a host.
+func ChangeHostCheckCommand(host, command string) (Command, error) {
+	return newCommand("CHANGE_HOST_CHECK_COMMAND", host, command)
+}
+
+// ChangeSvcCheckCommand changes the check command of a service.
+func ChangeSvcCheckCommand(host, service, command string) (Command, error) {
+	return newCommand("CHANGE_SVC_CHECK_COMMAND", host, service, command)
+}
+
+// ChangeHostCheckTimeperiod changes the check timeperiod of a host.
+func ChangeHostCheckTimeperiod(host, timePeriod string) (Command, error) {
+	return newCommand("CHANGE_HOST_CHECK_TIMEPERIOD", host, timePeriod)
+}
+
+// ChangeSvcCheckTimeperiod changes the check timeperiod of a service.
+func ChangeSvcCheckTimeperiod(host, service, timePeriod string) (Command, error) {
+	return newCommand("CHANGE_SVC_CHECK_TIMEPERIOD", host, service, timePeriod)
+}
+
+// ChangeHostNotificationTimeperiod changes the notification timeperiod of a
+// host.
+func ChangeHostNotificationTimeperiod(host, timePeriod string) (Command, error) {
+	return newCommand("CHANGE_HOST_NOTIFICATION_TIMEPERIOD", host, timePeriod)
+}
+
+// ChangeSvcNotificationTimeperiod changes the notification timeperiod of a
+// service.
+func ChangeSvcNotificationTimeperiod(host, service, timePeriod string) (Command, error) {
+	return newCommand("CHANGE_SVC_NOTIFICATION_TIMEPERIOD", host, service, timePeriod)
+}
+
+// ChangeContactHostNotificationTimeperiod changes the host notification
+// timeperiod of a contact.
+func ChangeContactHostNotificationTimeperiod(contact, timePeriod string) (Command, error) {
+	return newCommand("CHANGE_CONTACT_HOST_NOTIFICATION_TIMEPERIOD", contact, timePeriod)
+}
+
+// ChangeContactSvcNotificationTimeperiod changes the service notification
+// timeperiod of a contact.
+func ChangeContactSvcNotificationTimeperiod(contact, timePeriod string) (Command, error) {
+	return newCommand("CHANGE_CONTACT_SVC_NOTIFICATION_TIMEPERIOD", contact, timePeriod)
+}
+
+// ChangeHostEventHandler changes the event handler of a host.
+func ChangeHostEventHandler(host, command string) (Command, error) {
+	return newCommand("CHANGE_HOST_EVENT_HANDLER", host, command)
+}
+
+// ChangeSvcEventHandler changes the event handler of a service.
+func ChangeSvcEventHandler(host, service, command string) (Command, error) {
+	return newCommand("CHANGE_SVC_EVENT_HANDLER", host, service, command)
+}
+
+// ChangeGlobalHostEventHandler changes the global host event handler.
+func ChangeGlobalHostEventHandler(command string) (Command, error) {
+	return newCommand("CHANGE_GLOBAL_HOST_EVENT_HANDLER", command)
+}
+
+// ChangeGlobalSvcEventHandler changes the global service event handler.
+func ChangeGlobalSvcEventHandler(command string) (Command, error) {
+	return newCommand("CHANGE_GLOBAL_SVC_EVENT_HANDLER", command)
+}
+
+// ChangeMaxHostCheckAttempts changes the maximum number of check attempts of
+// a host.
+func ChangeMaxHostCheckAttempts(host string, attempts int) (Command, error) {
+	return newCommand("CHANGE_MAX_HOST_CHECK_ATTEMPTS", host, attempts)
+}
+
+// ChangeMaxSvcCheckAttempts changes the maximum number of check attempts of a
+// service.
+func ChangeMaxSvcCheckAttempts(host, service string, attempts int) (Command, error) {
+	return newCommand("CHANGE_MAX_SVC_CHECK_ATTEMPTS", host, service, attempts)
+}
+
+// ChangeNormalHostCheckInterval changes the normal check interval of a host,
+// in minutes.
+func ChangeNormalHostCheckInterval(host string, interval float64) (Command, error) {
+	return newCommand("CHANGE_NORMAL_HOST_CHECK_INTERVAL", host, interval)
+}
+
+// ChangeNormalSvcCheckInterval changes the normal check interval of a
+// service, in minutes.
+func ChangeNormalSvcCheckInterval(host, service string, interval float64) (Command, error) {
+	return newCommand("CHANGE_NORMAL_SVC_CHECK_INTERVAL", host, service, interval)
+}
+
+// ChangeRetryHostCheckInterval changes the retry check interval of a host, in
+// minutes.
+func ChangeRetryHostCheckInterval(host string, interval float64) (Command, error) {
+	return newCommand("CHANGE_RETRY_HOST_CHECK_INTERVAL", host, interval)
+}
+
+// ChangeRetrySvcCheckInterval changes the retry check interval of a service,
+// in minutes.
+func ChangeRetrySvcCheckInterval(host, service string, interval float64) (Command, error) {
+	return newCommand("CHANGE_RETRY_SVC_CHECK_INTERVAL", host, service, interval)
+}
+
+// ChangeHostModattr sets the modified attributes of a host.
+func ChangeHostModattr(host string, value xdata.ModifiedAttribute) (Command, error) {
+	return newCommand("CHANGE_HOST_MODATTR", host, value)
+}
+
+// ChangeSvcModattr sets the modified attributes of a service.
+func ChangeSvcModattr(host, service string, value xdata.ModifiedAttribute) (Command, error) {
+	return newCommand("CHANGE_SVC_MODATTR", host, service, value)
+}
+
+// ChangeContactModattr sets the modified attributes of a contact.
+func ChangeContactModattr(contact string, value xdata.ModifiedAttribute) (Command, error) {
+	return newCommand("CHANGE_CONTACT_MODATTR", contact, value)
+}
+
+// ChangeContactModhattr sets the modified host attributes of a contact.
+func ChangeContactModhattr(contact string, value xdata.ModifiedAttribute) (Command, error) {
+	return newCommand("CHANGE_CONTACT_MODHATTR", contact, value)
+}
+
+// ChangeContactModsattr sets the modified service attributes of a contact.
+func ChangeContactModsattr(contact string, value xdata.ModifiedAttribute) (Command, error) {
+	return newCommand("CHANGE_CONTACT_MODSATTR", contact, value)
+}
+
+// ChangeCustomHostVar changes the value of a custom host variable.
+func ChangeCustomHostVar(host, name, value string) (Command, error) {
+	return newCommand("CHANGE_CUSTOM_HOST_VAR", host, name, value)
+}
+
+// ChangeCustomSvcVar changes the value of a custom service variable.
+func ChangeCustomSvcVar(host, service, name, value string) (Command, error) {
+	return newCommand("CHANGE_CUSTOM_SVC_VAR", host, service, name, value)
+}
+
+// ChangeCustomContactVar changes the value of a custom contact variable.
+func ChangeCustomContactVar(contact, name, value string) (Command, error) {
+	return newCommand("CHANGE_CUSTOM_CONTACT_VAR", contact, name, value)
+}
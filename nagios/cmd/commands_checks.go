@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+// ProcessHostCheckResult submits a passive host check result.
+func ProcessHostCheckResult(host string, status xdata.HostState, output string) (Command, error) {
+	return newCommand("PROCESS_HOST_CHECK_RESULT", host, status, output)
+}
+
+// ProcessServiceCheckResult submits a passive service check result.
+func ProcessServiceCheckResult(host, service string, status xdata.ServiceState, output string) (Command, error) {
+	return newCommand("PROCESS_SERVICE_CHECK_RESULT", host, service, status, output)
+}
+
+// ScheduleHostCheck schedules an active check of a host.
+func ScheduleHostCheck(host string, checkTime time.Time) (Command, error) {
+	return newCommand("SCHEDULE_HOST_CHECK", host, checkTime)
+}
+
+// ScheduleForcedHostCheck schedules an active check of a host, regardless of
+// its check settings.
+func ScheduleForcedHostCheck(host string, checkTime time.Time) (Command, error) {
+	return newCommand("SCHEDULE_FORCED_HOST_CHECK", host, checkTime)
+}
+
+// ScheduleHostSvcChecks schedules active checks of all services on a host.
+func ScheduleHostSvcChecks(host string, checkTime time.Time) (Command, error) {
+	return newCommand("SCHEDULE_HOST_SVC_CHECKS", host, checkTime)
+}
+
+// ScheduleForcedHostSvcChecks schedules active checks of all services on a
+// host, regardless of their check settings.
+func ScheduleForcedHostSvcChecks(host string, checkTime time.Time) (Command, error) {
+	return newCommand("SCHEDULE_FORCED_HOST_SVC_CHECKS", host, checkTime)
+}
+
+// ScheduleSvcCheck schedules an active check of a service.
+func ScheduleSvcCheck(host, service string, checkTime time.Time) (Command, error) {
+	return newCommand("SCHEDULE_SVC_CHECK", host, service, checkTime)
+}
+
+// ScheduleForcedSvcCheck schedules an active check of a service, regardless
+// of its check settings.
+func ScheduleForcedSvcCheck(host, service string, checkTime time.Time) (Command, error) {
+	return newCommand("SCHEDULE_FORCED_SVC_CHECK", host, service, checkTime)
+}
+
+// EnableHostCheck enables active checks of a host.
+func EnableHostCheck(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_CHECK", host)
+}
+
+// EnableSvcCheck enables active checks of a service.
+func EnableSvcCheck(host, service string) (Command, error) {
+	return newCommand("ENABLE_SVC_CHECK", host, service)
+}
+
+// EnableHostSvcChecks enables active checks of all services on a host.
+func EnableHostSvcChecks(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_SVC_CHECKS", host)
+}
+
+// EnablePassiveHostChecks enables passive checks of a host.
+func EnablePassiveHostChecks(host string) (Command, error) {
+	return newCommand("ENABLE_PASSIVE_HOST_CHECKS", host)
+}
+
+// EnablePassiveSvcChecks enables passive checks of a service.
+func EnablePassiveSvcChecks(host, service string) (Command, error) {
+	return newCommand("ENABLE_PASSIVE_SVC_CHECKS", host, service)
+}
+
+// EnableHostgroupHostChecks enables active checks of all hosts in a
+// hostgroup.
+func EnableHostgroupHostChecks(hostGroup string) (Command, error) {
+	return newCommand("ENABLE_HOSTGROUP_HOST_CHECKS", hostGroup)
+}
+
+// EnableHostgroupSvcChecks enables active checks of all services on hosts in
+// a hostgroup.
+func EnableHostgroupSvcChecks(hostGroup string) (Command, error) {
+	return newCommand("ENABLE_HOSTGROUP_SVC_CHECKS", hostGroup)
+}
+
+// EnableHostgroupPassiveHostChecks enables passive checks of all hosts in a
+// hostgroup.
+func EnableHostgroupPassiveHostChecks(hostGroup string) (Command, error) {
+	return newCommand("ENABLE_HOSTGROUP_PASSIVE_HOST_CHECKS", hostGroup)
+}
+
+// EnableHostgroupPassiveSvcChecks enables passive checks of all services on
+// hosts in a hostgroup.
+func EnableHostgroupPassiveSvcChecks(hostGroup string) (Command, error) {
+	return newCommand("ENABLE_HOSTGROUP_PASSIVE_SVC_CHECKS", hostGroup)
+}
+
+// EnableServicegroupHostChecks enables active checks of all hosts with
+// services in a servicegroup.
+func EnableServicegroupHostChecks(serviceGroup string) (Command, error) {
+	return newCommand("ENABLE_SERVICEGROUP_HOST_CHECKS", serviceGroup)
+}
+
+// EnableServicegroupSvcChecks enables active checks of all services in a
+// servicegroup.
+func EnableServicegroupSvcChecks(serviceGroup string) (Command, error) {
+	return newCommand("ENABLE_SERVICEGROUP_SVC_CHECKS", serviceGroup)
+}
+
+// EnableServicegroupPassiveHostChecks enables passive checks of all hosts
+// with services in a servicegroup.
+func EnableServicegroupPassiveHostChecks(serviceGroup string) (Command, error) {
+	return newCommand("ENABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS", serviceGroup)
+}
+
+// EnableServicegroupPassiveSvcChecks enables passive checks of all services
+// in a servicegroup.
+func EnableServicegroupPassiveSvcChecks(serviceGroup string) (Command, error) {
+	return newCommand("ENABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS", serviceGroup)
+}
+
+// EnableHostEventHandler enables the event handler of a host.
+func EnableHostEventHandler(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_EVENT_HANDLER", host)
+}
+
+// EnableSvcEventHandler enables the event handler of a service.
+func EnableSvcEventHandler(host, service string) (Command, error) {
+	return newCommand("ENABLE_SVC_EVENT_HANDLER", host, service)
+}
+
+// EnableHostFlapDetection enables flap detection for a host.
+func EnableHostFlapDetection(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_FLAP_DETECTION", host)
+}
+
+// EnableSvcFlapDetection enables flap detection for a service.
+func EnableSvcFlapDetection(host, service string) (Command, error) {
+	return newCommand("ENABLE_SVC_FLAP_DETECTION", host, service)
+}
+
+// DisableHostCheck disables active checks of a host.
+func DisableHostCheck(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_CHECK", host)
+}
+
+// DisableSvcCheck disables active checks of a service.
+func DisableSvcCheck(host, service string) (Command, error) {
+	return newCommand("DISABLE_SVC_CHECK", host, service)
+}
+
+// DisableHostSvcChecks disables active checks of all services on a host.
+func DisableHostSvcChecks(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_SVC_CHECKS", host)
+}
+
+// DisablePassiveHostChecks disables passive checks of a host.
+func DisablePassiveHostChecks(host string) (Command, error) {
+	return newCommand("DISABLE_PASSIVE_HOST_CHECKS", host)
+}
+
+// DisablePassiveSvcChecks disables passive checks of a service.
+func DisablePassiveSvcChecks(host, service string) (Command, error) {
+	return newCommand("DISABLE_PASSIVE_SVC_CHECKS", host, service)
+}
+
+// DisableHostgroupHostChecks disables active checks of all hosts in a
+// hostgroup.
+func DisableHostgroupHostChecks(hostGroup string) (Command, error) {
+	return newCommand("DISABLE_HOSTGROUP_HOST_CHECKS", hostGroup)
+}
+
+// DisableHostgroupSvcChecks disables active checks of all services on hosts
+// in a hostgroup.
+func DisableHostgroupSvcChecks(hostGroup string) (Command, error) {
+	return newCommand("DISABLE_HOSTGROUP_SVC_CHECKS", hostGroup)
+}
+
+// DisableHostgroupPassiveHostChecks disables passive checks of all hosts in a
+// hostgroup.
+func DisableHostgroupPassiveHostChecks(hostGroup string) (Command, error) {
+	return newCommand("DISABLE_HOSTGROUP_PASSIVE_HOST_CHECKS", hostGroup)
+}
+
+// DisableHostgroupPassiveSvcChecks disables passive checks of all services on
+// hosts in a hostgroup.
+func DisableHostgroupPassiveSvcChecks(hostGroup string) (Command, error) {
+	return newCommand("DISABLE_HOSTGROUP_PASSIVE_SVC_CHECKS", hostGroup)
+}
+
+// DisableServicegroupHostChecks disables active checks of all hosts with
+// services in a servicegroup.
+func DisableServicegroupHostChecks(serviceGroup string) (Command, error) {
+	return newCommand("DISABLE_SERVICEGROUP_HOST_CHECKS", serviceGroup)
+}
+
+// DisableServicegroupSvcChecks disables active checks of all services in a
+// servicegroup.
+func DisableServicegroupSvcChecks(serviceGroup string) (Command, error) {
+	return newCommand("DISABLE_SERVICEGROUP_SVC_CHECKS", serviceGroup)
+}
+
+// DisableServicegroupPassiveHostChecks disables passive checks of all hosts
+// with services in a servicegroup.
+func DisableServicegroupPassiveHostChecks(serviceGroup string) (Command, error) {
+	return newCommand("DISABLE_SERVICEGROUP_PASSIVE_HOST_CHECKS", serviceGroup)
+}
+
+// DisableServicegroupPassiveSvcChecks disables passive checks of all services
+// in a servicegroup.
+func DisableServicegroupPassiveSvcChecks(serviceGroup string) (Command, error) {
+	return newCommand("DISABLE_SERVICEGROUP_PASSIVE_SVC_CHECKS", serviceGroup)
+}
+
+// DisableHostEventHandler disables the event handler of a host.
+func DisableHostEventHandler(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_EVENT_HANDLER", host)
+}
+
+// DisableSvcEventHandler disables the event handler of a service.
+func DisableSvcEventHandler(host, service string) (Command, error) {
+	return newCommand("DISABLE_SVC_EVENT_HANDLER", host, service)
+}
+
+// DisableHostFlapDetection disables flap detection for a host.
+func DisableHostFlapDetection(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_FLAP_DETECTION", host)
+}
+
+// DisableSvcFlapDetection disables flap detection for a service.
+func DisableSvcFlapDetection(host, service string) (Command, error) {
+	return newCommand("DISABLE_SVC_FLAP_DETECTION", host, service)
+}
+
+// StartObsessingOverHost starts obsessing over checks of a host.
+func StartObsessingOverHost(host string) (Command, error) {
+	return newCommand("START_OBSESSING_OVER_HOST", host)
+}
+
+// StartObsessingOverSvc starts obsessing over checks of a service.
+func StartObsessingOverSvc(host, service string) (Command, error) {
+	return newCommand("START_OBSESSING_OVER_SVC", host, service)
+}
+
+// StopObsessingOverHost stops obsessing over checks of a host.
+func StopObsessingOverHost(host string) (Command, error) {
+	return newCommand("STOP_OBSESSING_OVER_HOST", host)
+}
+
+// StopObsessingOverSvc stops obsessing over checks of a service.
+func StopObsessingOverSvc(host, service string) (Command, error) {
+	return newCommand("STOP_OBSESSING_OVER_SVC", host, service)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+// AddHostComment adds a comment to a host.
+func AddHostComment(host string, persistent bool, author, comment string) (Command, error) {
+	return newCommand("ADD_HOST_COMMENT", host, persistent, author, comment)
+}
+
+// AddSvcComment adds a comment to a service.
+func AddSvcComment(host, service string, persistent bool, author, comment string) (Command, error) {
+	return newCommand("ADD_SVC_COMMENT", host, service, persistent, author, comment)
+}
+
+// DelHostComment deletes a host comment.
+func DelHostComment(commentID int) (Command, error) {
+	return newCommand("DEL_HOST_COMMENT", commentID)
+}
+
+// DelSvcComment deletes a service comment.
+func DelSvcComment(commentID int) (Command, error) {
+	return newCommand("DEL_SVC_COMMENT", commentID)
+}
+
+// DelAllHostComments deletes all comments on a host.
+func DelAllHostComments(host string) (Command, error) {
+	return newCommand("DEL_ALL_HOST_COMMENTS", host)
+}
+
+// DelAllSvcComments deletes all comments on a service.
+func DelAllSvcComments(host, service string) (Command, error) {
+	return newCommand("DEL_ALL_SVC_COMMENTS", host, service)
+}
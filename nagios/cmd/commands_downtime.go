@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"time"
+)
+
+// ScheduleHostDowntime schedules downtime for a host.
+func ScheduleHostDowntime(host string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_HOST_DOWNTIME", host, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleSvcDowntime schedules downtime for a service.
+func ScheduleSvcDowntime(host, service string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_SVC_DOWNTIME", host, service, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleHostSvcDowntime schedules downtime for all services on a host.
+func ScheduleHostSvcDowntime(host string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_HOST_SVC_DOWNTIME", host, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleAndPropagateHostDowntime schedules downtime for a host and all of
+// its children.
+func ScheduleAndPropagateHostDowntime(host string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_AND_PROPAGATE_HOST_DOWNTIME", host, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleAndPropagateTriggeredHostDowntime schedules downtime for a host,
+// and downtime for all of its children which is triggered by it.
+func ScheduleAndPropagateTriggeredHostDowntime(host string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_AND_PROPAGATE_TRIGGERED_HOST_DOWNTIME", host, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleHostgroupHostDowntime schedules downtime for all hosts in a
+// hostgroup.
+func ScheduleHostgroupHostDowntime(hostGroup string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_HOSTGROUP_HOST_DOWNTIME", hostGroup, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleHostgroupSvcDowntime schedules downtime for all services on hosts
+// in a hostgroup.
+func ScheduleHostgroupSvcDowntime(hostGroup string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_HOSTGROUP_SVC_DOWNTIME", hostGroup, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleServicegroupHostDowntime schedules downtime for all hosts with
+// services in a servicegroup.
+func ScheduleServicegroupHostDowntime(serviceGroup string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_SERVICEGROUP_HOST_DOWNTIME", serviceGroup, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// ScheduleServicegroupSvcDowntime schedules downtime for all services in a
+// servicegroup.
+func ScheduleServicegroupSvcDowntime(serviceGroup string, start, end time.Time, fixed bool, triggerID int, duration time.Duration, author, comment string) (Command, error) {
+	return newCommand("SCHEDULE_SERVICEGROUP_SVC_DOWNTIME", serviceGroup, start, end, fixed, triggerID, duration, author, comment)
+}
+
+// DelHostDowntime cancels scheduled host downtime.
+func DelHostDowntime(downtimeID int) (Command, error) {
+	return newCommand("DEL_HOST_DOWNTIME", downtimeID)
+}
+
+// DelSvcDowntime cancels scheduled service downtime.
+func DelSvcDowntime(downtimeID int) (Command, error) {
+	return newCommand("DEL_SVC_DOWNTIME", downtimeID)
+}
+
+// DelDowntimeByHostName cancels scheduled downtime for a host. The optional
+// arguments narrow the downtime which is cancelled, and are ignored when
+// empty.
+func DelDowntimeByHostName(host, service string, start time.Time, comment string) (Command, error) {
+	return newCommand("DEL_DOWNTIME_BY_HOST_NAME", host, service, start, comment)
+}
+
+// DelDowntimeByHostgroupName cancels scheduled downtime for hosts in a
+// hostgroup. The optional arguments narrow the downtime which is cancelled,
+// and are ignored when empty.
+func DelDowntimeByHostgroupName(hostGroup, host, service string, start time.Time, comment string) (Command, error) {
+	return newCommand("DEL_DOWNTIME_BY_HOSTGROUP_NAME", hostGroup, host, service, start, comment)
+}
+
+// DelDowntimeByStartTimeComment cancels scheduled downtime matching a start
+// time and comment. Empty arguments are ignored, but at least one should be
+// set.
+func DelDowntimeByStartTimeComment(start time.Time, comment string) (Command, error) {
+	return newCommand("DEL_DOWNTIME_BY_START_TIME_COMMENT", start, comment)
+}
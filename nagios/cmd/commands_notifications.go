@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"time"
+)
+
+// EnableHostNotifications enables notifications for a host.
+func EnableHostNotifications(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_NOTIFICATIONS", host)
+}
+
+// EnableSvcNotifications enables notifications for a service.
+func EnableSvcNotifications(host, service string) (Command, error) {
+	return newCommand("ENABLE_SVC_NOTIFICATIONS", host, service)
+}
+
+// EnableHostSvcNotifications enables notifications for all services on a
+// host.
+func EnableHostSvcNotifications(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_SVC_NOTIFICATIONS", host)
+}
+
+// EnableHostAndChildNotifications enables notifications for a host and all of
+// its children.
+func EnableHostAndChildNotifications(host string) (Command, error) {
+	return newCommand("ENABLE_HOST_AND_CHILD_NOTIFICATIONS", host)
+}
+
+// EnableAllNotificationsBeyondHost enables notifications for all hosts and
+// services beyond a host, but not the host itself.
+func EnableAllNotificationsBeyondHost(host string) (Command, error) {
+	return newCommand("ENABLE_ALL_NOTIFICATIONS_BEYOND_HOST", host)
+}
+
+// EnableHostgroupHostNotifications enables notifications for all hosts in a
+// hostgroup.
+func EnableHostgroupHostNotifications(hostGroup string) (Command, error) {
+	return newCommand("ENABLE_HOSTGROUP_HOST_NOTIFICATIONS", hostGroup)
+}
+
+// EnableHostgroupSvcNotifications enables notifications for all services on
+// hosts in a hostgroup.
+func EnableHostgroupSvcNotifications(hostGroup string) (Command, error) {
+	return newCommand("ENABLE_HOSTGROUP_SVC_NOTIFICATIONS", hostGroup)
+}
+
+// EnableServicegroupHostNotifications enables notifications for all hosts
+// with services in a servicegroup.
+func EnableServicegroupHostNotifications(serviceGroup string) (Command, error) {
+	return newCommand("ENABLE_SERVICEGROUP_HOST_NOTIFICATIONS", serviceGroup)
+}
+
+// EnableServicegroupSvcNotifications enables notifications for all services
+// in a servicegroup.
+func EnableServicegroupSvcNotifications(serviceGroup string) (Command, error) {
+	return newCommand("ENABLE_SERVICEGROUP_SVC_NOTIFICATIONS", serviceGroup)
+}
+
+// EnableContactHostNotifications enables host notifications for a contact.
+func EnableContactHostNotifications(contact string) (Command, error) {
+	return newCommand("ENABLE_CONTACT_HOST_NOTIFICATIONS", contact)
+}
+
+// EnableContactSvcNotifications enables service notifications for a contact.
+func EnableContactSvcNotifications(contact string) (Command, error) {
+	return newCommand("ENABLE_CONTACT_SVC_NOTIFICATIONS", contact)
+}
+
+// EnableContactgroupHostNotifications enables host notifications for all
+// contacts in a contactgroup.
+func EnableContactgroupHostNotifications(contactGroup string) (Command, error) {
+	return newCommand("ENABLE_CONTACTGROUP_HOST_NOTIFICATIONS", contactGroup)
+}
+
+// EnableContactgroupSvcNotifications enables service notifications for all
+// contacts in a contactgroup.
+func EnableContactgroupSvcNotifications(contactGroup string) (Command, error) {
+	return newCommand("ENABLE_CONTACTGROUP_SVC_NOTIFICATIONS", contactGroup)
+}
+
+// DisableHostNotifications disables notifications for a host.
+func DisableHostNotifications(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_NOTIFICATIONS", host)
+}
+
+// DisableSvcNotifications disables notifications for a service.
+func DisableSvcNotifications(host, service string) (Command, error) {
+	return newCommand("DISABLE_SVC_NOTIFICATIONS", host, service)
+}
+
+// DisableHostSvcNotifications disables notifications for all services on a
+// host.
+func DisableHostSvcNotifications(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_SVC_NOTIFICATIONS", host)
+}
+
+// DisableHostAndChildNotifications disables notifications for a host and all
+// of its children.
+func DisableHostAndChildNotifications(host string) (Command, error) {
+	return newCommand("DISABLE_HOST_AND_CHILD_NOTIFICATIONS", host)
+}
+
+// DisableAllNotificationsBeyondHost disables notifications for all hosts and
+// services beyond a host, but not the host itself.
+func DisableAllNotificationsBeyondHost(host string) (Command, error) {
+	return newCommand("DISABLE_ALL_NOTIFICATIONS_BEYOND_HOST", host)
+}
+
+// DisableHostgroupHostNotifications disables notifications for all hosts in a
+// hostgroup.
+func DisableHostgroupHostNotifications(hostGroup string) (Command, error) {
+	return newCommand("DISABLE_HOSTGROUP_HOST_NOTIFICATIONS", hostGroup)
+}
+
+// DisableHostgroupSvcNotifications disables notifications for all services on
+// hosts in a hostgroup.
+func DisableHostgroupSvcNotifications(hostGroup string) (Command, error) {
+	return newCommand("DISABLE_HOSTGROUP_SVC_NOTIFICATIONS", hostGroup)
+}
+
+// DisableServicegroupHostNotifications disables notifications for all hosts
+// with services in a servicegroup.
+func DisableServicegroupHostNotifications(serviceGroup string) (Command, error) {
+	return newCommand("DISABLE_SERVICEGROUP_HOST_NOTIFICATIONS", serviceGroup)
+}
+
+// DisableServicegroupSvcNotifications disables notifications for all services
+// in a servicegroup.
+func DisableServicegroupSvcNotifications(serviceGroup string) (Command, error) {
+	return newCommand("DISABLE_SERVICEGROUP_SVC_NOTIFICATIONS", serviceGroup)
+}
+
+// DisableContactHostNotifications disables host notifications for a contact.
+func DisableContactHostNotifications(contact string) (Command, error) {
+	return newCommand("DISABLE_CONTACT_HOST_NOTIFICATIONS", contact)
+}
+
+// DisableContactSvcNotifications disables service notifications for a
+// contact.
+func DisableContactSvcNotifications(contact string) (Command, error) {
+	return newCommand("DISABLE_CONTACT_SVC_NOTIFICATIONS", contact)
+}
+
+// DisableContactgroupHostNotifications disables host notifications for all
+// contacts in a contactgroup.
+func DisableContactgroupHostNotifications(contactGroup string) (Command, error) {
+	return newCommand("DISABLE_CONTACTGROUP_HOST_NOTIFICATIONS", contactGroup)
+}
+
+// DisableContactgroupSvcNotifications disables service notifications for all
+// contacts in a contactgroup.
+func DisableContactgroupSvcNotifications(contactGroup string) (Command, error) {
+	return newCommand("DISABLE_CONTACTGROUP_SVC_NOTIFICATIONS", contactGroup)
+}
+
+// DelayHostNotification delays the next notification for a host until the
+// given time.
+func DelayHostNotification(host string, notificationTime time.Time) (Command, error) {
+	return newCommand("DELAY_HOST_NOTIFICATION", host, notificationTime)
+}
+
+// DelaySvcNotification delays the next notification for a service until the
+// given time.
+func DelaySvcNotification(host, service string, notificationTime time.Time) (Command, error) {
+	return newCommand("DELAY_SVC_NOTIFICATION", host, service, notificationTime)
+}
+
+// SendCustomHostNotification sends a custom notification for a host. Options
+// is a bitmask, where 1 broadcasts to all contacts, 2 forces the notification
+// and 4 increments the notification number.
+func SendCustomHostNotification(host string, options int, author, comment string) (Command, error) {
+	return newCommand("SEND_CUSTOM_HOST_NOTIFICATION", host, options, author, comment)
+}
+
+// SendCustomSvcNotification sends a custom notification for a service.
+// Options is a bitmask, where 1 broadcasts to all contacts, 2 forces the
+// notification and 4 increments the notification number.
+func SendCustomSvcNotification(host, service string, options int, author, comment string) (Command, error) {
+	return newCommand("SEND_CUSTOM_SVC_NOTIFICATION", host, service, options, author, comment)
+}
+
+// SetHostNotificationNumber sets the current notification number of a host.
+func SetHostNotificationNumber(host string, number int) (Command, error) {
+	return newCommand("SET_HOST_NOTIFICATION_NUMBER", host, number)
+}
+
+// SetSvcNotificationNumber sets the current notification number of a service.
+func SetSvcNotificationNumber(host, service string, number int) (Command, error) {
+	return newCommand("SET_SVC_NOTIFICATION_NUMBER", host, service, number)
+}
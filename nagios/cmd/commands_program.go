@@ -0,0 +1,159 @@
+package cmd
+
+// EnableNotifications enables notifications globally.
+func EnableNotifications() Command {
+	return Command{Name: "ENABLE_NOTIFICATIONS"}
+}
+
+// EnableEventHandlers enables event handlers globally.
+func EnableEventHandlers() Command {
+	return Command{Name: "ENABLE_EVENT_HANDLERS"}
+}
+
+// EnableFlapDetection enables flap detection globally.
+func EnableFlapDetection() Command {
+	return Command{Name: "ENABLE_FLAP_DETECTION"}
+}
+
+// EnableFailurePrediction enables failure prediction globally. It is ignored
+// by nagios 4.
+func EnableFailurePrediction() Command {
+	return Command{Name: "ENABLE_FAILURE_PREDICTION"}
+}
+
+// EnablePerformanceData enables processing of performance data globally.
+func EnablePerformanceData() Command {
+	return Command{Name: "ENABLE_PERFORMANCE_DATA"}
+}
+
+// EnableHostFreshnessChecks enables freshness checks of all hosts.
+func EnableHostFreshnessChecks() Command {
+	return Command{Name: "ENABLE_HOST_FRESHNESS_CHECKS"}
+}
+
+// EnableServiceFreshnessChecks enables freshness checks of all services.
+func EnableServiceFreshnessChecks() Command {
+	return Command{Name: "ENABLE_SERVICE_FRESHNESS_CHECKS"}
+}
+
+// DisableNotifications disables notifications globally.
+func DisableNotifications() Command {
+	return Command{Name: "DISABLE_NOTIFICATIONS"}
+}
+
+// DisableEventHandlers disables event handlers globally.
+func DisableEventHandlers() Command {
+	return Command{Name: "DISABLE_EVENT_HANDLERS"}
+}
+
+// DisableFlapDetection disables flap detection globally.
+func DisableFlapDetection() Command {
+	return Command{Name: "DISABLE_FLAP_DETECTION"}
+}
+
+// DisableFailurePrediction disables failure prediction globally. It is
+// ignored by nagios 4.
+func DisableFailurePrediction() Command {
+	return Command{Name: "DISABLE_FAILURE_PREDICTION"}
+}
+
+// DisablePerformanceData disables processing of performance data globally.
+func DisablePerformanceData() Command {
+	return Command{Name: "DISABLE_PERFORMANCE_DATA"}
+}
+
+// DisableHostFreshnessChecks disables freshness checks of all hosts.
+func DisableHostFreshnessChecks() Command {
+	return Command{Name: "DISABLE_HOST_FRESHNESS_CHECKS"}
+}
+
+// DisableServiceFreshnessChecks disables freshness checks of all services.
+func DisableServiceFreshnessChecks() Command {
+	return Command{Name: "DISABLE_SERVICE_FRESHNESS_CHECKS"}
+}
+
+// StartExecutingHostChecks starts executing active host checks.
+func StartExecutingHostChecks() Command {
+	return Command{Name: "START_EXECUTING_HOST_CHECKS"}
+}
+
+// StartExecutingSvcChecks starts executing active service checks.
+func StartExecutingSvcChecks() Command {
+	return Command{Name: "START_EXECUTING_SVC_CHECKS"}
+}
+
+// StartAcceptingPassiveHostChecks starts accepting passive host checks.
+func StartAcceptingPassiveHostChecks() Command {
+	return Command{Name: "START_ACCEPTING_PASSIVE_HOST_CHECKS"}
+}
+
+// StartAcceptingPassiveSvcChecks starts accepting passive service checks.
+func StartAcceptingPassiveSvcChecks() Command {
+	return Command{Name: "START_ACCEPTING_PASSIVE_SVC_CHECKS"}
+}
+
+// StartObsessingOverHostChecks starts obsessing over host checks.
+func StartObsessingOverHostChecks() Command {
+	return Command{Name: "START_OBSESSING_OVER_HOST_CHECKS"}
+}
+
+// StartObsessingOverSvcChecks starts obsessing over service checks.
+func StartObsessingOverSvcChecks() Command {
+	return Command{Name: "START_OBSESSING_OVER_SVC_CHECKS"}
+}
+
+// StopExecutingHostChecks stops executing active host checks.
+func StopExecutingHostChecks() Command {
+	return Command{Name: "STOP_EXECUTING_HOST_CHECKS"}
+}
+
+// StopExecutingSvcChecks stops executing active service checks.
+func StopExecutingSvcChecks() Command {
+	return Command{Name: "STOP_EXECUTING_SVC_CHECKS"}
+}
+
+// StopAcceptingPassiveHostChecks stops accepting passive host checks.
+func StopAcceptingPassiveHostChecks() Command {
+	return Command{Name: "STOP_ACCEPTING_PASSIVE_HOST_CHECKS"}
+}
+
+// StopAcceptingPassiveSvcChecks stops accepting passive service checks.
+func StopAcceptingPassiveSvcChecks() Command {
+	return Command{Name: "STOP_ACCEPTING_PASSIVE_SVC_CHECKS"}
+}
+
+// StopObsessingOverHostChecks stops obsessing over host checks.
+func StopObsessingOverHostChecks() Command {
+	return Command{Name: "STOP_OBSESSING_OVER_HOST_CHECKS"}
+}
+
+// StopObsessingOverSvcChecks stops obsessing over service checks.
+func StopObsessingOverSvcChecks() Command {
+	return Command{Name: "STOP_OBSESSING_OVER_SVC_CHECKS"}
+}
+
+// ProcessFile processes the external commands in a file, which is deleted
+// afterwards if remove is true.
+func ProcessFile(filename string, remove bool) (Command, error) {
+	return newCommand("PROCESS_FILE", filename, remove)
+}
+
+// ReadStateInformation loads the retention file.
+func ReadStateInformation() Command {
+	return Command{Name: "READ_STATE_INFORMATION"}
+}
+
+// SaveStateInformation writes the retention file.
+func SaveStateInformation() Command {
+	return Command{Name: "SAVE_STATE_INFORMATION"}
+}
+
+// RestartProgram restarts nagios.
+func RestartProgram() Command {
+	return Command{Name: "RESTART_PROGRAM"}
+}
+
+// ShutdownProgram shuts down nagios.
+func ShutdownProgram() Command {
+	return Command{Name: "SHUTDOWN_PROGRAM"}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDeadLetterSize is the number of dead letters kept by the default
+// DeadLetterSink.
+const DefaultDeadLetterSize = 100
+
+// DeadLetter is a command which the Writer gave up on.
+type DeadLetter struct {
+	Target   string
+	Command  string
+	Reason   string
+	Attempts int
+	Queued   time.Time
+	Failed   time.Time
+}
+
+// DeadLetterSink receives commands which the Writer gave up on.
+type DeadLetterSink interface {
+	Add(d DeadLetter)
+	List() []DeadLetter
+}
+
+// DeadLetterBuffer is a DeadLetterSink which keeps the most recent dead
+// letters in memory.
+type DeadLetterBuffer struct {
+	mu      sync.Mutex
+	size    int
+	letters []DeadLetter
+}
+
+// NewDeadLetterBuffer constructs a DeadLetterBuffer holding up to size dead
+// letters.
+func NewDeadLetterBuffer(size int) *DeadLetterBuffer {
+	return &DeadLetterBuffer{
+		size: size,
+	}
+}
+
+// Add stores a dead letter, discarding the oldest if the buffer is full.
+func (b *DeadLetterBuffer) Add(d DeadLetter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.size <= 0 {
+		return
+	}
+
+	if len(b.letters) >= b.size {
+		copy(b.letters, b.letters[1:])
+		b.letters = b.letters[:len(b.letters)-1]
+	}
+	b.letters = append(b.letters, d)
+}
+
+// List returns the stored dead letters, oldest first.
+func (b *DeadLetterBuffer) List() []DeadLetter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	res := make([]DeadLetter, len(b.letters))
+	copy(res, b.letters)
+	return res
+}
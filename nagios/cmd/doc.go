@@ -1,2 +1,6 @@
 // Cmd provides routines for writing to the nagios external commands file.
+//
+// Commands should be built with the typed constructors, such as
+// ProcessServiceCheckResult, which validate and sanitize their arguments
+// against the catalogue of nagios external commands.
 package cmd
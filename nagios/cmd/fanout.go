@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrDeliveryFailed is returned when a command was not delivered to enough
+// targets to satisfy the delivery policy.
+var ErrDeliveryFailed = errors.New("delivery failed")
+
+// DeliveryPolicy decides how many targets must accept a command for a write
+// to a FanOut to succeed.
+type DeliveryPolicy int
+
+const (
+	// DeliverAll requires every target to accept the command. When only
+	// some of them do, the write fails even though those targets will still
+	// write the command, so a client retrying it duplicates the command on
+	// them.
+	DeliverAll DeliveryPolicy = iota
+
+	// DeliverAny requires at least one target to accept the command.
+	DeliverAny
+
+	// DeliverPrimary requires the first target, the primary, to accept the
+	// command. Failures of the other targets are only reported in the
+	// deliveries, so a retry does not duplicate the command on the primary.
+	DeliverPrimary
+)
+
+// ParseDeliveryPolicy converts the name of a policy, as used in the config
+// file, into a DeliveryPolicy.
+func ParseDeliveryPolicy(s string) (DeliveryPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "all":
+		return DeliverAll, nil
+	case "any":
+		return DeliverAny, nil
+	case "primary":
+		return DeliverPrimary, nil
+	default:
+		return DeliverAll, fmt.Errorf("unknown delivery policy '%s'", s)
+	}
+}
+
+// String returns a string representation of the DeliveryPolicy.
+//
+// An empty string is returned for unknown policies.
+func (p DeliveryPolicy) String() string {
+	switch p {
+	case DeliverAll:
+		return "all"
+	case DeliverAny:
+		return "any"
+	case DeliverPrimary:
+		return "primary"
+	default:
+		return ""
+	}
+}
+
+// Target is a named destination for commands, usually a Writer for the
+// external commands file of one nagios instance.
+type Target struct {
+	Name   string
+	Writer io.Writer
+}
+
+// Delivery is the result of writing a command to a single target.
+type Delivery struct {
+	Target string
+
+	// Err is nil if the target accepted the command.
+	Err error
+
+	// Healthy is false if the target is currently failing to write commands
+	// to nagios, in which case accepted commands are queued.
+	Healthy bool
+}
+
+// DeliveryError is returned when the deliveries do not satisfy the delivery
+// policy.
+type DeliveryError struct {
+	Deliveries []Delivery
+}
+
+func (e *DeliveryError) Error() string {
+	failed := make([]string, 0, len(e.Deliveries))
+	for _, d := range e.Deliveries {
+		if d.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", d.Target, d.Err))
+		}
+	}
+	return fmt.Sprintf("%s: %s", ErrDeliveryFailed, strings.Join(failed, ", "))
+}
+
+// Is matches ErrDeliveryFailed, and the errors of the failed deliveries.
+func (e *DeliveryError) Is(target error) bool {
+	if target == ErrDeliveryFailed {
+		return true
+	}
+
+	for _, d := range e.Deliveries {
+		if d.Err != nil && errors.Is(d.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FanOut writes each command to several targets, such as an active and a
+// standby nagios instance.
+//
+// Targets are written to concurrently. They should not block when they are
+// unable to deliver commands, otherwise a failed target holds up the others;
+// Writer targets should use the OverflowReject or OverflowDropOldest
+// policies.
+//
+// FanOut implements io.Writer.
+type FanOut struct {
+	policy  DeliveryPolicy
+	targets []Target
+}
+
+// NewFanOut constructs an instance of FanOut.
+func NewFanOut(policy DeliveryPolicy, targets ...Target) (*FanOut, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("must set at least one target")
+	}
+
+	names := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		if t.Writer == nil {
+			return nil, fmt.Errorf("target '%s' has no writer", t.Name)
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicate target '%s'", t.Name)
+		}
+		names[t.Name] = true
+	}
+
+	return &FanOut{
+		policy:  policy,
+		targets: targets,
+	}, nil
+}
+
+// Targets returns the targets of the FanOut.
+func (f *FanOut) Targets() []Target {
+	res := make([]Target, len(f.targets))
+	copy(res, f.targets)
+	return res
+}
+
+// Write writes a command to every target.
+//
+// If the delivery policy is not satisfied, a DeliveryError is returned, or
+// the error of the target if there is only one.
+func (f *FanOut) Write(cmd []byte) (int, error) {
+	if _, err := f.Deliver(cmd); err != nil {
+		return 0, err
+	}
+	return len(cmd), nil
+}
+
+// Deliver writes a command to every target, and returns the result for each
+// target in the order they were given to NewFanOut.
+//
+// Deliveries can not be undone, so when the policy is not satisfied, some
+// targets may have accepted the command anyway.
+func (f *FanOut) Deliver(cmd []byte) ([]Delivery, error) {
+	deliveries := make([]Delivery, len(f.targets))
+
+	var wg sync.WaitGroup
+	for i, t := range f.targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+
+			_, err := t.Writer.Write(cmd)
+			deliveries[i] = Delivery{
+				Target:  t.Name,
+				Err:     err,
+				Healthy: healthy(t.Writer),
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, d := range deliveries {
+		if d.Err == nil {
+			accepted++
+		}
+	}
+
+	if (f.policy == DeliverAll && accepted == len(deliveries)) ||
+		(f.policy == DeliverAny && accepted > 0) ||
+		(f.policy == DeliverPrimary && deliveries[0].Err == nil) {
+		return deliveries, nil
+	}
+
+	if len(deliveries) == 1 {
+		return deliveries, deliveries[0].Err
+	}
+
+	return deliveries, &DeliveryError{
+		Deliveries: deliveries,
+	}
+}
+
+// Run runs every target which needs to be run, such as a Writer, until they
+// have all returned. It should be run as a goroutine.
+func (f *FanOut) Run(ctx context.Context) error {
+	errs := make(chan error, len(f.targets))
+
+	var wg sync.WaitGroup
+	for _, t := range f.targets {
+		r, ok := t.Writer.(interface {
+			Run(ctx context.Context) error
+		})
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- r.Run(ctx)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes every target which needs to be closed, such as a Writer,
+// waiting for them to drain until ctx is done.
+func (f *FanOut) Close(ctx context.Context) error {
+	errs := make(chan error, len(f.targets))
+
+	var wg sync.WaitGroup
+	for _, t := range f.targets {
+		c, ok := t.Writer.(interface {
+			Close(ctx context.Context) error
+		})
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := c.Close(ctx); err != nil {
+				errs <- fmt.Errorf("%s: %w", name, err)
+			}
+		}(t.Name)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		return err
+	}
+	return nil
+}
+
+// DeadLetters returns the commands which the targets gave up on.
+func (f *FanOut) DeadLetters() []DeadLetter {
+	res := make([]DeadLetter, 0)
+	for _, t := range f.targets {
+		if d, ok := t.Writer.(interface {
+			DeadLetters() []DeadLetter
+		}); ok {
+			res = append(res, d.DeadLetters()...)
+		}
+	}
+	return res
+}
+
+// Stats returns the stats of each target which reports them, such as a
+// Writer.
+func (f *FanOut) Stats() []Stats {
+	res := make([]Stats, 0, len(f.targets))
+	for _, t := range f.targets {
+		if s, ok := t.Writer.(interface {
+			Stats() Stats
+		}); ok {
+			res = append(res, s.Stats())
+		}
+	}
+	return res
+}
+
+// healthy returns the health of targets which report it. Other targets are
+// assumed to be healthy.
+func healthy(w io.Writer) bool {
+	if h, ok := w.(interface {
+		Healthy() bool
+	}); ok {
+		return h.Healthy()
+	}
+	return true
+}
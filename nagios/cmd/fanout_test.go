@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// failingWriter rejects every write.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestFanOut_Deliver(t *testing.T) {
+	tests := []struct {
+		policy   DeliveryPolicy
+		standby  error
+		expected error
+	}{
+		{DeliverAll, nil, nil},
+		{DeliverAll, ErrQueueFull, ErrQueueFull},
+		{DeliverAny, nil, nil},
+		{DeliverAny, ErrQueueFull, nil},
+		{DeliverPrimary, nil, nil},
+		// the primary accepted the command, so a retry would duplicate it.
+		{DeliverPrimary, ErrQueueFull, nil},
+	}
+
+	for _, test := range tests {
+		var primary bytes.Buffer
+		var standby bytes.Buffer
+
+		targets := []Target{{Name: "primary", Writer: &primary}}
+		if test.standby == nil {
+			targets = append(targets, Target{Name: "standby", Writer: &standby})
+		} else {
+			targets = append(targets, Target{Name: "standby", Writer: failingWriter{test.standby}})
+		}
+
+		f, err := NewFanOut(test.policy, targets...)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		deliveries, err := f.Deliver([]byte("[1] ENABLE_NOTIFICATIONS"))
+		if test.expected == nil && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.policy, err)
+		}
+		if test.expected != nil && (!errors.Is(err, ErrDeliveryFailed) || !errors.Is(err, test.expected)) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.policy, err, test.expected)
+		}
+
+		// the primary gets the command even when the standby fails.
+		if primary.String() != "[1] ENABLE_NOTIFICATIONS" {
+			t.Errorf("%s: unexpected primary command, got: '%s'", test.policy, primary.String())
+		}
+
+		if len(deliveries) != 2 || deliveries[0].Target != "primary" || deliveries[0].Err != nil ||
+			deliveries[1].Target != "standby" || deliveries[1].Err != test.standby {
+			t.Errorf("%s: unexpected deliveries, got: '%v'", test.policy, deliveries)
+		}
+	}
+}
+
+func TestFanOut_Deliver_PrimaryFailed(t *testing.T) {
+	var standby bytes.Buffer
+	f, err := NewFanOut(DeliverPrimary,
+		Target{Name: "primary", Writer: failingWriter{ErrQueueFull}},
+		Target{Name: "standby", Writer: &standby},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	deliveries, err := f.Deliver([]byte("[1] ENABLE_NOTIFICATIONS"))
+	if !errors.Is(err, ErrDeliveryFailed) || !errors.Is(err, ErrQueueFull) {
+		t.Errorf("unexpected error, got: '%v'", err)
+	}
+
+	if len(deliveries) != 2 || deliveries[0].Err != ErrQueueFull || deliveries[1].Err != nil {
+		t.Errorf("unexpected deliveries, got: '%v'", deliveries)
+	}
+}
+
+func TestFanOut_Deliver_AllFailed(t *testing.T) {
+	f, err := NewFanOut(DeliverAny,
+		Target{Name: "primary", Writer: failingWriter{ErrQueueFull}},
+		Target{Name: "standby", Writer: failingWriter{ErrClosed}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = f.Write([]byte("[1] ENABLE_NOTIFICATIONS"))
+	if !errors.Is(err, ErrDeliveryFailed) || !errors.Is(err, ErrQueueFull) || !errors.Is(err, ErrClosed) {
+		t.Errorf("unexpected error, got: '%v'", err)
+	}
+}
+
+func TestFanOut_Write_SingleTarget(t *testing.T) {
+	f, err := NewFanOut(DeliverAll, Target{Name: "default", Writer: failingWriter{ErrQueueFull}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := f.Write([]byte("[1] ENABLE_NOTIFICATIONS")); err != ErrQueueFull {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrQueueFull)
+	}
+}
+
+func TestNewFanOut_DuplicateTarget(t *testing.T) {
+	_, err := NewFanOut(DeliverAll,
+		Target{Name: "primary", Writer: &bytes.Buffer{}},
+		Target{Name: "primary", Writer: &bytes.Buffer{}},
+	)
+	if err == nil {
+		t.Errorf("expected error for duplicate target")
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OverflowPolicy decides what happens to a write when the Writer queue is
+// full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for space in the queue.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowReject returns ErrQueueFull.
+	OverflowReject
+
+	// OverflowDropOldest discards the oldest queued commands to make space.
+	// Commands which are being written are not discarded, so ErrQueueFull
+	// is returned if the whole queue is being written.
+	OverflowDropOldest
+)
+
+// ParseOverflowPolicy converts the name of a policy, as used in the config
+// file, into an OverflowPolicy.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "block":
+		return OverflowBlock, nil
+	case "reject":
+		return OverflowReject, nil
+	case "drop_oldest":
+		return OverflowDropOldest, nil
+	default:
+		return OverflowBlock, fmt.Errorf("unknown overflow policy '%s'", s)
+	}
+}
+
+// String returns a string representation of the OverflowPolicy.
+//
+// An empty string is returned for unknown policies.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowReject:
+		return "reject"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	default:
+		return ""
+	}
+}
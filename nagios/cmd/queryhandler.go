@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultQueryHandlerSocket is the default path of the nagios query handler
+// socket.
+const DefaultQueryHandlerSocket = "/usr/local/nagios/var/rw/nagios.qh"
+
+// DefaultQueryHandlerTimeout limits how long a command submission through
+// the query handler may take.
+const DefaultQueryHandlerTimeout = 10 * time.Second
+
+// ErrRejected is returned when nagios refuses a command submitted through the
+// query handler.
+var ErrRejected = errors.New("command rejected")
+
+// QueryHandlerError describes the response of the query handler to a
+// rejected command.
+type QueryHandlerError struct {
+	Code    int
+	Message string
+}
+
+func (e *QueryHandlerError) Error() string {
+	return fmt.Sprintf("%s: %d: %s", ErrRejected, e.Code, e.Message)
+}
+
+// Unwrap allows errors.Is to match ErrRejected.
+func (e *QueryHandlerError) Unwrap() error {
+	return ErrRejected
+}
+
+// QueryHandlerWriter submits commands through the @command handler of the
+// nagios 4 query handler socket.
+//
+// Unlike the external commands file, each write waits for nagios to process
+// the command, and returns a QueryHandlerError if nagios rejects it.
+//
+// QueryHandlerWriter implements io.Writer.
+type QueryHandlerWriter struct {
+	path    string
+	timeout time.Duration
+}
+
+// NewQueryHandlerWriter constructs an instance of QueryHandlerWriter, using
+// the query handler socket at path.
+//
+// A zero timeout means DefaultQueryHandlerTimeout is used.
+func NewQueryHandlerWriter(path string, timeout time.Duration) (*QueryHandlerWriter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("must set query handler socket")
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultQueryHandlerTimeout
+	}
+
+	return &QueryHandlerWriter{
+		path:    path,
+		timeout: timeout,
+	}, nil
+}
+
+// Write submits a command, and waits for nagios to accept or reject it.
+//
+// It is assumed that the input is a full, valid, nagios command, not
+// terminated by a new-line.
+func (w *QueryHandlerWriter) Write(cmd []byte) (int, error) {
+	conn, err := net.DialTimeout("unix", w.path, w.timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(w.timeout)); err != nil {
+		return 0, err
+	}
+
+	// queries are terminated by a null byte. the '#' prefix asks nagios to
+	// close the connection once it has responded.
+	req := make([]byte, 0, len(cmd)+len("#command run ")+1)
+	req = append(req, "#command run "...)
+	req = append(req, cmd...)
+	req = append(req, 0)
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	res, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	if err := parseQueryHandlerResponse(strings.TrimRight(res, "\x00")); err != nil {
+		return 0, err
+	}
+
+	return len(cmd), nil
+}
+
+// parseQueryHandlerResponse checks a 'CODE: MESSAGE' response.
+//
+// The @command handler responds with 0 when a command is accepted, and the
+// query handler itself uses 200, so both are treated as success.
+func parseQueryHandlerResponse(res string) error {
+	parts := strings.SplitN(res, ":", 2)
+	code, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return fmt.Errorf("invalid query handler response '%s'", res)
+	}
+
+	if code == 0 || code == 200 {
+		return nil
+	}
+
+	message := ""
+	if len(parts) == 2 {
+		message = strings.TrimSpace(parts[1])
+	}
+
+	return &QueryHandlerError{
+		Code:    code,
+		Message: message,
+	}
+}
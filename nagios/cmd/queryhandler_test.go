@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveQueryHandler stands in for the nagios query handler, responding to
+// each request with the next response.
+func serveQueryHandler(t *testing.T, responses []string) (string, <-chan string, func()) {
+	dir, err := ioutil.TempDir("", "qh")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+
+	path := filepath.Join(dir, "nagios.qh")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("unable to listen: %s", err)
+	}
+
+	requests := make(chan string, len(responses))
+	go func() {
+		for _, res := range responses {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			req, err := bufio.NewReader(conn).ReadString(0)
+			if err == nil {
+				requests <- req
+				conn.Write([]byte(res))
+			}
+			conn.Close()
+		}
+	}()
+
+	return path, requests, func() {
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestQueryHandlerWriter_Write(t *testing.T) {
+	path, requests, cleanup := serveQueryHandler(t, []string{
+		"0: OK\x00",
+		"2: Malformed command\x00",
+		"400: Bad request\x00",
+		"200: OK",
+	})
+	defer cleanup()
+
+	w, err := NewQueryHandlerWriter(path, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cmd := "[1600000000] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;0;OK"
+	if _, err := w.Write([]byte(cmd)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	req := <-requests
+	if expected := "#command run " + cmd + "\x00"; req != expected {
+		t.Errorf("unexpected request, got: '%s', want: '%s'", req, expected)
+	}
+
+	tests := []struct {
+		code    int
+		message string
+	}{
+		{2, "Malformed command"},
+		{400, "Bad request"},
+	}
+
+	for _, test := range tests {
+		_, err := w.Write([]byte(cmd))
+		if !errors.Is(err, ErrRejected) {
+			t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrRejected)
+			continue
+		}
+
+		var qhErr *QueryHandlerError
+		if !errors.As(err, &qhErr) || qhErr.Code != test.code || qhErr.Message != test.message {
+			t.Errorf("unexpected error, got: '%v', want: '%d: %s'", err, test.code, test.message)
+		}
+	}
+
+	// responses without a trailing null byte are accepted at EOF.
+	if _, err := w.Write([]byte(cmd)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestQueryHandlerWriter_Write_Unavailable(t *testing.T) {
+	w, err := NewQueryHandlerWriter(filepath.Join(os.TempDir(), "does-not-exist", "nagios.qh"), time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = w.Write([]byte("[1600000000] ENABLE_NOTIFICATIONS"))
+	if err == nil || errors.Is(err, ErrRejected) {
+		t.Errorf("unexpected error, got: '%v'", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "nagios.qh") {
+		t.Errorf("expected socket path in error, got: '%v'", err)
+	}
+}
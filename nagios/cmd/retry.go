@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how the Writer retries commands which could not be
+// written to the external commands file.
+type RetryPolicy struct {
+	// InitialBackoff is the delay after the first failure. It doubles after
+	// each consecutive failure, up to MaxBackoff. Zero means there is no
+	// limit.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// MaxAttempts is the number of failed writes after which a command is
+	// dead-lettered. Zero means there is no limit.
+	MaxAttempts int
+
+	// MaxAge is how long a command may be queued before it is
+	// dead-lettered. Zero means there is no limit.
+	MaxAge time.Duration
+}
+
+// DefaultRetryPolicy retries every second at first, backing off to once a
+// minute, and never gives up on a command.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// Backoff returns the delay after n consecutive failures, with jitter of up
+// to half the delay.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < n && d < math.MaxInt64/2; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// expired returns whether a command queued at the given time has been
+// queued for too long.
+func (p RetryPolicy) expired(queued, now time.Time) bool {
+	return p.MaxAge > 0 && now.Sub(queued) > p.MaxAge
+}
+
+// exhausted returns whether a command has failed too many times.
+func (p RetryPolicy) exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	capped := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	uncapped := RetryPolicy{InitialBackoff: time.Second}
+	low := RetryPolicy{InitialBackoff: time.Minute, MaxBackoff: time.Second}
+
+	tests := []struct {
+		policy   RetryPolicy
+		failures int
+		max      time.Duration
+	}{
+		{capped, 1, time.Second},
+		{capped, 2, 2 * time.Second},
+		{capped, 3, 4 * time.Second},
+		{capped, 4, 8 * time.Second},
+		{capped, 5, 10 * time.Second},
+		{capped, 50, 10 * time.Second},
+		{uncapped, 1, time.Second},
+		{uncapped, 5, 16 * time.Second},
+		{uncapped, 11, 1024 * time.Second},
+		{low, 1, time.Second},
+		{low, 3, time.Second},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			d := test.policy.Backoff(test.failures)
+			if d < test.max/2 || d > test.max {
+				t.Errorf("unexpected backoff after %d failures with max '%s', got: '%s', want between: '%s' and '%s'",
+					test.failures, test.policy.MaxBackoff, d, test.max/2, test.max)
+				break
+			}
+		}
+	}
+
+	// without a limit, the backoff keeps growing, without overflowing.
+	if d := uncapped.Backoff(1000); d < uncapped.Backoff(30) {
+		t.Errorf("unexpected backoff after 1000 failures, got: '%s'", d)
+	}
+}
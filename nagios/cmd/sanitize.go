@@ -9,8 +9,18 @@ var sanitizer = strings.NewReplacer(
 	";", ":",
 )
 
+var outputSanitizer = strings.NewReplacer(
+	"\n", "\\n",
+)
+
 // Sanitize should be used to ensure strings inserted into nagios commands
 // are properly escaped.
 func Sanitize(s string) string {
 	return sanitizer.Replace(s)
 }
+
+// SanitizeOutput escapes the plugin output of a check result. Unlike
+// Sanitize, ';' is kept, as it separates the fields of performance data.
+func SanitizeOutput(s string) string {
+	return outputSanitizer.Replace(s)
+}
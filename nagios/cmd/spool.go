@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpoolFile is the name of the journal kept in the spool directory.
+const SpoolFile = "commands.journal"
+
+// spool is an append-only journal of queued commands.
+//
+// Each queued command is recorded as '+SEQ QUEUED COMMAND', where QUEUED is
+// a unix timestamp, and is followed by a '-SEQ' record once it has been
+// written to the external commands file. Commands without a '-' record are
+// replayed when the spool is reopened.
+//
+// Records are appended while the Writer holds its lock, but synced to disk
+// after it has been released, so that concurrent writes share an fsync
+// rather than queueing behind each other's.
+type spool struct {
+	path string
+	f    *os.File
+	size int64
+
+	// appended counts the records appended, and synced those known to be
+	// on disk. syncMu serialises syncs, and guards f against being replaced
+	// by rewrite during a sync.
+	appended uint64
+	syncMu   sync.Mutex
+	synced   uint64
+}
+
+// openSpool opens the journal in dir, creating the directory if required,
+// and returns the commands which have not yet been written.
+func openSpool(dir string) (*spool, []entry, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, nil, err
+	}
+
+	s := &spool{
+		path: filepath.Join(dir, SpoolFile),
+	}
+
+	pending, err := readSpool(s.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.rewrite(pending); err != nil {
+		return nil, nil, err
+	}
+
+	return s, pending, nil
+}
+
+// readSpool returns the commands in the journal which have not been written,
+// ordered by sequence number.
+func readSpool(path string) ([]entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pending := make(map[uint64]entry)
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			// a partial final record is left by a crash part way through
+			// an append, in which case the write was never acknowledged.
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if len(line) < 2 {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			parts := strings.SplitN(line[1:], " ", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			seq, err := strconv.ParseUint(parts[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			queued, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			pending[seq] = entry{
+				seq:    seq,
+				cmd:    parts[2],
+				queued: time.Unix(queued, 0),
+			}
+
+		case '-':
+			seq, err := strconv.ParseUint(line[1:], 10, 64)
+			if err != nil {
+				continue
+			}
+			delete(pending, seq)
+		}
+	}
+
+	entries := make([]entry, 0, len(pending))
+	for _, e := range pending {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+
+	return entries, nil
+}
+
+// append records a queued command. It returns a mark to pass to sync, once
+// the caller has released its lock, which returns once the record is on
+// disk.
+func (s *spool) append(e entry) (uint64, error) {
+	if strings.Contains(e.cmd, "\n") {
+		return 0, fmt.Errorf("command contains a new-line")
+	}
+
+	n, err := fmt.Fprintf(s.f, "+%d %d %s\n", e.seq, e.queued.Unix(), e.cmd)
+	s.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+
+	return atomic.AddUint64(&s.appended, 1), nil
+}
+
+// sync syncs the journal to disk, unless the record with the given mark has
+// been synced already. A single fsync covers every record appended before
+// it started, so concurrent callers are committed together.
+func (s *spool) sync(mark uint64) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	if s.synced >= mark {
+		return nil
+	}
+
+	if s.f == nil {
+		return os.ErrClosed
+	}
+
+	appended := atomic.LoadUint64(&s.appended)
+	if err := s.f.Sync(); err != nil {
+		return err
+	}
+	s.synced = appended
+
+	return nil
+}
+
+// ack records that a command has been written.
+//
+// The record is not synced, so a command may be replayed a second time
+// after a crash.
+func (s *spool) ack(seq uint64) error {
+	n, err := fmt.Fprintf(s.f, "-%d\n", seq)
+	s.size += int64(n)
+	return err
+}
+
+// rewrite replaces the journal with one containing only the given commands.
+func (s *spool) rewrite(pending []entry) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	appended := atomic.LoadUint64(&s.appended)
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	var size int64
+	for _, e := range pending {
+		n, _ := fmt.Fprintf(w, "+%d %d %s\n", e.seq, e.queued.Unix(), e.cmd)
+		size += int64(n)
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	if dir, err := os.Open(filepath.Dir(s.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	if s.f != nil {
+		s.f.Close()
+	}
+
+	s.f, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	s.size = size
+	s.synced = appended
+
+	return nil
+}
+
+// close closes the journal, once any sync in progress has finished.
+func (s *spool) close() error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import "time"
+
+// Stats is a snapshot of the state of a Writer.
+//
+// The counters are totals since the Writer was created, and count commands,
+// not writes to the external commands file.
+type Stats struct {
+	Target string
+
+	// Enqueued commands were accepted by Write.
+	Enqueued uint64
+
+	// Rejected commands were refused by Write because the queue was full.
+	Rejected uint64
+
+	// Written commands were written to the external commands file.
+	Written uint64
+
+	// Failed counts failed attempts to write a command, and Retried counts
+	// the failed commands which were kept in the queue to be tried again.
+	Failed  uint64
+	Retried uint64
+
+	// Dropped commands were removed from the queue without being written,
+	// either to the dead-letter sink or on Close.
+	Dropped uint64
+
+	// Coalesced commands replaced a queued passive check result, and are
+	// also counted as enqueued.
+	Coalesced uint64
+
+	QueueDepth  int
+	QueuedBytes int64
+
+	// OldestAge is how long the command at the head of the queue has been
+	// waiting, or zero if the queue is empty.
+	OldestAge time.Duration
+
+	// Open is whether the external commands file is currently open.
+	Open bool
+
+	Healthy bool
+
+	LastWrite     time.Time
+	LastError     string
+	LastErrorTime time.Time
+}
+
+// counters holds the running totals reported by Stats.
+type counters struct {
+	enqueued  uint64
+	rejected  uint64
+	written   uint64
+	failed    uint64
+	retried   uint64
+	dropped   uint64
+	coalesced uint64
+}
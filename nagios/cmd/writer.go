@@ -1,48 +1,148 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 )
 
-// Number of 'in-flight' commands to store in the command writer channel.
+// Number of 'in-flight' commands to store in the command writer queue.
 const DefaultWriteBufferSize = 1000
 
+// PipeBuf is the largest write to a fifo which is guaranteed to be atomic on
+// linux. Commands are batched into writes of up to this size, including the
+// new-line terminating each command.
+const PipeBuf = 4096
+
+// The spool journal is compacted once it is this much larger than twice the
+// size of the queued commands.
+const spoolCompactBytes = 1 << 20
+
 const DefaultExternalCommandsFile = "/usr/share/nagios/rw/nagios.cmd"
 
+var (
+	// ErrClosed is returned when writing to, or closing, a closed Writer.
+	ErrClosed = errors.New("writer closed")
+
+	// ErrQueueFull is returned by non-blocking writes when the queue is full.
+	ErrQueueFull = errors.New("queue full")
+
+	// ErrCommandTooLong is returned when a command does not fit in a single
+	// atomic write to the external commands file.
+	ErrCommandTooLong = errors.New("command too long")
+
+	// ErrDropped is returned by Close when queued commands could not be
+	// written before the deadline.
+	ErrDropped = errors.New("commands dropped")
+)
+
 // Writer writes to the Nagios external commands file.
 //
 // The writes are buffered in a queue, to handle cases where the nagios
-// external commands file is temporarily unavailable.
+// external commands file is temporarily unavailable. The queue can be backed
+// by a spool directory, so that queued commands survive a restart.
 //
-// Writer implements io.Writer by pushing a command onto the channel.
-// A consumer coroutine must be started via the Run method.
+// Writer implements io.Writer by pushing a command onto the queue.
+// A consumer coroutine must be started via the Run method, and stopped via
+// the Close method.
 //
 // The code assumes the external command file is a fifo, such that writes
 // to the file will return an error when the underlying pipe has been closed.
 type Writer struct {
-	filename    string
-	logger      *zap.Logger
-	nonBlocking bool
-	queue       chan string
+	name          string
+	filename      string
+	logger        *zap.Logger
+	overflow      OverflowPolicy
+	bufferSize    int
+	spoolMaxBytes int64
+	retry         RetryPolicy
+	deadLetters   DeadLetterSink
+	coalesce      bool
+
+	mu          sync.Mutex
+	space       *sync.Cond
+	queue       []entry
+	queuedBytes int64
+	seq         uint64
+	spool       *spool
+	closed      bool
+	running     bool
+	healthy     bool
+	open        bool
+	counters    counters
+	lastWrite   time.Time
+	lastErr     error
+	lastErrTime time.Time
+
+	// keys maps the coalesce key of each queued passive check result to its
+	// sequence number. Commands up to and including flushing are being
+	// written by Run, and are not replaced.
+	keys     map[string]uint64
+	flushing uint64
+
+	// notify is signalled when a command is queued.
+	notify chan struct{}
+
+	// closing is closed when Close is called, and abort is closed when the
+	// Close deadline passes. done is closed when Run returns.
+	closing chan struct{}
+	abort   chan struct{}
+	done    chan struct{}
+}
+
+// batch is a run of commands from the head of the queue, which are written
+// to the external commands file together.
+type batch []entry
+
+// bytes renders the batch as new-line terminated commands.
+func (b batch) bytes() []byte {
+	var buf bytes.Buffer
+	for _, e := range b {
+		buf.WriteString(e.cmd)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// entry is a queued command.
+type entry struct {
+	seq      uint64
+	cmd      string
+	key      string
+	queued   time.Time
+	attempts int
 }
 
 type writerConfig struct {
-	bufferSize  int
-	filename    string
-	logger      *zap.Logger
-	nonBlocking bool
+	bufferSize    int
+	name          string
+	filename      string
+	logger        *zap.Logger
+	overflow      OverflowPolicy
+	spoolDir      string
+	spoolMaxBytes int64
+	retry         RetryPolicy
+	deadLetters   DeadLetterSink
+	coalesce      bool
 }
 
 // NewWriter constructs an instance of Writer.
+//
+// If a spool directory is set, commands left in the spool by a previous
+// Writer are queued for writing.
 func NewWriter(opts ...WriterOption) (*Writer, error) {
 	cfg := writerConfig{
 		bufferSize: DefaultWriteBufferSize,
 		filename:   DefaultExternalCommandsFile,
 		logger:     zap.NewNop(),
+		retry:      DefaultRetryPolicy(),
 	}
 	for _, opt := range opts {
 		if err := opt(&cfg); err != nil {
@@ -50,95 +150,689 @@ func NewWriter(opts ...WriterOption) (*Writer, error) {
 		}
 	}
 
-	return &Writer{
-		filename: cfg.filename,
-		logger: cfg.logger.Named("ExternalCommands").With(
-			zap.String("command file", cfg.filename),
-		),
-		queue: make(chan string, cfg.bufferSize),
-	}, nil
+	logger := cfg.logger.Named("ExternalCommands").With(
+		zap.String("command file", cfg.filename),
+	)
+	if cfg.name != "" {
+		logger = logger.With(zap.String("target", cfg.name))
+	}
+
+	w := &Writer{
+		name:          cfg.name,
+		filename:      cfg.filename,
+		logger:        logger,
+		healthy:       true,
+		overflow:      cfg.overflow,
+		bufferSize:    cfg.bufferSize,
+		spoolMaxBytes: cfg.spoolMaxBytes,
+		retry:         cfg.retry,
+		deadLetters:   cfg.deadLetters,
+		coalesce:      cfg.coalesce,
+		keys:          make(map[string]uint64),
+		notify:        make(chan struct{}, 1),
+		closing:       make(chan struct{}),
+		abort:         make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	w.space = sync.NewCond(&w.mu)
+
+	if w.deadLetters == nil {
+		w.deadLetters = NewDeadLetterBuffer(DefaultDeadLetterSize)
+	}
+
+	if cfg.spoolDir != "" {
+		s, pending, err := openSpool(cfg.spoolDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open spool: %w", err)
+		}
+		w.spool = s
+
+		for _, e := range pending {
+			// the spool may have been written by a version which did not
+			// limit the length of commands, and they would never fit in a
+			// batch.
+			if len(e.cmd)+1 > PipeBuf {
+				w.record(e, ErrCommandTooLong.Error())
+				if err := s.ack(e.seq); err != nil {
+					return nil, fmt.Errorf("unable to update spool: %w", err)
+				}
+				continue
+			}
+			if w.coalesce {
+				e = w.index(e)
+			}
+			w.queue = append(w.queue, e)
+			w.queuedBytes += int64(len(e.cmd))
+			w.seq = e.seq
+		}
+
+		if len(w.queue) > 0 {
+			w.logger.Info("replaying spooled commands",
+				zap.Int("commands", len(w.queue)),
+			)
+			w.notify <- struct{}{}
+		}
+	}
+
+	return w, nil
 }
 
-// Run launches an infinite loop. It should be run as a goroutine.
-func (w *Writer) Run() {
+// Run writes queued commands to the external commands file until ctx is
+// cancelled, or the Writer is closed and the queue has been drained. It
+// should be run as a goroutine.
+//
+// Commands are written in order. If a write fails, the command stays at the
+// head of the queue and is retried according to the retry policy, after
+// which it is sent to the dead-letter sink.
+//
+// Commands which are still queued when ctx is cancelled are not written.
+func (w *Writer) Run(ctx context.Context) error {
+	defer close(w.done)
+
+	w.mu.Lock()
+	w.running = true
+	w.mu.Unlock()
+
 	var f *os.File
+	defer func() {
+		if f != nil {
+			f.Close()
+			w.setOpen(false)
+		}
+	}()
+
+	// failures counts consecutive failures, for backing off.
+	failures := 0
 
 	log := w.logger
 	for {
+		select {
+		case <-w.abort:
+			return nil
+		default:
+		}
+
+		// commands are only removed from the queue once they have been
+		// written, so they are retried in order if the write fails.
+		batch, ok := w.peek()
+		if !ok {
+			select {
+			case <-w.closing:
+				return nil
+			default:
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-w.abort:
+				return nil
+			case <-w.closing:
+			case <-w.notify:
+			}
+			continue
+		}
+
 		// the nagios command file is only available when nagios is running.
 		// so, it is expected to not exist, for example when nagios is
 		// in the middle of restarting.
 		if f == nil {
 			newFile, err := os.OpenFile(w.filename, os.O_APPEND|os.O_WRONLY, 0644)
 			if err != nil {
+				failures++
+				w.setError(err)
+				w.release()
+				backoff := w.retry.Backoff(failures)
 				log.Warn("unable to open command file",
-					zap.String("retry interval", "1s"),
+					zap.Duration("retry interval", backoff),
 				)
-				time.Sleep(time.Second)
+				if err := w.sleep(ctx, backoff); err != nil {
+					return err
+				}
 				continue
 			}
 			f = newFile
+			w.setOpen(true)
 		}
 
-		// read the next command from the queue and attempt to write to the
-		// external command file
-		cmd, ok := <-w.queue
-		if !ok {
-			continue
-		}
-
-		// if the external commands file goes away, requeue the command for
-		// later and redo the loop.
-		_, err := f.Write([]byte(cmd + "\n"))
-		if err != nil {
-			err = f.Sync()
-		}
+		// if the external commands file goes away, keep the commands queued
+		// and redo the loop. writes of up to PipeBuf bytes are atomic, so
+		// either the whole batch is written or none of it is.
+		_, err := f.Write(batch.bytes())
 		if err != nil {
+			f.Close()
 			f = nil
+			w.setOpen(false)
+			failures++
+			w.setError(err)
+			backoff := w.retry.Backoff(failures)
 			log.Warn("unable to write to command file",
-				zap.String("retry interval", "1s"),
+				zap.Duration("retry interval", backoff),
 				zap.Error(err),
 			)
-			go func() {
-				w.queue <- cmd
-			}()
-			time.Sleep(time.Second)
+			w.fail(batch, err)
+			if err := w.sleep(ctx, backoff); err != nil {
+				return err
+			}
 			continue
 		}
 
+		failures = 0
+		w.pop(batch)
 		log.Debug("wrote to command file",
-			zap.String("command", cmd),
+			zap.Int("commands", len(batch)),
+		)
+	}
+}
+
+// DeadLetters returns the commands which the Writer gave up on.
+func (w *Writer) DeadLetters() []DeadLetter {
+	return w.deadLetters.List()
+}
+
+// Name returns the name of the Writer, as set by WithName.
+func (w *Writer) Name() string {
+	return w.name
+}
+
+// Healthy returns false if the last attempt to open or write to the external
+// commands file failed.
+func (w *Writer) Healthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.healthy
+}
+
+// Stats returns a snapshot of the queue and the counters of the Writer.
+func (w *Writer) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := Stats{
+		Target:        w.name,
+		Enqueued:      w.counters.enqueued,
+		Rejected:      w.counters.rejected,
+		Written:       w.counters.written,
+		Failed:        w.counters.failed,
+		Retried:       w.counters.retried,
+		Dropped:       w.counters.dropped,
+		Coalesced:     w.counters.coalesced,
+		QueueDepth:    len(w.queue),
+		QueuedBytes:   w.queuedBytes,
+		Open:          w.open,
+		Healthy:       w.healthy,
+		LastWrite:     w.lastWrite,
+		LastErrorTime: w.lastErrTime,
+	}
+
+	if len(w.queue) > 0 {
+		stats.OldestAge = time.Since(w.queue[0].queued)
+	}
+
+	if w.lastErr != nil {
+		stats.LastError = w.lastErr.Error()
+	}
+
+	return stats
+}
+
+func (w *Writer) setOpen(b bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.open = b
+}
+
+// setError records a failure to open or write to the external commands file.
+func (w *Writer) setError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.healthy = false
+	w.lastErr = err
+	w.lastErrTime = time.Now()
+}
+
+// Close stops the Writer from accepting new commands, and waits for Run to
+// write the queued commands to the external commands file.
+//
+// If the queue has not been drained by the time ctx is done, Run is stopped
+// once any write in progress has finished, and the remaining commands are
+// dropped and an error wrapping ErrDropped is returned.
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	w.space.Broadcast()
+	w.mu.Unlock()
+
+	close(w.closing)
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		close(w.abort)
+
+		// Run acknowledges written commands in the spool, so must have
+		// returned before the spool is rewritten. it returns once a write
+		// in progress has finished.
+		w.mu.Lock()
+		running := w.running
+		w.mu.Unlock()
+		if running {
+			<-w.done
+		}
+	}
+
+	w.mu.Lock()
+	dropped := len(w.queue)
+	w.queue = nil
+	w.queuedBytes = 0
+	spool := w.spool
+	w.spool = nil
+	if spool == nil {
+		w.counters.dropped += uint64(dropped)
+	}
+	w.mu.Unlock()
+
+	// spooled commands are not lost, they are replayed by the next Writer.
+	// the journal is truncated if they were all written.
+	if spool != nil {
+		if dropped > 0 {
+			w.logger.Warn("left queued commands in spool",
+				zap.Int("commands", dropped),
+			)
+		} else if err := spool.rewrite(nil); err != nil {
+			w.logger.Warn("unable to compact spool",
+				zap.Error(err),
+			)
+		}
+		return spool.close()
+	}
+
+	if dropped > 0 {
+		w.logger.Warn("dropped queued commands",
+			zap.Int("dropped", dropped),
 		)
+		return fmt.Errorf("%w: %d", ErrDropped, dropped)
 	}
+
+	return nil
 }
 
-// Write appends a command string to the queue, for later writing.
+// Write appends a command string to the queue, for later writing. If the
+// Writer has a spool directory, the command is synced to the spool before
+// Write returns.
 //
-// If the queue is in non-blocking mode, an error will be returned when the
-// queue is full.
+// When the queue is full, the overflow policy decides whether the write
+// blocks until the queue empties, returns ErrQueueFull, or drops the oldest
+// queued commands.
 //
-// If the queue is in blocking mode, the write will block until the queue
-// empties.
+// ErrClosed is returned once Close has been called, and ErrCommandTooLong if
+// the command would not be written atomically.
 //
 // It is assumed that the input is a full, valid, nagios command, not
 // terminated by a new-line.
 func (w *Writer) Write(cmd []byte) (n int, err error) {
-	if !w.nonBlocking {
-		w.queue <- string(cmd)
-		return len(cmd), nil
+	if len(cmd)+1 > PipeBuf {
+		return 0, ErrCommandTooLong
+	}
+
+	spool, mark, err := w.enqueue(string(cmd))
+	if err != nil {
+		return 0, err
+	}
+
+	// the spool is synced without the lock, so that concurrent writes
+	// share an fsync.
+	if spool != nil {
+		if err := spool.sync(mark); err != nil {
+			return 0, fmt.Errorf("unable to spool command: %w", err)
+		}
+	}
+
+	return len(cmd), nil
+}
+
+// enqueue appends a command to the queue, or replaces a queued command it
+// coalesces with. If the Writer has a spool, it is returned with the mark
+// to sync it to.
+func (w *Writer) enqueue(cmd string) (*spool, uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.coalesce && !w.closed {
+		if ok, mark, err := w.replace(cmd); ok || err != nil {
+			return w.spool, mark, err
+		}
+	}
+
+	for !w.closed && w.full(len(cmd)) {
+		switch {
+		case w.overflow == OverflowReject, len(w.queue) == 0:
+			w.counters.rejected++
+			return nil, 0, ErrQueueFull
+		case w.overflow == OverflowDropOldest:
+			if !w.drop() {
+				w.counters.rejected++
+				return nil, 0, ErrQueueFull
+			}
+		default:
+			w.space.Wait()
+		}
+	}
+
+	if w.closed {
+		return nil, 0, ErrClosed
+	}
+
+	w.seq++
+	e := entry{
+		seq:    w.seq,
+		cmd:    cmd,
+		queued: time.Now(),
+	}
+	if w.coalesce {
+		e = w.index(e)
+	}
+
+	var mark uint64
+	if w.spool != nil {
+		var err error
+		if mark, err = w.spool.append(e); err != nil {
+			return nil, 0, fmt.Errorf("unable to spool command: %w", err)
+		}
 	}
 
+	w.queue = append(w.queue, e)
+	w.queuedBytes += int64(len(e.cmd))
+	w.counters.enqueued++
+
 	select {
-	case w.queue <- string(cmd):
-		return len(cmd), nil
+	case w.notify <- struct{}{}:
 	default:
-		return 0, fmt.Errorf("channel blocked")
+	}
+
+	return w.spool, mark, nil
+}
+
+// full returns whether a command of n bytes does not fit in the queue.
+func (w *Writer) full(n int) bool {
+	if len(w.queue) >= w.bufferSize {
+		return true
+	}
+
+	return w.spool != nil && w.spoolMaxBytes > 0 && w.queuedBytes+int64(n) > w.spoolMaxBytes
+}
+
+// peek returns a batch of commands from the head of the queue, after
+// dead-lettering any commands which have been queued for too long.
+//
+// The batch is as many commands as fit in a single atomic write to a fifo.
+func (w *Writer) peek() (batch, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	for len(w.queue) > 0 {
+		if w.retry.expired(w.queue[0].queued, now) {
+			w.deadLetter("expired")
+		} else if len(w.queue[0].cmd)+1 > PipeBuf {
+			w.deadLetter(ErrCommandTooLong.Error())
+		} else {
+			break
+		}
+	}
+
+	if len(w.queue) == 0 {
+		return nil, false
+	}
+
+	size := 0
+	var b batch
+	for _, e := range w.queue {
+		size += len(e.cmd) + 1
+		if size > PipeBuf {
+			break
+		}
+		b = append(b, e)
+	}
+	if len(b) == 0 {
+		return nil, false
+	}
+	w.flushing = b[len(b)-1].seq
+
+	return b, true
+}
+
+// pop removes a batch of commands from the head of the queue, once they
+// have been written.
+//
+// Commands may have been dropped from the head while the batch was being
+// written, so each is only removed if it is still at the head.
+func (w *Writer) pop(b batch) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushing = 0
+	w.healthy = true
+	w.lastWrite = time.Now()
+	w.counters.written += uint64(len(b))
+
+	for _, e := range b {
+		if len(w.queue) > 0 && w.queue[0].seq == e.seq {
+			w.remove(0)
+		}
+	}
+}
+
+// release marks a batch which was not written as no longer in flight.
+func (w *Writer) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushing = 0
+}
+
+// fail records a failed attempt to write a batch of commands, and
+// dead-letters the commands at the head of the queue which have run out of
+// attempts.
+func (w *Writer) fail(b batch, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushing = 0
+
+	attempted := make(map[uint64]bool, len(b))
+	for _, e := range b {
+		attempted[e.seq] = true
+	}
+
+	for i := range w.queue {
+		if !attempted[w.queue[i].seq] {
+			break
+		}
+		w.queue[i].attempts++
+		w.counters.failed++
+	}
+
+	for len(w.queue) > 0 && attempted[w.queue[0].seq] && w.retry.exhausted(w.queue[0].attempts) {
+		w.deadLetter(err.Error())
+	}
+
+	for i := range w.queue {
+		if !attempted[w.queue[i].seq] {
+			break
+		}
+		w.counters.retried++
+	}
+}
+
+// index records the coalesce key of a passive check result which is being
+// queued. The caller must hold the lock.
+func (w *Writer) index(e entry) entry {
+	if key, ok := coalesceKey(e.cmd); ok {
+		e.key = key
+		w.keys[key] = e.seq
+	}
+	return e
+}
+
+// replace replaces a queued passive check result for the same host and
+// service as cmd, keeping its place in the queue, and returns whether there
+// was one to replace, and the mark to sync the spool to. Commands which are
+// being written are not replaced. The caller must hold the lock.
+func (w *Writer) replace(cmd string) (bool, uint64, error) {
+	key, ok := coalesceKey(cmd)
+	if !ok {
+		return false, 0, nil
+	}
+
+	seq, ok := w.keys[key]
+	if !ok || seq <= w.flushing {
+		return false, 0, nil
+	}
+
+	i := sort.Search(len(w.queue), func(i int) bool {
+		return w.queue[i].seq >= seq
+	})
+	if i == len(w.queue) || w.queue[i].seq != seq {
+		return false, 0, nil
+	}
+
+	// the replacement keeps the sequence number, so it supersedes the
+	// replaced command when the spool is replayed.
+	e := w.queue[i]
+	e.cmd = cmd
+	e.queued = time.Now()
+
+	var mark uint64
+	if w.spool != nil {
+		var err error
+		if mark, err = w.spool.append(e); err != nil {
+			return false, 0, fmt.Errorf("unable to spool command: %w", err)
+		}
+	}
+
+	w.queuedBytes += int64(len(e.cmd) - len(w.queue[i].cmd))
+	w.queue[i] = e
+	w.counters.enqueued++
+	w.counters.coalesced++
+
+	return true, mark, nil
+}
+
+// drop discards the oldest queued command which is not being written, and
+// returns false if every queued command is being written. The caller must
+// hold the lock.
+func (w *Writer) drop() bool {
+	for i, e := range w.queue {
+		if e.seq > w.flushing {
+			w.record(e, "queue full")
+			w.remove(i)
+			return true
+		}
+	}
+	return false
+}
+
+// deadLetter removes the command at the head of the queue and sends it to
+// the dead-letter sink. The caller must hold the lock.
+func (w *Writer) deadLetter(reason string) {
+	w.record(w.queue[0], reason)
+	w.remove(0)
+}
+
+// record adds a command to the dead letters. The caller must hold the lock,
+// or own the Writer.
+func (w *Writer) record(e entry, reason string) {
+	w.logger.Warn("dead-lettering command",
+		zap.String("command", e.cmd),
+		zap.String("reason", reason),
+		zap.Int("attempts", e.attempts),
+	)
+
+	w.deadLetters.Add(DeadLetter{
+		Target:   w.name,
+		Command:  e.cmd,
+		Reason:   reason,
+		Attempts: e.attempts,
+		Queued:   e.queued,
+		Failed:   time.Now(),
+	})
+	w.counters.dropped++
+}
+
+// remove removes the i'th queued command, and records it as written in the
+// spool. The caller must hold the lock.
+func (w *Writer) remove(i int) {
+	e := w.queue[i]
+	if i == 0 {
+		w.queue[0] = entry{}
+		w.queue = w.queue[1:]
+	} else {
+		copy(w.queue[i:], w.queue[i+1:])
+		w.queue[len(w.queue)-1] = entry{}
+		w.queue = w.queue[:len(w.queue)-1]
+	}
+	w.queuedBytes -= int64(len(e.cmd))
+	if e.key != "" && w.keys[e.key] == e.seq {
+		delete(w.keys, e.key)
+	}
+	w.space.Signal()
+
+	if w.spool == nil {
+		return
+	}
+
+	if err := w.spool.ack(e.seq); err != nil {
+		w.logger.Warn("unable to update spool",
+			zap.Error(err),
+		)
+	}
+
+	// compact the journal once it is mostly made up of commands which have
+	// already been written.
+	if w.spool.size > 2*w.queuedBytes+spoolCompactBytes {
+		if err := w.spool.rewrite(w.queue); err != nil {
+			w.logger.Warn("unable to compact spool",
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// sleep waits for d, returning early if ctx is cancelled or Close gives up
+// waiting for the queue to drain.
+func (w *Writer) sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.abort:
+		return ErrClosed
+	case <-t.C:
+		return nil
 	}
 }
 
 // WriterOption passes in parameters to NewWriter().
 type WriterOption func(*writerConfig) error
 
+// WithName names the Writer, to tell it apart from other targets.
+func WithName(name string) WriterOption {
+	return func(cfg *writerConfig) error {
+		cfg.name = name
+		return nil
+	}
+}
+
 func WithFilename(f string) WriterOption {
 	return func(cfg *writerConfig) error {
 		cfg.filename = f
@@ -154,12 +848,76 @@ func WithLogger(l *zap.Logger) WriterOption {
 	}
 }
 
-// WithNonBlocking sets the blocking behaviour of the Write() method.
+// WithNonBlocking sets the blocking behaviour of the Write() method. It is
+// shorthand for the OverflowReject and OverflowBlock policies.
 //
 // Writers are blocking by default.
 func WithNonBlocking(b bool) WriterOption {
 	return func(cfg *writerConfig) error {
-		cfg.nonBlocking = b
+		cfg.overflow = OverflowBlock
+		if b {
+			cfg.overflow = OverflowReject
+		}
+		return nil
+	}
+}
+
+// WithOverflowPolicy sets the behaviour of the Write() method when the
+// queue is full.
+func WithOverflowPolicy(p OverflowPolicy) WriterOption {
+	return func(cfg *writerConfig) error {
+		cfg.overflow = p
+		return nil
+	}
+}
+
+// WithRetryPolicy sets how commands are retried when they cannot be
+// written.
+func WithRetryPolicy(p RetryPolicy) WriterOption {
+	return func(cfg *writerConfig) error {
+		cfg.retry = p
+		return nil
+	}
+}
+
+// WithDeadLetterSink sets where commands are sent when the Writer gives up
+// on them. By default, the most recent are kept in memory.
+func WithDeadLetterSink(s DeadLetterSink) WriterOption {
+	return func(cfg *writerConfig) error {
+		cfg.deadLetters = s
+		return nil
+	}
+}
+
+// WithCoalescing replaces a queued passive check result with a newer result
+// for the same host, or host and service, instead of queueing both. The
+// replacement keeps the place of the result it replaces in the queue.
+func WithCoalescing(b bool) WriterOption {
+	return func(cfg *writerConfig) error {
+		cfg.coalesce = b
+		return nil
+	}
+}
+
+// WithBufferSize sets the maximum number of queued commands.
+func WithBufferSize(n int) WriterOption {
+	return func(cfg *writerConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("buffer size must be positive")
+		}
+		cfg.bufferSize = n
+		return nil
+	}
+}
+
+// WithSpool keeps queued commands in a journal in dir, so they are not lost
+// if the Writer is restarted before they are written.
+//
+// If maxBytes is positive, it limits the total size of the queued commands.
+func WithSpool(dir string, maxBytes int64) WriterOption {
+	return func(cfg *writerConfig) error {
+		cfg.spoolDir = dir
+		cfg.spoolMaxBytes = maxBytes
 		return nil
 	}
 }
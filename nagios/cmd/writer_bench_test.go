@@ -0,0 +1,157 @@
+//go:build linux
+// +build linux
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// mkfifo creates a fifo which is drained in the background, like nagios
+// reading its external commands file.
+func mkfifo(b *testing.B) (string, func()) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		b.Fatalf("unable to create temp dir: %s", err)
+	}
+
+	filename := filepath.Join(dir, "nagios.cmd")
+	if err := syscall.Mkfifo(filename, 0600); err != nil {
+		os.RemoveAll(dir)
+		b.Fatalf("unable to create fifo: %s", err)
+	}
+
+	// opening the read side with O_RDWR stops it seeing EOF between
+	// writers, and stops the write side blocking on open.
+	r, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		os.RemoveAll(dir)
+		b.Fatalf("unable to open fifo: %s", err)
+	}
+	go io.Copy(ioutil.Discard, r)
+
+	return filename, func() {
+		r.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func benchmarkCommand(i int) []byte {
+	return []byte(fmt.Sprintf("[%d] PROCESS_SERVICE_CHECK_RESULT;web%04d.example.com;HTTP;0;HTTP OK: HTTP/1.1 200 OK - 1234 bytes in 0.012 second response time|time=0.012s;;;0.000000 size=1234B;;;0", time.Now().Unix(), i))
+}
+
+// BenchmarkFIFO_Unbatched writes each command with its own syscall, as the
+// Writer used to.
+func BenchmarkFIFO_Unbatched(b *testing.B) {
+	filename, cleanup := mkfifo(b)
+	defer cleanup()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		b.Fatalf("unable to open fifo: %s", err)
+	}
+	defer f.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Write(append(benchmarkCommand(i), '\n')); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkWriter(b *testing.B) {
+	filename, cleanup := mkfifo(b)
+	defer cleanup()
+
+	w, err := NewWriter(WithFilename(filename), WithBufferSize(b.N+1))
+	if err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+	go w.Run(context.Background())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(benchmarkCommand(i)); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func BenchmarkWriter_Spool(b *testing.B) {
+	filename, cleanup := mkfifo(b)
+	defer cleanup()
+
+	w, err := NewWriter(
+		WithFilename(filename),
+		WithBufferSize(b.N+1),
+		WithSpool(filepath.Join(filepath.Dir(filename), "spool"), 0),
+	)
+	if err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+	go w.Run(context.Background())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(benchmarkCommand(i)); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+}
+
+// BenchmarkWriter_Spool_Parallel writes from many goroutines, which share
+// the spool's fsyncs.
+func BenchmarkWriter_Spool_Parallel(b *testing.B) {
+	filename, cleanup := mkfifo(b)
+	defer cleanup()
+
+	w, err := NewWriter(
+		WithFilename(filename),
+		WithBufferSize(b.N+1),
+		WithSpool(filepath.Join(filepath.Dir(filename), "spool"), 0),
+	)
+	if err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+	go w.Run(context.Background())
+
+	var n int64
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := w.Write(benchmarkCommand(int(atomic.AddInt64(&n, 1)))); err != nil {
+				b.Errorf("unexpected error: %s", err)
+				return
+			}
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		b.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter_Close(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "nagios.cmd")
+	if err := ioutil.WriteFile(filename, nil, 0644); err != nil {
+		t.Fatalf("unable to create command file: %s", err)
+	}
+
+	w, err := NewWriter(WithFilename(filename))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, cmd := range []string{"[1] ENABLE_NOTIFICATIONS", "[2] DISABLE_NOTIFICATIONS"} {
+		if _, err := w.Write([]byte(cmd)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- w.Run(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error from Run: %s", err)
+	}
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("unable to read command file: %s", err)
+	}
+
+	expected := "[1] ENABLE_NOTIFICATIONS\n[2] DISABLE_NOTIFICATIONS\n"
+	if string(contents) != expected {
+		t.Errorf("unexpected commands, got: '%s', want: '%s'", contents, expected)
+	}
+
+	if _, err := w.Write([]byte("[3] ENABLE_NOTIFICATIONS")); !errors.Is(err, ErrClosed) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrClosed)
+	}
+
+	if err := w.Close(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrClosed)
+	}
+}
+
+func TestWriter_Close_Dropped(t *testing.T) {
+	w, err := NewWriter(WithFilename(filepath.Join(os.TempDir(), "does-not-exist", "nagios.cmd")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write([]byte("[1] ENABLE_NOTIFICATIONS")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	go w.Run(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Close(ctx); !errors.Is(err, ErrDropped) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrDropped)
+	}
+
+	// Run has returned by the time Close does.
+	select {
+	case <-w.done:
+	default:
+		t.Errorf("expected Run to have returned")
+	}
+}
+
+func TestWriter_Write_NonBlocking(t *testing.T) {
+	w, err := NewWriter(WithNonBlocking(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w.bufferSize = 1
+
+	if _, err := w.Write([]byte("[1] ENABLE_NOTIFICATIONS")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write([]byte("[2] ENABLE_NOTIFICATIONS")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrQueueFull)
+	}
+}
+
+func TestWriter_Spool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	spoolDir := filepath.Join(dir, "spool")
+	filename := filepath.Join(dir, "nagios.cmd")
+
+	// nagios is down, so the commands stay in the spool.
+	w, err := NewWriter(WithFilename(filename), WithSpool(spoolDir, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, cmd := range []string{"[1] ENABLE_NOTIFICATIONS", "[2] DISABLE_NOTIFICATIONS"} {
+		if _, err := w.Write([]byte(cmd)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// once nagios is back, the spooled commands are replayed.
+	if err := ioutil.WriteFile(filename, nil, 0644); err != nil {
+		t.Fatalf("unable to create command file: %s", err)
+	}
+
+	w, err = NewWriter(WithFilename(filename), WithSpool(spoolDir, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	go w.Run(context.Background())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("unable to read command file: %s", err)
+	}
+
+	expected := "[1] ENABLE_NOTIFICATIONS\n[2] DISABLE_NOTIFICATIONS\n"
+	if string(contents) != expected {
+		t.Errorf("unexpected commands, got: '%s', want: '%s'", contents, expected)
+	}
+
+	journal, err := ioutil.ReadFile(filepath.Join(spoolDir, SpoolFile))
+	if err != nil {
+		t.Fatalf("unable to read spool: %s", err)
+	}
+	if len(journal) != 0 {
+		t.Errorf("expected spool to be truncated, got: '%s'", journal)
+	}
+}
+
+func TestWriter_Spool_DropOldest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewWriter(
+		WithSpool(dir, 0),
+		WithBufferSize(2),
+		WithOverflowPolicy(OverflowDropOldest),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, cmd := range []string{"[1] A", "[2] B", "[3] C"} {
+		if _, err := w.Write([]byte(cmd)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	pending, err := readSpool(filepath.Join(dir, SpoolFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(pending) != 2 || pending[0].cmd != "[2] B" || pending[1].cmd != "[3] C" {
+		t.Errorf("unexpected spooled commands, got: '%v'", pending)
+	}
+}
+
+func TestWriter_DropOldest_InFlight(t *testing.T) {
+	w, err := NewWriter(WithBufferSize(2), WithOverflowPolicy(OverflowDropOldest))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the batch peeked by Run is in flight until its write to the blocked
+	// fifo returns.
+	if _, err := w.Write([]byte("[1] A")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, ok := w.peek()
+	if !ok || len(b) != 1 {
+		t.Fatalf("unexpected batch, got: '%v'", b)
+	}
+
+	for _, cmd := range []string{"[2] B", "[3] C"} {
+		if _, err := w.Write([]byte(cmd)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// the command being written is kept, and the next oldest dropped.
+	letters := w.DeadLetters()
+	if len(letters) != 1 || letters[0].Command != "[2] B" {
+		t.Errorf("unexpected dead letters, got: '%v'", letters)
+	}
+
+	w.pop(b)
+	if len(w.queue) != 1 || w.queue[0].cmd != "[3] C" {
+		t.Errorf("unexpected queue, got: '%v'", w.queue)
+	}
+
+	// nothing can be dropped while every queued command is being written.
+	if _, err := w.Write([]byte("[4] D")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if b, ok = w.peek(); !ok || len(b) != 2 {
+		t.Fatalf("unexpected batch, got: '%v'", b)
+	}
+	if _, err := w.Write([]byte("[5] E")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrQueueFull)
+	}
+}
+
+func TestWriter_Spool_MaxBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewWriter(WithSpool(dir, 10), WithOverflowPolicy(OverflowReject))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write([]byte("[1] ABCDE")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write([]byte("[2] ABCDE")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrQueueFull)
+	}
+}
+
+func TestReadSpool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	journal := "+1 100 [1] A\n+2 200 [2] B;x y\n-1\n+3 300 [3] C\n+4 400 [4] partial"
+	path := filepath.Join(dir, SpoolFile)
+	if err := ioutil.WriteFile(path, []byte(journal), 0644); err != nil {
+		t.Fatalf("unable to write spool: %s", err)
+	}
+
+	pending, err := readSpool(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []entry{
+		{seq: 2, cmd: "[2] B;x y", queued: time.Unix(200, 0)},
+		{seq: 3, cmd: "[3] C", queued: time.Unix(300, 0)},
+	}
+	if len(pending) != len(expected) {
+		t.Fatalf("unexpected spooled commands, got: '%v', want: '%v'", pending, expected)
+	}
+	for i := range expected {
+		if pending[i] != expected[i] {
+			t.Errorf("unexpected spooled command, got: '%v', want: '%v'", pending[i], expected[i])
+		}
+	}
+}
+
+func TestWriter_DeadLetter_Expired(t *testing.T) {
+	w, err := NewWriter(
+		WithFilename(filepath.Join(os.TempDir(), "does-not-exist", "nagios.cmd")),
+		WithRetryPolicy(RetryPolicy{
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			MaxAge:         time.Millisecond,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write([]byte("[1] ENABLE_NOTIFICATIONS")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	go w.Run(context.Background())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(w.DeadLetters()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	letters := w.DeadLetters()
+	if len(letters) != 1 {
+		t.Fatalf("unexpected dead letters, got: '%v'", letters)
+	}
+	if letters[0].Command != "[1] ENABLE_NOTIFICATIONS" || letters[0].Reason != "expired" {
+		t.Errorf("unexpected dead letter, got: '%v'", letters[0])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Close(ctx); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestDeadLetterBuffer(t *testing.T) {
+	b := NewDeadLetterBuffer(2)
+	for _, cmd := range []string{"A", "B", "C"} {
+		b.Add(DeadLetter{Command: cmd})
+	}
+
+	letters := b.List()
+	if len(letters) != 2 || letters[0].Command != "B" || letters[1].Command != "C" {
+		t.Errorf("unexpected dead letters, got: '%v'", letters)
+	}
+}
+
+func TestWriter_Write_TooLong(t *testing.T) {
+	w, err := NewWriter()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write(bytes.Repeat([]byte("a"), PipeBuf-1)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if _, err := w.Write(bytes.Repeat([]byte("a"), PipeBuf)); !errors.Is(err, ErrCommandTooLong) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrCommandTooLong)
+	}
+}
+
+func TestWriter_Spool_TooLong(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	long := "[2] " + strings.Repeat("a", PipeBuf)
+	journal := "+1 100 [1] A\n+2 200 " + long + "\n+3 300 [3] C\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, SpoolFile), []byte(journal), 0644); err != nil {
+		t.Fatalf("unable to write spool: %s", err)
+	}
+
+	w, err := NewWriter(WithSpool(dir, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	letters := w.DeadLetters()
+	if len(letters) != 1 || letters[0].Command != long || letters[0].Reason != ErrCommandTooLong.Error() {
+		t.Errorf("unexpected dead letters, got: '%v'", letters)
+	}
+
+	b, ok := w.peek()
+	if !ok || len(b) != 2 || b[0].cmd != "[1] A" || b[1].cmd != "[3] C" {
+		t.Errorf("unexpected batch, got: '%v'", b)
+	}
+
+	pending, err := readSpool(filepath.Join(dir, SpoolFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("unexpected spooled commands, got: '%v'", pending)
+	}
+}
+
+func TestWriter_Peek_TooLong(t *testing.T) {
+	w, err := NewWriter()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// commands are length checked when written, so the queue is set up
+	// directly.
+	w.queue = []entry{{seq: 1, cmd: strings.Repeat("a", PipeBuf)}}
+
+	if b, ok := w.peek(); ok {
+		t.Errorf("unexpected batch, got: '%v'", b)
+	}
+	if len(w.DeadLetters()) != 1 {
+		t.Errorf("unexpected dead letters, got: '%v'", w.DeadLetters())
+	}
+}
+
+func TestWriter_Batch(t *testing.T) {
+	w, err := NewWriter()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// each command is 1000 bytes with its new-line, so 4 fit in a batch.
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write(bytes.Repeat([]byte("a"), 999)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := []int{4, 4, 2}
+	for _, n := range expected {
+		b, ok := w.peek()
+		if !ok {
+			t.Fatalf("expected batch")
+		}
+		if len(b) != n || len(b.bytes()) > PipeBuf {
+			t.Errorf("unexpected batch, got: '%d' commands, '%d' bytes, want: '%d' commands", len(b), len(b.bytes()), n)
+		}
+		w.pop(b)
+	}
+
+	if _, ok := w.peek(); ok {
+		t.Errorf("expected empty queue")
+	}
+}
+
+func TestWriter_Stats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewWriter(
+		WithName("primary"),
+		WithFilename(filepath.Join(dir, "missing", "nagios.cmd")),
+		WithBufferSize(2),
+		WithOverflowPolicy(OverflowReject),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, cmd := range []string{"[1] ENABLE_NOTIFICATIONS", "[2] DISABLE_NOTIFICATIONS", "[3] ENABLE_NOTIFICATIONS"} {
+		w.Write([]byte(cmd))
+	}
+
+	b, _ := w.peek()
+	w.fail(b, errors.New("broken pipe"))
+	w.setError(errors.New("broken pipe"))
+
+	stats := w.Stats()
+	if stats.Target != "primary" || stats.Enqueued != 2 || stats.Rejected != 1 || stats.Failed != 2 || stats.Retried != 2 {
+		t.Errorf("unexpected counters, got: '%+v'", stats)
+	}
+	if stats.QueueDepth != 2 || stats.OldestAge <= 0 || stats.Healthy || stats.Open || stats.LastError != "broken pipe" {
+		t.Errorf("unexpected state, got: '%+v'", stats)
+	}
+
+	b, _ = w.peek()
+	w.pop(b)
+
+	stats = w.Stats()
+	if stats.Written != 2 || stats.QueueDepth != 0 || stats.OldestAge != 0 || !stats.Healthy || stats.LastWrite.IsZero() {
+		t.Errorf("unexpected stats after write, got: '%+v'", stats)
+	}
+}
+
+func TestWriter_Coalesce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	spoolDir := filepath.Join(dir, "spool")
+	w, err := NewWriter(WithCoalescing(true), WithSpool(spoolDir, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, cmd := range []string{
+		"[1] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;2;down",
+		"[1] PROCESS_SERVICE_CHECK_RESULT;web01;SSH;0;up",
+		"[1] ENABLE_NOTIFICATIONS",
+		"[2] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;0;up",
+		"[2] PROCESS_HOST_CHECK_RESULT;web01;0;up",
+		"[3] PROCESS_HOST_CHECK_RESULT;web01;1;down",
+		"[3] ENABLE_NOTIFICATIONS",
+	} {
+		if _, err := w.Write([]byte(cmd)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	expected := "[2] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;0;up\n" +
+		"[1] PROCESS_SERVICE_CHECK_RESULT;web01;SSH;0;up\n" +
+		"[1] ENABLE_NOTIFICATIONS\n" +
+		"[3] PROCESS_HOST_CHECK_RESULT;web01;1;down\n" +
+		"[3] ENABLE_NOTIFICATIONS\n"
+
+	// the replacements are replayed from the spool in place of the
+	// commands they replaced.
+	w.spool.close()
+	w, err = NewWriter(WithCoalescing(true), WithSpool(spoolDir, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	b, ok := w.peek()
+	if !ok {
+		t.Fatalf("expected batch")
+	}
+	if string(b.bytes()) != expected {
+		t.Errorf("unexpected commands, got: '%s', want: '%s'", b.bytes(), expected)
+	}
+
+	// commands which are being written are not replaced.
+	if _, err := w.Write([]byte("[4] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;1;slow")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w.pop(b)
+
+	b, ok = w.peek()
+	if !ok || string(b.bytes()) != "[4] PROCESS_SERVICE_CHECK_RESULT;web01;HTTP;1;slow\n" {
+		t.Errorf("unexpected commands, got: '%s'", b.bytes())
+	}
+}
@@ -0,0 +1,156 @@
+package history
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// archiveLayout is the time layout nagios uses to name rotated log files, e.g.
+// nagios-08-17-2020-00.log.
+const archiveLayout = "nagios-01-02-2006-15.log"
+
+// Archive locates the nagios log file and its rotated archives.
+type Archive struct {
+	// LogFile is the path to the current nagios.log.
+	LogFile string
+
+	// ArchivePath is the directory nagios rotates logs into. When empty,
+	// only LogFile is read.
+	ArchivePath string
+
+	// Location is used to interpret the times in archive filenames, which
+	// nagios writes in local time.
+	Location *time.Location
+}
+
+type archiveFile struct {
+	name    string
+	rotated time.Time
+}
+
+// Files returns the log files, in chronological order, needed to construct
+// the state history between start and end.
+//
+// An archive is named after the time it was rotated, and so contains events
+// from before that time. Nagios logs the current state of every host and
+// service at the start of each file, so the first file rotated after start
+// is enough to establish the initial states.
+func (a *Archive) Files(start, end time.Time) ([]string, error) {
+	archives, err := a.archives()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, f := range archives {
+		if !f.rotated.After(start) {
+			continue
+		}
+
+		files = append(files, f.name)
+		if !f.rotated.Before(end) {
+			return files, nil
+		}
+	}
+
+	if a.LogFile != "" {
+		files = append(files, a.LogFile)
+	}
+
+	return files, nil
+}
+
+func (a *Archive) archives() ([]archiveFile, error) {
+	if a.ArchivePath == "" {
+		return nil, nil
+	}
+
+	loc := a.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	entries, err := ioutil.ReadDir(a.ArchivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]archiveFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		rotated, err := time.ParseInLocation(archiveLayout, entry.Name(), loc)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, archiveFile{
+			name:    filepath.Join(a.ArchivePath, entry.Name()),
+			rotated: rotated,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].rotated.Before(files[j].rotated)
+	})
+
+	return files, nil
+}
+
+// Events reads every event from the log files needed to construct the state
+// history between start and end.
+//
+// Events from before start are included, so the state at the start of the
+// window can be determined. Events after end are discarded.
+func (a *Archive) Events(start, end time.Time) ([]Event, error) {
+	files, err := a.Files(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0)
+	for _, filename := range files {
+		events, err = readEvents(filename, end.Unix(), events)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+
+	return events, nil
+}
+
+func readEvents(filename string, end int64, events []Event) ([]Event, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return events, err
+	}
+	defer f.Close()
+
+	dec := NewDecoder(f)
+	dec.IgnoreInvalidLines = true
+	for {
+		var e Event
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+
+		if e.Time > end {
+			continue
+		}
+		events = append(events, e)
+	}
+}
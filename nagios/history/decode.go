@@ -0,0 +1,215 @@
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+// Decoder reads state history events from a nagios log file.
+type Decoder struct {
+	// When set, IgnoreInvalidLines will not cause a decode error if a
+	// recognised entry cannot be parsed.
+	IgnoreInvalidLines bool
+
+	r *bufio.Reader
+}
+
+var (
+	reLine         = regexp.MustCompile(`^\[(\d+)\]\s+(.*?)\s*$`)
+	reProgramStart = regexp.MustCompile(`^Nagios \S+ starting\.\.\.`)
+	reProgramEnd   = regexp.MustCompile(`^Caught SIG\w+, shutting down\.\.\.`)
+)
+
+// NewDecoder takes a reader and returns a Decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Decode reads the next event from the log into e.
+//
+// Lines which do not describe a state change, scheduled downtime, or
+// program start or end are skipped. io.EOF is returned when there are no
+// more events.
+func (dec *Decoder) Decode(e *Event) error {
+	for {
+		line, err := dec.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return err
+		}
+
+		ok, perr := parseLine(line, e)
+		if perr != nil && !dec.IgnoreInvalidLines {
+			return perr
+		}
+		if ok && perr == nil {
+			return nil
+		}
+
+		if err == io.EOF {
+			return io.EOF
+		}
+	}
+}
+
+func parseLine(line string, e *Event) (bool, error) {
+	m := reLine.FindStringSubmatch(line)
+	if len(m) != 3 {
+		return false, nil
+	}
+
+	ts, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid timestamp '%s'", m[1])
+	}
+
+	*e = Event{Time: ts}
+	msg := m[2]
+
+	if reProgramStart.MatchString(msg) {
+		e.Type = ProgramStart
+		return true, nil
+	}
+
+	if reProgramEnd.MatchString(msg) {
+		e.Type = ProgramEnd
+		return true, nil
+	}
+
+	sep := strings.Index(msg, ": ")
+	if sep == -1 {
+		return false, nil
+	}
+	kind, args := msg[:sep], msg[sep+2:]
+
+	switch kind {
+	case "HOST ALERT", "INITIAL HOST STATE", "CURRENT HOST STATE":
+		return true, parseHostState(args, e)
+	case "SERVICE ALERT", "INITIAL SERVICE STATE", "CURRENT SERVICE STATE":
+		return true, parseServiceState(args, e)
+	case "HOST DOWNTIME ALERT":
+		return true, parseHostDowntime(args, e)
+	case "SERVICE DOWNTIME ALERT":
+		return true, parseServiceDowntime(args, e)
+	default:
+		return false, nil
+	}
+}
+
+// HOST ALERT: host;STATE;STATE TYPE;attempt;output
+func parseHostState(args string, e *Event) error {
+	f := strings.SplitN(args, ";", 5)
+	if len(f) < 3 {
+		return fmt.Errorf("invalid host state entry '%s'", args)
+	}
+
+	st, err := xdata.ParseHostState([]byte(f[1]))
+	if err != nil {
+		return fmt.Errorf("invalid host state '%s': %w", f[1], err)
+	}
+
+	stateType, err := xdata.ParseStateType([]byte(f[2]))
+	if err != nil {
+		return fmt.Errorf("invalid state type '%s': %w", f[2], err)
+	}
+
+	e.Type = StateChange
+	e.HostName = f[0]
+	e.State = int(st)
+	e.StateType = stateType
+	if len(f) == 5 {
+		e.Output = f[4]
+	}
+
+	return nil
+}
+
+// SERVICE ALERT: host;service;STATE;STATE TYPE;attempt;output
+func parseServiceState(args string, e *Event) error {
+	f := strings.SplitN(args, ";", 6)
+	if len(f) < 4 {
+		return fmt.Errorf("invalid service state entry '%s'", args)
+	}
+
+	st, err := xdata.ParseServiceState([]byte(f[2]))
+	if err != nil {
+		return fmt.Errorf("invalid service state '%s': %w", f[2], err)
+	}
+
+	stateType, err := xdata.ParseStateType([]byte(f[3]))
+	if err != nil {
+		return fmt.Errorf("invalid state type '%s': %w", f[3], err)
+	}
+
+	e.Type = StateChange
+	e.HostName = f[0]
+	e.ServiceDescription = f[1]
+	e.State = int(st)
+	e.StateType = stateType
+	if len(f) == 6 {
+		e.Output = f[5]
+	}
+
+	return nil
+}
+
+// HOST DOWNTIME ALERT: host;STARTED;comment
+func parseHostDowntime(args string, e *Event) error {
+	f := strings.SplitN(args, ";", 3)
+	if len(f) < 2 {
+		return fmt.Errorf("invalid host downtime entry '%s'", args)
+	}
+
+	t, err := parseDowntimeType(f[1])
+	if err != nil {
+		return err
+	}
+
+	e.Type = t
+	e.HostName = f[0]
+	if len(f) == 3 {
+		e.Output = strings.TrimSpace(f[2])
+	}
+
+	return nil
+}
+
+// SERVICE DOWNTIME ALERT: host;service;STARTED;comment
+func parseServiceDowntime(args string, e *Event) error {
+	f := strings.SplitN(args, ";", 4)
+	if len(f) < 3 {
+		return fmt.Errorf("invalid service downtime entry '%s'", args)
+	}
+
+	t, err := parseDowntimeType(f[2])
+	if err != nil {
+		return err
+	}
+
+	e.Type = t
+	e.HostName = f[0]
+	e.ServiceDescription = f[1]
+	if len(f) == 4 {
+		e.Output = strings.TrimSpace(f[3])
+	}
+
+	return nil
+}
+
+func parseDowntimeType(s string) (EventType, error) {
+	switch s {
+	case "STARTED":
+		return DowntimeStart, nil
+	case "STOPPED", "CANCELLED":
+		return DowntimeEnd, nil
+	default:
+		return StateChange, fmt.Errorf("invalid downtime type '%s'", s)
+	}
+}
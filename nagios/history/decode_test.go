@@ -0,0 +1,74 @@
+package history
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+const sampleLog = `[1597622400] LOG ROTATION: DAILY
+[1597622400] LOG VERSION: 2.0
+[1597622400] CURRENT HOST STATE: host1;UP;HARD;1;PING OK
+[1597622400] CURRENT SERVICE STATE: host1;service 1;OK;HARD;1;OK - all good
+[1597622500] SERVICE ALERT: host1;service 1;CRITICAL;SOFT;1;CRITICAL - disk full; really
+[1597622600] HOST ALERT: host1;DOWN;HARD;3;PING CRITICAL
+[1597622700] HOST DOWNTIME ALERT: host1;STARTED; Host has entered a period of scheduled downtime
+[1597622800] SERVICE DOWNTIME ALERT: host1;service 1;CANCELLED; Scheduled downtime for service has been cancelled.
+[1597622900] Caught SIGTERM, shutting down...
+[1597623000] Nagios 4.4.6 starting... (PID=1234)
+[1597623100] EXTERNAL COMMAND: PROCESS_SERVICE_CHECK_RESULT;host1;service 1;0;OK`
+
+func TestDecoder_Decode(t *testing.T) {
+	expected := []Event{
+		{Time: 1597622400, Type: StateChange, HostName: "host1", State: int(xdata.Up), StateType: xdata.Hard, Output: "PING OK"},
+		{Time: 1597622400, Type: StateChange, HostName: "host1", ServiceDescription: "service 1", State: int(xdata.Ok), StateType: xdata.Hard, Output: "OK - all good"},
+		{Time: 1597622500, Type: StateChange, HostName: "host1", ServiceDescription: "service 1", State: int(xdata.Critical), StateType: xdata.Soft, Output: "CRITICAL - disk full; really"},
+		{Time: 1597622600, Type: StateChange, HostName: "host1", State: int(xdata.Down), StateType: xdata.Hard, Output: "PING CRITICAL"},
+		{Time: 1597622700, Type: DowntimeStart, HostName: "host1", Output: "Host has entered a period of scheduled downtime"},
+		{Time: 1597622800, Type: DowntimeEnd, HostName: "host1", ServiceDescription: "service 1", Output: "Scheduled downtime for service has been cancelled."},
+		{Time: 1597622900, Type: ProgramEnd},
+		{Time: 1597623000, Type: ProgramStart},
+	}
+
+	dec := NewDecoder(strings.NewReader(sampleLog))
+	for i, want := range expected {
+		var got Event
+		if err := dec.Decode(&got); err != nil {
+			t.Errorf("unable to decode event %d: %s", i, err)
+			return
+		}
+
+		if got != want {
+			t.Errorf("incorrect event %d, got: %+v, want: %+v", i, got, want)
+		}
+	}
+
+	var e Event
+	if err := dec.Decode(&e); err != io.EOF {
+		t.Errorf("expected EOF, got: %v (%+v)", err, e)
+	}
+}
+
+func TestDecoder_Decode_InvalidLine(t *testing.T) {
+	const input = "[1597622500] SERVICE ALERT: host1;service 1;BROKEN;HARD;1;output\n" +
+		"[1597622600] HOST ALERT: host1;DOWN;HARD;3;PING CRITICAL\n"
+
+	dec := NewDecoder(strings.NewReader(input))
+	var e Event
+	if err := dec.Decode(&e); err == nil {
+		t.Errorf("expected error")
+	}
+
+	dec = NewDecoder(strings.NewReader(input))
+	dec.IgnoreInvalidLines = true
+	if err := dec.Decode(&e); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if e.Type != StateChange || e.HostName != "host1" || e.State != int(xdata.Down) {
+		t.Errorf("unexpected event, got: %+v", e)
+	}
+}
@@ -0,0 +1,3 @@
+// History provides routines for reading state history from the nagios log
+// file and its archives.
+package history
@@ -0,0 +1,75 @@
+package history
+
+import (
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+)
+
+// EventType identifies the kind of entry read from the nagios log.
+type EventType int
+
+const (
+	// StateChange is logged for host and service alerts, as well as the
+	// initial and current states logged on startup and log rotation.
+	StateChange EventType = iota
+
+	// DowntimeStart is logged when a host or service enters scheduled downtime.
+	DowntimeStart
+
+	// DowntimeEnd is logged when scheduled downtime expires or is cancelled.
+	DowntimeEnd
+
+	// ProgramStart is logged when nagios starts.
+	ProgramStart
+
+	// ProgramEnd is logged when nagios shuts down.
+	ProgramEnd
+)
+
+// String returns a string representation of the EventType.
+//
+// An empty string is returned for unknown types.
+func (t EventType) String() string {
+	switch t {
+	case StateChange:
+		return "STATE CHANGE"
+	case DowntimeStart:
+		return "DOWNTIME START"
+	case DowntimeEnd:
+		return "DOWNTIME END"
+	case ProgramStart:
+		return "PROGRAM START"
+	case ProgramEnd:
+		return "PROGRAM END"
+	default:
+		return ""
+	}
+}
+
+// Event represents a single line of interest in the nagios log.
+//
+// Host events have an empty ServiceDescription. Program events have neither
+// a HostName nor a ServiceDescription.
+type Event struct {
+	Time               int64
+	Type               EventType
+	HostName           string
+	ServiceDescription string
+
+	// State holds either an xdata.HostState or an xdata.ServiceState,
+	// depending on whether the event refers to a host or a service. It is
+	// only set for StateChange events.
+	State     int
+	StateType xdata.StateType
+	Output    string
+}
+
+// IsHostEvent returns whether the event refers to a host, rather than a
+// service or the nagios process itself.
+func (e *Event) IsHostEvent() bool {
+	return e.HostName != "" && e.ServiceDescription == ""
+}
+
+// IsServiceEvent returns whether the event refers to a service.
+func (e *Event) IsServiceEvent() bool {
+	return e.ServiceDescription != ""
+}
@@ -0,0 +1,186 @@
+package nsca
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EncryptionMethod is the method used to encrypt data packets. The values
+// match the encryption_method numbers used by send_nsca.
+type EncryptionMethod int
+
+const (
+	EncryptNone        EncryptionMethod = 0
+	EncryptXOR         EncryptionMethod = 1
+	EncryptDES         EncryptionMethod = 2
+	EncryptTripleDES   EncryptionMethod = 3
+	EncryptRijndael128 EncryptionMethod = 14
+)
+
+// ParseEncryptionMethod converts the name or number of an encryption method,
+// as used in send_nsca.cfg, into an EncryptionMethod.
+//
+// Only the methods which can be implemented with the standard library are
+// supported.
+func ParseEncryptionMethod(s string) (EncryptionMethod, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if n, err := strconv.Atoi(s); err == nil {
+		m := EncryptionMethod(n)
+		if m.String() == "" {
+			return EncryptNone, fmt.Errorf("unsupported encryption method %d", n)
+		}
+		return m, nil
+	}
+
+	switch s {
+	case "none":
+		return EncryptNone, nil
+	case "xor":
+		return EncryptXOR, nil
+	case "des":
+		return EncryptDES, nil
+	case "3des", "tripledes":
+		return EncryptTripleDES, nil
+	case "rijndael-128", "aes":
+		return EncryptRijndael128, nil
+	default:
+		return EncryptNone, fmt.Errorf("unsupported encryption method '%s'", s)
+	}
+}
+
+// String returns the name of the EncryptionMethod.
+//
+// An empty string is returned for unsupported methods.
+func (m EncryptionMethod) String() string {
+	switch m {
+	case EncryptNone:
+		return "none"
+	case EncryptXOR:
+		return "xor"
+	case EncryptDES:
+		return "des"
+	case EncryptTripleDES:
+		return "3des"
+	case EncryptRijndael128:
+		return "rijndael-128"
+	default:
+		return ""
+	}
+}
+
+// crypter decrypts the data packets of a single connection.
+type crypter interface {
+	decrypt(b []byte)
+}
+
+// newCrypter constructs a crypter for a connection, using the IV sent in
+// its init packet.
+//
+// The block ciphers follow libmcrypt, which send_nsca uses: the password is
+// truncated or zero padded to the key size of the cipher, and the start of
+// the IV is used with 8-bit CFB mode.
+func newCrypter(m EncryptionMethod, password string, iv []byte) (crypter, error) {
+	var (
+		block cipher.Block
+		err   error
+	)
+
+	switch m {
+	case EncryptNone:
+		return noCrypter{}, nil
+	case EncryptXOR:
+		return &xorCrypter{iv: iv, password: []byte(password)}, nil
+	case EncryptDES:
+		block, err = des.NewCipher(key(password, 8))
+	case EncryptTripleDES:
+		block, err = des.NewTripleDESCipher(key(password, 24))
+	case EncryptRijndael128:
+		block, err = aes.NewCipher(key(password, 32))
+	default:
+		return nil, fmt.Errorf("unsupported encryption method %d", m)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newCFB8(block, iv[:block.BlockSize()], true), nil
+}
+
+// key truncates or zero pads the password to size bytes.
+func key(password string, size int) []byte {
+	k := make([]byte, size)
+	copy(k, password)
+	return k
+}
+
+type noCrypter struct{}
+
+func (noCrypter) decrypt(b []byte) {}
+
+// xorCrypter XORs each packet with the IV, then with the password, from the
+// start of the packet.
+type xorCrypter struct {
+	iv       []byte
+	password []byte
+}
+
+func (c *xorCrypter) decrypt(b []byte) {
+	for i := range b {
+		b[i] ^= c.iv[i%len(c.iv)]
+	}
+
+	if len(c.password) == 0 {
+		return
+	}
+	for i := range b {
+		b[i] ^= c.password[i%len(c.password)]
+	}
+}
+
+// cfb8 is the 8-bit cipher feedback mode, where the feedback register is
+// shifted one byte at a time. Unlike the XOR method, its state carries on
+// from one packet to the next.
+type cfb8 struct {
+	block    cipher.Block
+	register []byte
+	out      []byte
+	decrypts bool
+}
+
+func newCFB8(block cipher.Block, iv []byte, decrypts bool) *cfb8 {
+	register := make([]byte, len(iv))
+	copy(register, iv)
+
+	return &cfb8{
+		block:    block,
+		register: register,
+		out:      make([]byte, len(iv)),
+		decrypts: decrypts,
+	}
+}
+
+func (c *cfb8) decrypt(b []byte) {
+	c.xorKeyStream(b)
+}
+
+func (c *cfb8) xorKeyStream(b []byte) {
+	for i := range b {
+		c.block.Encrypt(c.out, c.register)
+
+		in := b[i]
+		b[i] ^= c.out[0]
+
+		// the register is fed with the cipher text.
+		feedback := b[i]
+		if c.decrypts {
+			feedback = in
+		}
+		copy(c.register, c.register[1:])
+		c.register[len(c.register)-1] = feedback
+	}
+}
@@ -0,0 +1,85 @@
+package nsca
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseEncryptionMethod(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected EncryptionMethod
+	}{
+		{"0", EncryptNone},
+		{"none", EncryptNone},
+		{"1", EncryptXOR},
+		{"XOR", EncryptXOR},
+		{"2", EncryptDES},
+		{"3des", EncryptTripleDES},
+		{"14", EncryptRijndael128},
+		{"aes", EncryptRijndael128},
+	}
+
+	for _, test := range tests {
+		m, err := ParseEncryptionMethod(test.input)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %s", test.input, err)
+		}
+		if m != test.expected {
+			t.Errorf("unexpected method, got: '%s', want: '%s'", m, test.expected)
+		}
+	}
+
+	for _, input := range []string{"8", "blowfish", ""} {
+		if _, err := ParseEncryptionMethod(input); err == nil {
+			t.Errorf("expected error for '%s'", input)
+		}
+	}
+}
+
+// TestCFB8 checks the CFB8-AES128 vector from NIST SP 800-38A.
+func TestCFB8(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	iv, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	plain, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d")
+	expected, _ := hex.DecodeString("3b79424c9c0dd436bace9e0ed4586a4f32b9")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	b := append([]byte(nil), plain...)
+	newCFB8(block, iv, false).xorKeyStream(b)
+	if !bytes.Equal(b, expected) {
+		t.Errorf("unexpected cipher text, got: '%x', want: '%x'", b, expected)
+	}
+
+	// decrypting in two parts checks the state carries on.
+	dec := newCFB8(block, iv, true)
+	dec.decrypt(b[:5])
+	dec.decrypt(b[5:])
+	if !bytes.Equal(b, plain) {
+		t.Errorf("unexpected plain text, got: '%x', want: '%x'", b, plain)
+	}
+}
+
+func TestXORCrypter(t *testing.T) {
+	iv := bytes.Repeat([]byte{0x5a}, IVSize)
+	c := &xorCrypter{iv: iv, password: []byte("secret")}
+
+	plain := []byte("a packet which is longer than the password")
+	b := append([]byte(nil), plain...)
+
+	// XOR is its own inverse, and starts again with each packet.
+	c.decrypt(b)
+	if bytes.Equal(b, plain) {
+		t.Fatalf("expected packet to be changed")
+	}
+	c.decrypt(b)
+	if !bytes.Equal(b, plain) {
+		t.Errorf("unexpected plain text, got: '%s', want: '%s'", b, plain)
+	}
+}
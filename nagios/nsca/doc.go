@@ -0,0 +1,7 @@
+// Nsca provides a server for the NSCA v2 protocol, which send_nsca uses to
+// submit passive check results.
+//
+// Each connection is sent an init packet, holding an IV and a timestamp,
+// after which the client sends any number of fixed size data packets,
+// encrypted with the IV and a shared password.
+package nsca
@@ -0,0 +1,92 @@
+package nsca
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+const (
+	// IVSize is the size of the IV sent in the init packet.
+	IVSize = 128
+
+	// InitPacketSize is the size of the init packet, the IV followed by a
+	// 32-bit timestamp.
+	InitPacketSize = IVSize + 4
+
+	// PacketVersion is the version of the data packets sent by NSCA v2
+	// clients.
+	PacketVersion = 3
+
+	// DefaultOutputLength is the size of the plugin output of a data packet
+	// sent by send_nsca 2.7. send_nsca 2.9 uses 4096.
+	DefaultOutputLength = 512
+
+	hostnameLength    = 64
+	descriptionLength = 128
+
+	// headerSize is the size of the fields before the host name, including
+	// the padding after the packet version.
+	headerSize = 14
+)
+
+// ErrInvalidPacket is returned when a data packet can not be decoded.
+var ErrInvalidPacket = errors.New("invalid packet")
+
+// Packet is a passive check result, decoded from a data packet. Host check
+// results have an empty ServiceName.
+type Packet struct {
+	Timestamp   time.Time
+	ReturnCode  int
+	Hostname    string
+	ServiceName string
+	Output      string
+}
+
+// PacketSize returns the size of a data packet with the given plugin output
+// length, including the padding added by the C compiler.
+func PacketSize(outputLength int) int {
+	n := headerSize + hostnameLength + descriptionLength + outputLength
+	return (n + 3) &^ 3
+}
+
+// Decode decodes a decrypted data packet, checking its version and CRC32.
+func Decode(b []byte, outputLength int) (Packet, error) {
+	if len(b) != PacketSize(outputLength) {
+		return Packet{}, fmt.Errorf("%w: size %d, expected %d", ErrInvalidPacket, len(b), PacketSize(outputLength))
+	}
+
+	if v := int16(binary.BigEndian.Uint16(b[0:])); v != PacketVersion {
+		return Packet{}, fmt.Errorf("%w: version %d", ErrInvalidPacket, v)
+	}
+
+	// the CRC32 is calculated with its own field zeroed.
+	crc := binary.BigEndian.Uint32(b[4:])
+	data := make([]byte, len(b))
+	copy(data, b)
+	copy(data[4:8], []byte{0, 0, 0, 0})
+	if crc32.ChecksumIEEE(data) != crc {
+		return Packet{}, fmt.Errorf("%w: CRC32 mismatch, wrong password or encryption method?", ErrInvalidPacket)
+	}
+
+	offset := headerSize
+	field := func(n int) string {
+		f := b[offset : offset+n]
+		offset += n
+		if i := bytes.IndexByte(f, 0); i >= 0 {
+			f = f[:i]
+		}
+		return string(f)
+	}
+
+	return Packet{
+		Timestamp:   time.Unix(int64(binary.BigEndian.Uint32(b[8:])), 0),
+		ReturnCode:  int(int16(binary.BigEndian.Uint16(b[12:]))),
+		Hostname:    field(hostnameLength),
+		ServiceName: field(descriptionLength),
+		Output:      field(outputLength),
+	}, nil
+}
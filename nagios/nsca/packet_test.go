@@ -0,0 +1,95 @@
+package nsca
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+// encode encodes a data packet, as send_nsca does before encrypting it.
+func encode(p Packet, outputLength int) []byte {
+	b := make([]byte, PacketSize(outputLength))
+
+	binary.BigEndian.PutUint16(b[0:], PacketVersion)
+	binary.BigEndian.PutUint32(b[8:], uint32(p.Timestamp.Unix()))
+	binary.BigEndian.PutUint16(b[12:], uint16(int16(p.ReturnCode)))
+
+	offset := headerSize
+	for _, f := range []struct {
+		value string
+		n     int
+	}{{p.Hostname, hostnameLength}, {p.ServiceName, descriptionLength}, {p.Output, outputLength}} {
+		copy(b[offset:offset+f.n-1], f.value)
+		offset += f.n
+	}
+
+	binary.BigEndian.PutUint32(b[4:], crc32.ChecksumIEEE(b))
+
+	return b
+}
+
+func TestPacketSize(t *testing.T) {
+	tests := []struct {
+		outputLength int
+		expected     int
+	}{
+		{512, 720},
+		{4096, 4304},
+	}
+
+	for _, test := range tests {
+		if got := PacketSize(test.outputLength); got != test.expected {
+			t.Errorf("unexpected packet size, got: '%d', want: '%d'", got, test.expected)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	expected := Packet{
+		Timestamp:   time.Unix(1500000000, 0),
+		ReturnCode:  2,
+		Hostname:    "web1",
+		ServiceName: "HTTP",
+		Output:      "CRITICAL - connection refused",
+	}
+
+	for _, n := range []int{512, 4096} {
+		p, err := Decode(encode(expected, n), n)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if p != expected {
+			t.Errorf("unexpected packet, got: '%+v', want: '%+v'", p, expected)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	valid := func() []byte {
+		return encode(Packet{Hostname: "web1", Output: "up"}, DefaultOutputLength)
+	}
+
+	badCRC := valid()
+	badCRC[20] ^= 0xff
+
+	badVersion := valid()
+	binary.BigEndian.PutUint16(badVersion[0:], 2)
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"short", valid()[:100]},
+		{"crc", badCRC},
+		{"version", badVersion},
+	}
+
+	for _, test := range tests {
+		_, err := Decode(test.input, DefaultOutputLength)
+		if !errors.Is(err, ErrInvalidPacket) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, ErrInvalidPacket)
+		}
+	}
+}
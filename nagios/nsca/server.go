@@ -0,0 +1,326 @@
+package nsca
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMaxPacketAge is how far the timestamp of a data packet may be from
+// the current time, as in nsca.cfg.
+const DefaultMaxPacketAge = 30 * time.Second
+
+// DefaultTimeout is how long a connection may be idle before it is closed.
+const DefaultTimeout = time.Minute
+
+// ErrServerClosed is returned by Serve after the Server is shut down.
+var ErrServerClosed = errors.New("nsca: server closed")
+
+// Handler is called with each passive check result received by the Server.
+// Errors are logged, as NSCA has no way of reporting them to the client.
+type Handler func(p Packet) error
+
+// Server accepts passive check results from NSCA v2 clients, such as
+// send_nsca.
+type Server struct {
+	handler      Handler
+	method       EncryptionMethod
+	password     string
+	outputLength int
+	maxPacketAge time.Duration
+	timeout      time.Duration
+	logger       *zap.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer constructs an instance of Server.
+func NewServer(opts ...ServerOption) (*Server, error) {
+	s := Server{
+		method:       EncryptXOR,
+		outputLength: DefaultOutputLength,
+		maxPacketAge: DefaultMaxPacketAge,
+		timeout:      DefaultTimeout,
+		logger:       zap.NewNop(),
+		conns:        make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.handler == nil {
+		return nil, fmt.Errorf("must set handler")
+	}
+
+	return &s, nil
+}
+
+// ListenAndServe listens on the TCP address addr, then calls Serve.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on the listener, serving each in a new
+// goroutine. It blocks until the listener fails, or the Server is shut down,
+// when ErrServerClosed is returned.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Temporary() {
+				s.logger.Warn("unable to accept connection",
+					zap.Error(err),
+				)
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		go s.serve(conn)
+	}
+}
+
+// Shutdown stops accepting connections, then waits for the open
+// connections to finish until the context is done, when they are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	<-done
+	return ctx.Err()
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// track records an open connection, unless the Server is shut down.
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	s.wg.Done()
+}
+
+// serve sends the init packet, then reads data packets until the client
+// closes the connection.
+func (s *Server) serve(conn net.Conn) {
+	defer s.untrack(conn)
+	defer conn.Close()
+
+	logger := s.logger.With(
+		zap.String("remote", conn.RemoteAddr().String()),
+	)
+
+	init := make([]byte, InitPacketSize)
+	if _, err := rand.Read(init[:IVSize]); err != nil {
+		logger.Error("unable to generate IV",
+			zap.Error(err),
+		)
+		return
+	}
+	binary.BigEndian.PutUint32(init[IVSize:], uint32(time.Now().Unix()))
+
+	conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if _, err := conn.Write(init); err != nil {
+		logger.Warn("unable to send init packet",
+			zap.Error(err),
+		)
+		return
+	}
+
+	c, err := newCrypter(s.method, s.password, init[:IVSize])
+	if err != nil {
+		logger.Error("unable to set up decryption",
+			zap.Error(err),
+		)
+		return
+	}
+
+	buf := make([]byte, PacketSize(s.outputLength))
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.timeout))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			if err != io.EOF && !s.isClosed() {
+				logger.Warn("unable to read packet",
+					zap.Error(err),
+				)
+			}
+			return
+		}
+
+		c.decrypt(buf)
+		p, err := Decode(buf, s.outputLength)
+		if err != nil {
+			// the rest of the connection can not be decrypted either.
+			logger.Warn("closing connection",
+				zap.Error(err),
+			)
+			return
+		}
+
+		if s.maxPacketAge > 0 {
+			age := time.Since(p.Timestamp)
+			if age < 0 {
+				age = -age
+			}
+			if age > s.maxPacketAge {
+				logger.Warn("dropping packet outside of max packet age",
+					zap.String("host", p.Hostname),
+					zap.String("service", p.ServiceName),
+					zap.Duration("age", age),
+				)
+				continue
+			}
+		}
+
+		if err := s.handler(p); err != nil {
+			logger.Warn("unable to submit NSCA check result",
+				zap.String("host", p.Hostname),
+				zap.String("service", p.ServiceName),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// ServerOption passes parameters to NewServer().
+type ServerOption func(s *Server) error
+
+// WithHandler sets the function called with each passive check result.
+func WithHandler(h Handler) ServerOption {
+	return func(s *Server) error {
+		s.handler = h
+		return nil
+	}
+}
+
+// WithEncryption sets the encryption method and password, which must match
+// send_nsca.cfg.
+func WithEncryption(m EncryptionMethod, password string) ServerOption {
+	return func(s *Server) error {
+		if m.String() == "" {
+			return fmt.Errorf("unsupported encryption method %d", m)
+		}
+		s.method = m
+		s.password = password
+		return nil
+	}
+}
+
+// WithOutputLength sets the size of the plugin output in data packets, which
+// depends on the version of send_nsca.
+func WithOutputLength(n int) ServerOption {
+	return func(s *Server) error {
+		if n <= 0 {
+			return fmt.Errorf("output length must be positive")
+		}
+		s.outputLength = n
+		return nil
+	}
+}
+
+// WithMaxPacketAge sets how far the timestamp of a data packet may be from
+// the current time. Zero disables the check.
+func WithMaxPacketAge(d time.Duration) ServerOption {
+	return func(s *Server) error {
+		s.maxPacketAge = d
+		return nil
+	}
+}
+
+// WithTimeout sets how long a connection may be idle before it is closed.
+func WithTimeout(d time.Duration) ServerOption {
+	return func(s *Server) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+		s.timeout = d
+		return nil
+	}
+}
+
+// WithLogger passes in a zap Logger to NewServer().
+func WithLogger(l *zap.Logger) ServerOption {
+	return func(s *Server) error {
+		s.logger = l
+		return nil
+	}
+}
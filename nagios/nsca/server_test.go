@@ -0,0 +1,144 @@
+package nsca
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// sendNSCA stands in for send_nsca, sending each packet over one
+// connection.
+func sendNSCA(t *testing.T, addr string, m EncryptionMethod, password string, packets []Packet) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer conn.Close()
+
+	init := make([]byte, InitPacketSize)
+	if _, err := io.ReadFull(conn, init); err != nil {
+		t.Fatalf("unable to read init packet: %s", err)
+	}
+	iv := init[:IVSize]
+
+	var enc func([]byte)
+	switch m {
+	case EncryptXOR:
+		enc = (&xorCrypter{iv: iv, password: []byte(password)}).decrypt
+	case EncryptRijndael128:
+		block, _ := aes.NewCipher(key(password, 32))
+		enc = newCFB8(block, iv[:block.BlockSize()], false).xorKeyStream
+	default:
+		enc = func([]byte) {}
+	}
+
+	for _, p := range packets {
+		b := encode(p, DefaultOutputLength)
+		enc(b)
+		if _, err := conn.Write(b); err != nil {
+			t.Fatalf("unable to write packet: %s", err)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		method   EncryptionMethod
+		password string
+	}{
+		{EncryptNone, ""},
+		{EncryptXOR, "secret"},
+		{EncryptRijndael128, "secret"},
+	}
+
+	for _, test := range tests {
+		received := make(chan Packet, 10)
+		s, err := NewServer(
+			WithEncryption(test.method, test.password),
+			WithHandler(func(p Packet) error {
+				received <- p
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unable to listen: %s", err)
+		}
+
+		served := make(chan error, 1)
+		go func() {
+			served <- s.Serve(l)
+		}()
+
+		now := time.Unix(time.Now().Unix(), 0)
+		packets := []Packet{
+			{Timestamp: now, ReturnCode: 1, Hostname: "web1", ServiceName: "HTTP", Output: "WARNING - slow"},
+			{Timestamp: now.Add(-time.Hour), ReturnCode: 0, Hostname: "web1", ServiceName: "HTTP", Output: "too old"},
+			{Timestamp: now, ReturnCode: 1, Hostname: "web2", Output: "DOWN"},
+		}
+		sendNSCA(t, l.Addr().String(), test.method, test.password, packets)
+
+		for _, expected := range []Packet{packets[0], packets[2]} {
+			select {
+			case p := <-received:
+				if p != expected {
+					t.Errorf("%s: unexpected packet, got: '%+v', want: '%+v'", test.method, p, expected)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%s: timed out waiting for packet", test.method)
+			}
+		}
+
+		if err := s.Shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := <-served; !errors.Is(err, ErrServerClosed) {
+			t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrServerClosed)
+		}
+
+		select {
+		case p := <-received:
+			t.Errorf("%s: unexpected packet: '%+v'", test.method, p)
+		default:
+		}
+	}
+}
+
+func TestServer_WrongPassword(t *testing.T) {
+	received := make(chan Packet, 10)
+	s, err := NewServer(
+		WithEncryption(EncryptXOR, "secret"),
+		WithHandler(func(p Packet) error {
+			received <- p
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	go s.Serve(l)
+	defer s.Shutdown(context.Background())
+
+	sendNSCA(t, l.Addr().String(), EncryptXOR, "wrong", []Packet{
+		{Timestamp: time.Now(), Hostname: "web1", Output: "UP"},
+	})
+
+	select {
+	case p := <-received:
+		t.Errorf("unexpected packet: '%+v'", p)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,126 @@
+// Objects provides routines for reading group membership from the nagios
+// objects cache file.
+package objects
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ServiceRef identifies a service by its host name and service description.
+type ServiceRef struct {
+	HostName           string
+	ServiceDescription string
+}
+
+// Groups holds the host and service group memberships defined in the nagios
+// object configuration.
+type Groups struct {
+	HostGroups    map[string][]string
+	ServiceGroups map[string][]ServiceRef
+}
+
+var (
+	reDefine = regexp.MustCompile(`^\s*define\s+(\w+)\s*{`)
+	reEnd    = regexp.MustCompile(`^\s*}`)
+)
+
+// LoadGroups reads the group definitions from an objects.cache file.
+func LoadGroups(filename string) (*Groups, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeGroups(f)
+}
+
+// DecodeGroups reads the group definitions from the contents of an
+// objects.cache file.
+//
+// Nagios expands group members when writing the objects cache, so members
+// defined through hostgroup_members or servicegroup_members are already
+// present in the members directive.
+func DecodeGroups(r io.Reader) (*Groups, error) {
+	g := &Groups{
+		HostGroups:    make(map[string][]string),
+		ServiceGroups: make(map[string][]ServiceRef),
+	}
+
+	var (
+		kind  string
+		attrs map[string]string
+	)
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+
+		if kind == "" {
+			if m := reDefine.FindStringSubmatch(line); len(m) == 2 {
+				kind = m[1]
+				attrs = make(map[string]string)
+			}
+			continue
+		}
+
+		if reEnd.MatchString(line) {
+			if err := g.add(kind, attrs); err != nil {
+				return nil, err
+			}
+			kind = ""
+			continue
+		}
+
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(fields) == 2 {
+			attrs[fields[0]] = strings.TrimSpace(fields[1])
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (g *Groups) add(kind string, attrs map[string]string) error {
+	switch kind {
+	case "hostgroup":
+		g.HostGroups[attrs["hostgroup_name"]] = splitMembers(attrs["members"])
+
+	case "servicegroup":
+		members := splitMembers(attrs["members"])
+		if len(members)%2 != 0 {
+			return fmt.Errorf("invalid members for servicegroup '%s'", attrs["servicegroup_name"])
+		}
+
+		refs := make([]ServiceRef, 0, len(members)/2)
+		for i := 0; i < len(members); i += 2 {
+			refs = append(refs, ServiceRef{
+				HostName:           members[i],
+				ServiceDescription: members[i+1],
+			})
+		}
+		g.ServiceGroups[attrs["servicegroup_name"]] = refs
+	}
+
+	return nil
+}
+
+func splitMembers(s string) []string {
+	members := make([]string, 0)
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			members = append(members, m)
+		}
+	}
+	return members
+}
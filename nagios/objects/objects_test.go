@@ -0,0 +1,60 @@
+package objects
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleObjects = `########################################
+#       NAGIOS OBJECT CACHE FILE
+########################################
+
+define hostgroup {
+	hostgroup_name	linux-servers
+	alias	Linux Servers
+	members	host1,host2
+	}
+
+define servicegroup {
+	servicegroup_name	web
+	alias	Web Services
+	members	host1,http,host2,https
+	}
+
+define host {
+	host_name	host1
+	}
+`
+
+func TestDecodeGroups(t *testing.T) {
+	g, err := DecodeGroups(strings.NewReader(sampleObjects))
+	if err != nil {
+		t.Errorf("unable to decode sample input: %s", err)
+		return
+	}
+
+	if got, want := g.HostGroups["linux-servers"], []string{"host1", "host2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect hostgroup members, got: %v, want: %v", got, want)
+	}
+
+	want := []ServiceRef{
+		{HostName: "host1", ServiceDescription: "http"},
+		{HostName: "host2", ServiceDescription: "https"},
+	}
+	if got := g.ServiceGroups["web"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect servicegroup members, got: %v, want: %v", got, want)
+	}
+}
+
+func TestDecodeGroups_InvalidServiceGroup(t *testing.T) {
+	const input = `define servicegroup {
+	servicegroup_name	web
+	members	host1,http,host2
+	}
+`
+
+	if _, err := DecodeGroups(strings.NewReader(input)); err == nil {
+		t.Errorf("expected error")
+	}
+}
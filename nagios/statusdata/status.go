@@ -17,6 +17,12 @@ var (
 
 	// ErrUnknownService is used to indicate that no service status can be found for the given service.
 	ErrUnknownService = errors.New("unknown service")
+
+	// ErrUnknownComment is used to indicate that no comment can be found with the given id.
+	ErrUnknownComment = errors.New("unknown comment")
+
+	// ErrUnknownDowntime is used to indicate that no scheduled downtime can be found with the given id.
+	ErrUnknownDowntime = errors.New("unknown downtime")
 )
 
 // Repository provides access to the data in Nagios' status.dat file.
@@ -28,7 +34,22 @@ type Repository struct {
 	refreshInterval time.Duration
 	log             *zap.Logger
 
+	hosts    map[string]*xdata.HostStatus
 	services map[string]map[string]*xdata.ServiceStatus
+
+	hostComments         []*xdata.HostComment
+	hostCommentsByHost   map[string][]*xdata.HostComment
+	hostCommentsByID     map[int]*xdata.HostComment
+	serviceComments      []*xdata.ServiceComment
+	serviceCommentsBySvc map[string]map[string][]*xdata.ServiceComment
+	serviceCommentsByID  map[int]*xdata.ServiceComment
+
+	hostDowntimes        []*xdata.HostDowntime
+	hostDowntimesByID    map[int]*xdata.HostDowntime
+	serviceDowntimes     []*xdata.ServiceDowntime
+	serviceDowntimesByID map[int]*xdata.ServiceDowntime
+
+	updated chan struct{}
 }
 
 // NewRepository constructs an instance of statusdata.Repository.
@@ -45,6 +66,7 @@ func NewRepository(filename string, opts ...RepositoryOpt) (*Repository, error)
 		filename:        filename,
 		refreshInterval: time.Minute,
 		log:             zap.NewNop(),
+		updated:         make(chan struct{}),
 	}
 
 	for _, opt := range opts {
@@ -95,6 +117,11 @@ func (r *Repository) load() error {
 		return fmt.Errorf("unable to decode nagios status file")
 	}
 
+	hosts := make(map[string]*xdata.HostStatus)
+	for _, check := range raw.HostStatus {
+		hosts[check.HostName] = check
+	}
+
 	statuses := make(map[string]map[string]*xdata.ServiceStatus)
 	for _, check := range raw.ServiceStatus {
 		services, ok := statuses[check.HostName]
@@ -106,9 +133,54 @@ func (r *Repository) load() error {
 		services[check.ServiceDescription] = check
 	}
 
+	hostComments := make(map[string][]*xdata.HostComment)
+	hostCommentsByID := make(map[int]*xdata.HostComment)
+	for _, c := range raw.HostComment {
+		hostComments[c.HostName] = append(hostComments[c.HostName], c)
+		hostCommentsByID[c.CommentID] = c
+	}
+
+	serviceComments := make(map[string]map[string][]*xdata.ServiceComment)
+	serviceCommentsByID := make(map[int]*xdata.ServiceComment)
+	for _, c := range raw.ServiceComment {
+		services, ok := serviceComments[c.HostName]
+		if !ok {
+			services = make(map[string][]*xdata.ServiceComment)
+			serviceComments[c.HostName] = services
+		}
+
+		services[c.ServiceDescription] = append(services[c.ServiceDescription], c)
+		serviceCommentsByID[c.CommentID] = c
+	}
+
+	hostDowntimes := make(map[int]*xdata.HostDowntime)
+	for _, d := range raw.HostDowntime {
+		hostDowntimes[d.DowntimeID] = d
+	}
+
+	serviceDowntimes := make(map[int]*xdata.ServiceDowntime)
+	for _, d := range raw.ServiceDowntime {
+		serviceDowntimes[d.DowntimeID] = d
+	}
+
 	r.mux.Lock()
 	defer r.mux.Unlock()
+	r.hosts = hosts
 	r.services = statuses
+	r.hostComments = raw.HostComment
+	r.hostCommentsByHost = hostComments
+	r.hostCommentsByID = hostCommentsByID
+	r.serviceComments = raw.ServiceComment
+	r.serviceCommentsBySvc = serviceComments
+	r.serviceCommentsByID = serviceCommentsByID
+	r.hostDowntimes = raw.HostDowntime
+	r.hostDowntimesByID = hostDowntimes
+	r.serviceDowntimes = raw.ServiceDowntime
+	r.serviceDowntimesByID = serviceDowntimes
+
+	// wake anything waiting for the status file to be reloaded.
+	close(r.updated)
+	r.updated = make(chan struct{})
 
 	r.log.Info("loaded nagios status file",
 		zap.String("filename", r.filename),
@@ -117,6 +189,21 @@ func (r *Repository) load() error {
 	return nil
 }
 
+// HostStatus looks up a Nagios host check result by host name.
+//
+// ErrUnknownHost is returned if the Host is not found in the Nagios statusdata file.
+func (r *Repository) HostStatus(host string) (*xdata.HostStatus, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	status, ok := r.hosts[host]
+	if !ok {
+		return nil, ErrUnknownHost
+	}
+
+	return status, nil
+}
+
 // ServiceStatus looks up a Nagios service check result by host and service description.
 //
 // ErrUnknownHost and ErrUnknownService are returned if the respective Host and Service are not found in the Nagios
@@ -138,6 +225,132 @@ func (r *Repository) ServiceStatus(host, name string) (*xdata.ServiceStatus, err
 	return service, nil
 }
 
+// HostComments returns the comments for the given host, or every host comment if host is empty.
+//
+// ErrUnknownHost is returned if the Host is not found in the Nagios statusdata file.
+func (r *Repository) HostComments(host string) ([]*xdata.HostComment, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	if host == "" {
+		return r.hostComments, nil
+	}
+
+	if _, ok := r.hosts[host]; !ok {
+		return nil, ErrUnknownHost
+	}
+
+	return r.hostCommentsByHost[host], nil
+}
+
+// ServiceComments returns the comments for the given service, or every service comment if host and name are empty.
+//
+// ErrUnknownHost and ErrUnknownService are returned if the respective Host and Service are not found in the Nagios
+// statusdata file.
+func (r *Repository) ServiceComments(host, name string) ([]*xdata.ServiceComment, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	if host == "" {
+		return r.serviceComments, nil
+	}
+
+	services, ok := r.services[host]
+	if !ok {
+		return nil, ErrUnknownHost
+	}
+
+	if _, ok := services[name]; !ok {
+		return nil, ErrUnknownService
+	}
+
+	return r.serviceCommentsBySvc[host][name], nil
+}
+
+// HostComment looks up a host comment by id.
+//
+// ErrUnknownComment is returned if no host comment exists with the given id.
+func (r *Repository) HostComment(id int) (*xdata.HostComment, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	c, ok := r.hostCommentsByID[id]
+	if !ok {
+		return nil, ErrUnknownComment
+	}
+
+	return c, nil
+}
+
+// ServiceComment looks up a service comment by id.
+//
+// ErrUnknownComment is returned if no service comment exists with the given id.
+func (r *Repository) ServiceComment(id int) (*xdata.ServiceComment, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	c, ok := r.serviceCommentsByID[id]
+	if !ok {
+		return nil, ErrUnknownComment
+	}
+
+	return c, nil
+}
+
+// HostDowntimes returns every scheduled host downtime.
+func (r *Repository) HostDowntimes() []*xdata.HostDowntime {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return r.hostDowntimes
+}
+
+// ServiceDowntimes returns every scheduled service downtime.
+func (r *Repository) ServiceDowntimes() []*xdata.ServiceDowntime {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return r.serviceDowntimes
+}
+
+// HostDowntime looks up a scheduled host downtime by id.
+//
+// ErrUnknownDowntime is returned if no host downtime exists with the given id.
+func (r *Repository) HostDowntime(id int) (*xdata.HostDowntime, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	d, ok := r.hostDowntimesByID[id]
+	if !ok {
+		return nil, ErrUnknownDowntime
+	}
+
+	return d, nil
+}
+
+// ServiceDowntime looks up a scheduled service downtime by id.
+//
+// ErrUnknownDowntime is returned if no service downtime exists with the given id.
+func (r *Repository) ServiceDowntime(id int) (*xdata.ServiceDowntime, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	d, ok := r.serviceDowntimesByID[id]
+	if !ok {
+		return nil, ErrUnknownDowntime
+	}
+
+	return d, nil
+}
+
+// Updated returns a channel which is closed the next time the status file is successfully reloaded.
+func (r *Repository) Updated() <-chan struct{} {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return r.updated
+}
+
 // RepositoryOpt is used to customise the functionality of statusdata.Repository.
 type RepositoryOpt func(r *Repository) error
 
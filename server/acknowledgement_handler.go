@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
+	"github.com/jamesmichael/nagiosapi/service/acknowledgement"
+)
+
+type AcknowledgementService interface {
+	Acknowledgement(host, service string) (acknowledgement.Acknowledgement, error)
+	Acknowledge(req acknowledgement.Request) error
+	RemoveAcknowledgement(host, service string) error
+}
+
+// RegisterAcknowledgementService sets up /status/HOST/ack and
+// /status/HOST/SERVICE/ack routes for acknowledging host and service problems.
+func (s *Server) RegisterAcknowledgementService(svc AcknowledgementService) {
+	s.mux.Get("/status/{host}/ack", handleGetAcknowledgement(svc))
+	s.mux.Post("/status/{host}/ack", handleAcknowledge(svc))
+	s.mux.Delete("/status/{host}/ack", handleRemoveAcknowledgement(svc))
+
+	s.mux.Get("/status/{host}/{service}/ack", handleGetAcknowledgement(svc))
+	s.mux.Post("/status/{host}/{service}/ack", handleAcknowledge(svc))
+	s.mux.Delete("/status/{host}/{service}/ack", handleRemoveAcknowledgement(svc))
+}
+
+type acknowledgementResponse struct {
+	Hostname            string `json:"hostname"`
+	Service             string `json:"service,omitempty"`
+	IsAcknowledged      bool   `json:"is_acknowledged"`
+	AcknowledgementType string `json:"acknowledgement_type"`
+	Notify              *bool  `json:"notify,omitempty"`
+	Persistent          *bool  `json:"persistent,omitempty"`
+	ExpireTime          int64  `json:"expire_time,omitempty"`
+}
+
+type acknowledgementRequest struct {
+	Author     string `json:"author"`
+	Comment    string `json:"comment"`
+	Sticky     *bool  `json:"sticky"`
+	Notify     *bool  `json:"notify"`
+	Persistent bool   `json:"persistent"`
+	ExpireTime int64  `json:"expire_time"`
+}
+
+func handleGetAcknowledgement(svc AcknowledgementService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ack, err := svc.Acknowledgement(chi.URLParam(r, "host"), chi.URLParam(r, "service"))
+		if err != nil {
+			writeAcknowledgementError(w, err)
+			return
+		}
+
+		writeAcknowledgement(w, http.StatusOK, acknowledgementResponse{
+			Hostname:            ack.HostName,
+			Service:             ack.ServiceDescription,
+			IsAcknowledged:      ack.IsAcknowledged,
+			AcknowledgementType: ack.Type.String(),
+		})
+	}
+}
+
+func handleAcknowledge(svc AcknowledgementService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req acknowledgementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+		defer r.Body.Close()
+
+		// match the defaults used by the nagios CGIs.
+		ackType := xdata.AcknowledgementType(xdata.Sticky)
+		if req.Sticky != nil && !*req.Sticky {
+			ackType = xdata.Normal
+		}
+
+		notify := true
+		if req.Notify != nil {
+			notify = *req.Notify
+		}
+
+		host := chi.URLParam(r, "host")
+		service := chi.URLParam(r, "service")
+		if err := svc.Acknowledge(acknowledgement.Request{
+			HostName:           host,
+			ServiceDescription: service,
+			Type:               ackType,
+			Notify:             notify,
+			Persistent:         req.Persistent,
+			Author:             req.Author,
+			Comment:            req.Comment,
+			Expire:             unixTime(req.ExpireTime),
+		}); err != nil {
+			writeAcknowledgementError(w, err)
+			return
+		}
+
+		writeAcknowledgement(w, http.StatusAccepted, acknowledgementResponse{
+			Hostname:            host,
+			Service:             service,
+			IsAcknowledged:      true,
+			AcknowledgementType: ackType.String(),
+			Notify:              &notify,
+			Persistent:          &req.Persistent,
+			ExpireTime:          req.ExpireTime,
+		})
+	}
+}
+
+func handleRemoveAcknowledgement(svc AcknowledgementService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := chi.URLParam(r, "host")
+		service := chi.URLParam(r, "service")
+		if err := svc.RemoveAcknowledgement(host, service); err != nil {
+			writeAcknowledgementError(w, err)
+			return
+		}
+
+		writeAcknowledgement(w, http.StatusAccepted, acknowledgementResponse{
+			Hostname:            host,
+			Service:             service,
+			IsAcknowledged:      false,
+			AcknowledgementType: xdata.AcknowledgementType(xdata.None).String(),
+		})
+	}
+}
+
+func writeAcknowledgement(w http.ResponseWriter, status int, res acknowledgementResponse) {
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(out)
+}
+
+func writeAcknowledgementError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, statusdata.ErrUnknownHost),
+		errors.Is(err, statusdata.ErrUnknownService):
+		http.Error(w, http.StatusText(404), 404)
+	case errors.Is(err, acknowledgement.ErrNoProblem),
+		errors.Is(err, acknowledgement.ErrNotAcknowledged):
+		http.Error(w, err.Error(), 409)
+	case errors.Is(err, acknowledgement.ErrInvalidAcknowledgement),
+		errors.Is(err, cmd.ErrInvalidArgument),
+		errors.Is(err, cmd.ErrCommandTooLong):
+		http.Error(w, err.Error(), 400)
+	default:
+		http.Error(w, http.StatusText(500), 500)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/submission"
+)
+
+type DeadLetterService interface {
+	DeadLetters() []cmd.DeadLetter
+}
+
+type WriterStatsService interface {
+	Stats() []cmd.Stats
+}
+
+type UnknownTargetService interface {
+	UnknownTargets() []submission.UnknownTarget
+}
+
+// RegisterDeadLetterService sets up the /admin/deadletters route for
+// inspecting external commands which could not be written to nagios.
+func (s *Server) RegisterDeadLetterService(svc DeadLetterService) {
+	s.mux.Get("/admin/deadletters", handleListDeadLetters(svc))
+}
+
+// RegisterWriterStatsService sets up the /admin/writer route for inspecting
+// the external commands queue of each target.
+func (s *Server) RegisterWriterStatsService(svc WriterStatsService) {
+	s.mux.Get("/admin/writer", handleWriterStats(svc))
+}
+
+// RegisterUnknownTargetService sets up the /admin/unknown-targets route for
+// finding agents which submit results for hosts and services nagios does not
+// know about.
+func (s *Server) RegisterUnknownTargetService(svc UnknownTargetService) {
+	s.mux.Get("/admin/unknown-targets", handleListUnknownTargets(svc))
+}
+
+type deadLetterResponse struct {
+	Target     string `json:"target"`
+	Command    string `json:"command"`
+	Reason     string `json:"reason"`
+	Attempts   int    `json:"attempts"`
+	QueuedTime int64  `json:"queued_time"`
+	FailedTime int64  `json:"failed_time"`
+}
+
+func handleListDeadLetters(svc DeadLetterService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		letters := svc.DeadLetters()
+
+		res := make([]deadLetterResponse, 0, len(letters))
+		for _, d := range letters {
+			res = append(res, deadLetterResponse{
+				Target:     d.Target,
+				Command:    d.Command,
+				Reason:     d.Reason,
+				Attempts:   d.Attempts,
+				QueuedTime: d.Queued.Unix(),
+				FailedTime: d.Failed.Unix(),
+			})
+		}
+
+		out, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+	}
+}
+
+type writerStatsResponse struct {
+	Target           string  `json:"target"`
+	Enqueued         uint64  `json:"enqueued"`
+	Rejected         uint64  `json:"rejected"`
+	Written          uint64  `json:"written"`
+	Failed           uint64  `json:"failed"`
+	Retried          uint64  `json:"retried"`
+	Dropped          uint64  `json:"dropped"`
+	Coalesced        uint64  `json:"coalesced"`
+	QueueDepth       int     `json:"queue_depth"`
+	QueuedBytes      int64   `json:"queued_bytes"`
+	OldestAgeSeconds float64 `json:"oldest_age_seconds"`
+	Open             bool    `json:"open"`
+	Healthy          bool    `json:"healthy"`
+	LastWriteTime    int64   `json:"last_write_time"`
+	LastError        string  `json:"last_error"`
+	LastErrorTime    int64   `json:"last_error_time"`
+}
+
+func handleWriterStats(svc WriterStatsService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats := svc.Stats()
+
+		res := make([]writerStatsResponse, 0, len(stats))
+		for _, st := range stats {
+			res = append(res, writerStatsResponse{
+				Target:           st.Target,
+				Enqueued:         st.Enqueued,
+				Rejected:         st.Rejected,
+				Written:          st.Written,
+				Failed:           st.Failed,
+				Retried:          st.Retried,
+				Dropped:          st.Dropped,
+				Coalesced:        st.Coalesced,
+				QueueDepth:       st.QueueDepth,
+				QueuedBytes:      st.QueuedBytes,
+				OldestAgeSeconds: st.OldestAge.Seconds(),
+				Open:             st.Open,
+				Healthy:          st.Healthy,
+				LastWriteTime:    timestamp(st.LastWrite),
+				LastError:        st.LastError,
+				LastErrorTime:    timestamp(st.LastErrorTime),
+			})
+		}
+
+		out, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+	}
+}
+
+// timestamp returns t as a unix timestamp, or 0 if t is not set.
+func timestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+type unknownTargetResponse struct {
+	Hostname      string `json:"hostname"`
+	ServiceName   string `json:"service_name"`
+	Count         int    `json:"count"`
+	Rejected      int    `json:"rejected"`
+	FirstSeenTime int64  `json:"first_seen_time"`
+	LastSeenTime  int64  `json:"last_seen_time"`
+}
+
+func handleListUnknownTargets(svc UnknownTargetService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		targets := svc.UnknownTargets()
+
+		res := make([]unknownTargetResponse, 0, len(targets))
+		for _, t := range targets {
+			res = append(res, unknownTargetResponse{
+				Hostname:      t.Hostname,
+				ServiceName:   t.ServiceName,
+				Count:         t.Count,
+				Rejected:      t.Rejected,
+				FirstSeenTime: t.FirstSeen.Unix(),
+				LastSeenTime:  t.LastSeen.Unix(),
+			})
+		}
+
+		out, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+	}
+}
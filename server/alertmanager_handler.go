@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/alertmanager"
+)
+
+type AlertmanagerService interface {
+	SubmitAlert(a alertmanager.Alert) (alertmanager.Check, []cmd.Delivery, error)
+}
+
+// alertmanagerMessage is the body of an Alertmanager webhook notification.
+// Only the alerts are used.
+type alertmanagerMessage struct {
+	Alerts []struct {
+		Status      string            `json:"status"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"alerts"`
+}
+
+type alertResponse struct {
+	batchItemResponse
+	Hostname    string `json:"hostname,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// RegisterAlertmanagerService sets up the /integrations/alertmanager route,
+// which receives Alertmanager webhook notifications, and submits each alert
+// as a passive service check result.
+func (s *Server) RegisterAlertmanagerService(svc AlertmanagerService) {
+	s.mux.Post("/integrations/alertmanager", func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var msg alertmanagerMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			writeSubmitError(w, 400, decodeError(err))
+			return
+		}
+
+		// Alertmanager retries the whole notification, every alert of the
+		// group, unless it is accepted. Passive results are idempotent, so
+		// a retry is asked for whenever any alert could not be queued, even
+		// though those which were are then submitted twice.
+		retry := false
+		res := make([]alertResponse, 0, len(msg.Alerts))
+		for i, a := range msg.Alerts {
+			check, deliveries, err := svc.SubmitAlert(alertmanager.Alert{
+				Status:      a.Status,
+				Labels:      a.Labels,
+				Annotations: a.Annotations,
+			})
+
+			item := alertResponse{
+				batchItemResponse: batchItemResponse{Index: i},
+				Hostname:          check.Hostname,
+				ServiceName:       check.ServiceName,
+				State:             check.State.String(),
+			}
+			item.setResult(deliveries, err)
+			if item.Status == submitQueueFull || item.Status == submitFailed {
+				retry = true
+			}
+
+			res = append(res, item)
+		}
+
+		out, err := json.Marshal(struct {
+			Alerts []alertResponse `json:"alerts"`
+		}{res})
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		code := http.StatusOK
+		if retry {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		w.Write(out)
+	})
+}
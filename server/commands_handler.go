@@ -1,45 +1,565 @@
 package server
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
+	"github.com/jamesmichael/nagiosapi/service/heartbeat"
+	"github.com/jamesmichael/nagiosapi/service/submission"
 )
 
 type PassiveCommandService interface {
-	SubmitResult(time int64, statusCode uint, hostname, serviceName, body string) error
+	SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error)
+	SubmitHostResult(time int64, state xdata.HostState, hostname, body string) ([]cmd.Delivery, error)
+}
+
+// heartbeatExpecter is implemented by services which watch for results that
+// stop arriving, such as heartbeat.Service.
+type heartbeatExpecter interface {
+	Expect(hostname, serviceName string, every time.Duration)
 }
 
+// passiveServiceResult and passiveHostResult accept the status as either a
+// numeric code, or a name such as "CRITICAL" or "DOWN".
+//
+// ExpectEvery declares that the next result should arrive within the
+// interval, otherwise an overdue result is submitted.
 type passiveServiceResult struct {
-	Time        int64  `json:"time"`
-	Hostname    string `json:"hostname"`
-	ServiceName string `json:"service_name"`
-	Status      uint   `json:"status"`
-	Body        string `json:"body"`
+	Time        int64              `json:"time"`
+	Hostname    string             `json:"hostname"`
+	ServiceName string             `json:"service_name"`
+	Status      xdata.ServiceState `json:"status"`
+	Body        string             `json:"body"`
+	LongOutput  string             `json:"long_output"`
+	Perfdata    []perfdata         `json:"perfdata"`
+	ExpectEvery interval           `json:"expect_every"`
+}
+
+type passiveHostResult struct {
+	Time        int64           `json:"time"`
+	Hostname    string          `json:"hostname"`
+	Status      xdata.HostState `json:"status"`
+	Body        string          `json:"body"`
+	LongOutput  string          `json:"long_output"`
+	Perfdata    []perfdata      `json:"perfdata"`
+	ExpectEvery interval        `json:"expect_every"`
+}
+
+// interval accepts either a duration, such as "5m", or a number of seconds.
+type interval time.Duration
+
+func (i *interval) UnmarshalJSON(b []byte) error {
+	var d time.Duration
+
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		if d, err = time.ParseDuration(strings.TrimSpace(s)); err != nil {
+			return &submission.FieldError{Field: "expect_every", Reason: "must be a duration, such as '5m'"}
+		}
+	} else {
+		var n int64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return &submission.FieldError{Field: "expect_every", Reason: "must be a duration or a number of seconds"}
+		}
+		d = time.Duration(n) * time.Second
+	}
+
+	if d < heartbeat.MinInterval {
+		return &submission.FieldError{Field: "expect_every", Reason: fmt.Sprintf("must be at least %s", heartbeat.MinInterval)}
+	}
+
+	*i = interval(d)
+	return nil
+}
+
+// expect records the interval the next result is expected within, once a
+// result was submitted. It is ignored if the service does not watch for
+// overdue results.
+func expect(svc PassiveCommandService, every interval, hostname, serviceName string) {
+	if e, ok := svc.(heartbeatExpecter); ok && every > 0 {
+		e.Expect(hostname, serviceName, time.Duration(every))
+	}
+}
+
+type perfdata struct {
+	Label string        `json:"label"`
+	Value float64       `json:"value"`
+	UOM   string        `json:"uom"`
+	Warn  perfdataValue `json:"warn"`
+	Crit  perfdataValue `json:"crit"`
+	Min   perfdataValue `json:"min"`
+	Max   perfdataValue `json:"max"`
+}
+
+// perfdataValue accepts either a number, or a string such as a threshold
+// range.
+type perfdataValue string
+
+func (v *perfdataValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = perfdataValue(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*v = perfdataValue(n)
+	return nil
+}
+
+// output renders the body, long output and performance data of a result
+// into the plugin output.
+func output(body, longOutput string, metrics []perfdata) (string, error) {
+	p := make([]submission.Perfdata, 0, len(metrics))
+	for _, m := range metrics {
+		p = append(p, submission.Perfdata{
+			Label: m.Label,
+			Value: m.Value,
+			UOM:   m.UOM,
+			Warn:  string(m.Warn),
+			Crit:  string(m.Crit),
+			Min:   string(m.Min),
+			Max:   string(m.Max),
+		})
+	}
+
+	return submission.RenderOutput(body, longOutput, p)
 }
 
-// RegisterPassiveCommandService sets up /submit route for submitting
-// passive service check results.
+// submitError names the field of a passive check result which is invalid.
+type submitError struct {
+	Error  string `json:"error"`
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type deliveryResponse struct {
+	Target  string `json:"target"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Healthy bool   `json:"healthy"`
+}
+
+type submitResponse struct {
+	Deliveries []deliveryResponse `json:"deliveries"`
+}
+
+// Status of each item of a batch submission.
+const (
+	submitAccepted  = "accepted"
+	submitInvalid   = "invalid"
+	submitQueueFull = "queue_full"
+	submitUnknown   = "unknown"
+	submitFailed    = "failed"
+)
+
+type batchItemResponse struct {
+	Index      int                `json:"index"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Field      string             `json:"field,omitempty"`
+	Deliveries []deliveryResponse `json:"deliveries,omitempty"`
+}
+
+// RegisterPassiveCommandService sets up the /submit and /submit/host routes
+// for submitting passive service and host check results.
+//
+// /submit also accepts a batch of service check results, either as a JSON
+// array or as newline delimited JSON.
 func (s *Server) RegisterPassiveCommandService(svc PassiveCommandService) {
 	s.mux.Post("/submit", func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		body := bufio.NewReader(r.Body)
+		if isNDJSON(r) {
+			handleSubmitBatch(w, svc, streamItems(json.NewDecoder(body)))
+			return
+		}
+		if firstByte(body) == '[' {
+			handleSubmitBatch(w, svc, arrayItems(json.NewDecoder(body)))
+			return
+		}
+
 		var res passiveServiceResult
-		if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
-			http.Error(w, http.StatusText(400), 400)
+		if err := json.NewDecoder(body).Decode(&res); err != nil {
+			writeSubmitError(w, 400, decodeError(err))
+			return
+		}
+
+		pluginOutput, err := output(res.Body, res.LongOutput, res.Perfdata)
+		if err != nil {
+			writeSubmitResult(w, nil, err)
 			return
 		}
-		defer r.Body.Close()
 
-		if err := svc.SubmitResult(
+		deliveries, err := svc.SubmitResult(
 			res.Time,
 			res.Status,
 			res.Hostname,
 			res.ServiceName,
-			res.Body,
-		); err != nil {
-			http.Error(w, http.StatusText(500), 500)
+			pluginOutput,
+		)
+		if err == nil {
+			expect(svc, res.ExpectEvery, res.Hostname, res.ServiceName)
+		}
+		writeSubmitResult(w, deliveries, err)
+	})
+
+	s.mux.Post("/submit/host", func(w http.ResponseWriter, r *http.Request) {
+		var res passiveHostResult
+		if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+			writeSubmitError(w, 400, decodeError(err))
+			return
+		}
+		defer r.Body.Close()
+
+		pluginOutput, err := output(res.Body, res.LongOutput, res.Perfdata)
+		if err != nil {
+			writeSubmitResult(w, nil, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusAccepted)
-		w.Write([]byte(`"ok"`))
+		deliveries, err := svc.SubmitHostResult(
+			res.Time,
+			res.Status,
+			res.Hostname,
+			pluginOutput,
+		)
+		if err == nil {
+			expect(svc, res.ExpectEvery, res.Hostname, "")
+		}
+		writeSubmitResult(w, deliveries, err)
 	})
 }
+
+// writeSubmitResult writes the response to a passive check result
+// submission.
+func writeSubmitResult(w http.ResponseWriter, deliveries []cmd.Delivery, err error) {
+	if errors.Is(err, submission.ErrInvalidResult) ||
+		errors.Is(err, cmd.ErrInvalidArgument) ||
+		errors.Is(err, cmd.ErrCommandTooLong) {
+		writeSubmitError(w, 400, validationError(err))
+		return
+	}
+
+	if errors.Is(err, statusdata.ErrUnknownHost) || errors.Is(err, statusdata.ErrUnknownService) {
+		writeSubmitError(w, 404, unknownTargetError(err))
+		return
+	}
+
+	// with several targets, report the delivery to each of them.
+	if len(deliveries) > 1 && (err == nil || errors.Is(err, cmd.ErrDeliveryFailed)) {
+		code := http.StatusAccepted
+		if err != nil {
+			code = http.StatusBadGateway
+		}
+		writeDeliveries(w, code, deliveries)
+		return
+	}
+
+	if errors.Is(err, cmd.ErrQueueFull) {
+		writeSubmitError(w, 503, submitError{
+			Error:  cmd.ErrQueueFull.Error(),
+			Reason: "the external commands queue is full, retry later",
+		})
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, cmd.ErrRejected) {
+			http.Error(w, err.Error(), 422)
+			return
+		}
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(`"ok"`))
+}
+
+func writeDeliveries(w http.ResponseWriter, code int, deliveries []cmd.Delivery) {
+	res := submitResponse{
+		Deliveries: deliveryResponses(deliveries),
+	}
+
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(out)
+}
+
+func deliveryResponses(deliveries []cmd.Delivery) []deliveryResponse {
+	res := make([]deliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		delivery := deliveryResponse{
+			Target:  d.Target,
+			Status:  "queued",
+			Healthy: d.Healthy,
+		}
+		if d.Err != nil {
+			delivery.Status = "failed"
+			delivery.Error = d.Err.Error()
+		}
+		res = append(res, delivery)
+	}
+	return res
+}
+
+// handleSubmitBatch submits each item of a batch independently, so a bad
+// item does not fail the rest of the batch, and responds with the status of
+// each item.
+func handleSubmitBatch(w http.ResponseWriter, svc PassiveCommandService, next batchReader) {
+	res := make([]batchItemResponse, 0)
+	for {
+		raw, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the rest of the batch can not be read.
+			res = append(res, batchItemResponse{
+				Index:  len(res),
+				Status: submitInvalid,
+				Error:  err.Error(),
+			})
+			break
+		}
+
+		res = append(res, submitBatchItem(svc, len(res), raw))
+	}
+
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.Write(out)
+}
+
+func submitBatchItem(svc PassiveCommandService, index int, raw json.RawMessage) batchItemResponse {
+	res := batchItemResponse{
+		Index: index,
+	}
+
+	var result passiveServiceResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		e := decodeError(err)
+		res.Status = submitInvalid
+		res.Error = e.Reason
+		res.Field = e.Field
+		return res
+	}
+
+	var deliveries []cmd.Delivery
+	pluginOutput, err := output(result.Body, result.LongOutput, result.Perfdata)
+	if err == nil {
+		deliveries, err = svc.SubmitResult(
+			result.Time,
+			result.Status,
+			result.Hostname,
+			result.ServiceName,
+			pluginOutput,
+		)
+	}
+	if err == nil {
+		expect(svc, result.ExpectEvery, result.Hostname, result.ServiceName)
+	}
+	res.setResult(deliveries, err)
+
+	return res
+}
+
+// setResult sets the status of a batch item from the result of submitting
+// it.
+func (res *batchItemResponse) setResult(deliveries []cmd.Delivery, err error) {
+	if len(deliveries) > 1 {
+		res.Deliveries = deliveryResponses(deliveries)
+	}
+
+	switch {
+	case err == nil:
+		res.Status = submitAccepted
+	case errors.Is(err, submission.ErrInvalidResult),
+		errors.Is(err, cmd.ErrInvalidArgument),
+		errors.Is(err, cmd.ErrCommandTooLong):
+		e := validationError(err)
+		res.Status = submitInvalid
+		res.Error = e.Reason
+		res.Field = e.Field
+	case errors.Is(err, statusdata.ErrUnknownHost), errors.Is(err, statusdata.ErrUnknownService):
+		e := unknownTargetError(err)
+		res.Status = submitUnknown
+		res.Error = e.Reason
+		res.Field = e.Field
+	case errors.Is(err, cmd.ErrQueueFull):
+		res.Status = submitQueueFull
+		res.Error = err.Error()
+	default:
+		res.Status = submitFailed
+		res.Error = err.Error()
+	}
+}
+
+// batchReader returns the next undecoded item of a batch submission, or
+// io.EOF at the end of the batch.
+type batchReader func() (json.RawMessage, error)
+
+// arrayItems reads the items of a JSON array.
+func arrayItems(dec *json.Decoder) batchReader {
+	started := false
+	return func() (json.RawMessage, error) {
+		if !started {
+			started = true
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+		}
+
+		if !dec.More() {
+			return nil, io.EOF
+		}
+
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		return raw, err
+	}
+}
+
+// streamItems reads newline delimited JSON items until the end of the
+// request, so each item is submitted as soon as it arrives.
+func streamItems(dec *json.Decoder) batchReader {
+	return func() (json.RawMessage, error) {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		return raw, err
+	}
+}
+
+// isNDJSON returns whether the request body is newline delimited JSON.
+func isNDJSON(r *http.Request) bool {
+	t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return t == "application/x-ndjson" || t == "application/ndjson"
+}
+
+// firstByte returns the first non-whitespace byte of r, without consuming
+// it, or 0 if there is none.
+func firstByte(r *bufio.Reader) byte {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		default:
+			return b[0]
+		}
+	}
+}
+
+// decodeError describes why a passive check result could not be decoded,
+// naming the field where possible.
+func decodeError(err error) submitError {
+	var typeErr *json.UnmarshalTypeError
+	var fieldErr *submission.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return validationError(err)
+	case errors.As(err, &typeErr):
+		return submitError{
+			Error:  submission.ErrInvalidResult.Error(),
+			Field:  typeErr.Field,
+			Reason: fmt.Sprintf("must be a %s", typeErr.Type),
+		}
+	case errors.Is(err, xdata.ErrUnknownValue):
+		// only the status is parsed by xdata.
+		return submitError{
+			Error:  submission.ErrInvalidResult.Error(),
+			Field:  "status",
+			Reason: "must be a status code, or the name of a state",
+		}
+	default:
+		return submitError{
+			Error:  "invalid request body",
+			Reason: err.Error(),
+		}
+	}
+}
+
+// validationError describes why a passive check result failed validation.
+func validationError(err error) submitError {
+	var fieldErr *submission.FieldError
+	if errors.As(err, &fieldErr) {
+		return submitError{
+			Error:  submission.ErrInvalidResult.Error(),
+			Field:  fieldErr.Field,
+			Reason: fieldErr.Reason,
+		}
+	}
+
+	if errors.Is(err, cmd.ErrCommandTooLong) {
+		return submitError{
+			Error:  submission.ErrInvalidResult.Error(),
+			Field:  "body",
+			Reason: "too long for the external commands file",
+		}
+	}
+
+	return submitError{
+		Error:  submission.ErrInvalidResult.Error(),
+		Reason: err.Error(),
+	}
+}
+
+// unknownTargetError describes a result for a host or service which is not
+// in the nagios status file.
+func unknownTargetError(err error) submitError {
+	if errors.Is(err, statusdata.ErrUnknownHost) {
+		return submitError{
+			Error:  statusdata.ErrUnknownHost.Error(),
+			Field:  "hostname",
+			Reason: "not found in the nagios status file",
+		}
+	}
+
+	return submitError{
+		Error:  statusdata.ErrUnknownService.Error(),
+		Field:  "service_name",
+		Reason: "not found in the nagios status file",
+	}
+}
+
+func writeSubmitError(w http.ResponseWriter, status int, res submitError) {
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(out)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
+	"github.com/jamesmichael/nagiosapi/service/comment"
+)
+
+type CommentService interface {
+	Comments() ([]comment.Comment, error)
+	HostComments(host string) ([]comment.Comment, error)
+	ServiceComments(host, service string) ([]comment.Comment, error)
+	AddHostComment(host string, persistent bool, author, text string) error
+	AddServiceComment(host, service string, persistent bool, author, text string) error
+	DeleteHostComment(host string, id int) error
+	DeleteServiceComment(host, service string, id int) error
+}
+
+// RegisterCommentService sets up /comments, /status/HOST/comments and
+// /status/HOST/SERVICE/comments routes for reading, adding and deleting
+// comments.
+func (s *Server) RegisterCommentService(svc CommentService) {
+	s.mux.Get("/comments", handleListComments(svc))
+
+	s.mux.Get("/status/{host}/comments", handleHostComments(svc))
+	s.mux.Post("/status/{host}/comments", handleAddHostComment(svc))
+	s.mux.Delete("/status/{host}/comments/{id}", handleDeleteHostComment(svc))
+
+	s.mux.Get("/status/{host}/{service}/comments", handleServiceComments(svc))
+	s.mux.Post("/status/{host}/{service}/comments", handleAddServiceComment(svc))
+	s.mux.Delete("/status/{host}/{service}/comments/{id}", handleDeleteServiceComment(svc))
+}
+
+type commentResponse struct {
+	ID         int    `json:"id"`
+	Hostname   string `json:"hostname"`
+	Service    string `json:"service,omitempty"`
+	Author     string `json:"author"`
+	Comment    string `json:"comment"`
+	EntryTime  int64  `json:"entry_time"`
+	EntryType  string `json:"entry_type"`
+	Source     string `json:"source"`
+	Persistent bool   `json:"persistent"`
+	Expires    bool   `json:"expires"`
+	ExpireTime int64  `json:"expire_time,omitempty"`
+}
+
+type commentRequest struct {
+	Author     string `json:"author"`
+	Comment    string `json:"comment"`
+	Persistent bool   `json:"persistent"`
+}
+
+func handleListComments(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comments, err := svc.Comments()
+		writeComments(w, comments, err)
+	}
+}
+
+func handleHostComments(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comments, err := svc.HostComments(chi.URLParam(r, "host"))
+		writeComments(w, comments, err)
+	}
+}
+
+func handleServiceComments(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comments, err := svc.ServiceComments(chi.URLParam(r, "host"), chi.URLParam(r, "service"))
+		writeComments(w, comments, err)
+	}
+}
+
+func handleAddHostComment(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req commentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+		defer r.Body.Close()
+
+		err := svc.AddHostComment(chi.URLParam(r, "host"), req.Persistent, req.Author, req.Comment)
+		writeCommentResult(w, err)
+	}
+}
+
+func handleAddServiceComment(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req commentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+		defer r.Body.Close()
+
+		err := svc.AddServiceComment(chi.URLParam(r, "host"), chi.URLParam(r, "service"), req.Persistent, req.Author, req.Comment)
+		writeCommentResult(w, err)
+	}
+}
+
+func handleDeleteHostComment(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+
+		err = svc.DeleteHostComment(chi.URLParam(r, "host"), id)
+		writeCommentResult(w, err)
+	}
+}
+
+func handleDeleteServiceComment(svc CommentService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+
+		err = svc.DeleteServiceComment(chi.URLParam(r, "host"), chi.URLParam(r, "service"), id)
+		writeCommentResult(w, err)
+	}
+}
+
+func writeComments(w http.ResponseWriter, comments []comment.Comment, err error) {
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	res := make([]commentResponse, 0, len(comments))
+	for _, c := range comments {
+		res = append(res, commentResponse{
+			ID:         c.ID,
+			Hostname:   c.HostName,
+			Service:    c.ServiceDescription,
+			Author:     c.Author,
+			Comment:    c.Data,
+			EntryTime:  c.EntryTime,
+			EntryType:  c.EntryType.String(),
+			Source:     c.Source.String(),
+			Persistent: c.Persistent,
+			Expires:    c.Expires,
+			ExpireTime: c.ExpireTime,
+		})
+	}
+
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.Write(out)
+}
+
+func writeCommentResult(w http.ResponseWriter, err error) {
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(`"ok"`))
+}
+
+func writeCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, statusdata.ErrUnknownHost),
+		errors.Is(err, statusdata.ErrUnknownService),
+		errors.Is(err, statusdata.ErrUnknownComment):
+		http.Error(w, http.StatusText(404), 404)
+	case errors.Is(err, comment.ErrEmptyAuthor),
+		errors.Is(err, comment.ErrEmptyComment),
+		errors.Is(err, cmd.ErrInvalidArgument),
+		errors.Is(err, cmd.ErrCommandTooLong):
+		http.Error(w, err.Error(), 400)
+	default:
+		http.Error(w, http.StatusText(500), 500)
+	}
+}
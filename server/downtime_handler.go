@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
+	"github.com/jamesmichael/nagiosapi/service/downtime"
+)
+
+type DowntimeService interface {
+	Downtimes() []downtime.Downtime
+	Downtime(id int) (downtime.Downtime, error)
+	Schedule(ctx context.Context, req downtime.Request, wait bool) ([]downtime.Downtime, error)
+	Cancel(id int) error
+}
+
+// RegisterDowntimeService sets up /downtimes and /downtimes/ID routes for
+// listing, scheduling and cancelling scheduled downtime.
+func (s *Server) RegisterDowntimeService(svc DowntimeService) {
+	s.mux.Route("/downtimes", func(r chi.Router) {
+		r.Get("/", handleListDowntimes(svc))
+		r.Post("/", handleScheduleDowntime(svc))
+		r.Get("/{id}", handleGetDowntime(svc))
+		r.Delete("/{id}", handleCancelDowntime(svc))
+	})
+}
+
+type downtimeResponse struct {
+	ID          int    `json:"id"`
+	Hostname    string `json:"hostname"`
+	Service     string `json:"service,omitempty"`
+	Author      string `json:"author"`
+	Comment     string `json:"comment"`
+	EntryTime   int64  `json:"entry_time"`
+	StartTime   int64  `json:"start_time"`
+	EndTime     int64  `json:"end_time"`
+	Fixed       bool   `json:"fixed"`
+	Duration    int64  `json:"duration"`
+	TriggeredBy int    `json:"triggered_by,omitempty"`
+	IsInEffect  bool   `json:"is_in_effect"`
+}
+
+type downtimeRequest struct {
+	Type        string `json:"type"`
+	Hostname    string `json:"hostname"`
+	ServiceName string `json:"service_name"`
+	Hostgroup   string `json:"hostgroup"`
+	StartTime   int64  `json:"start_time"`
+	EndTime     int64  `json:"end_time"`
+	Fixed       *bool  `json:"fixed"`
+	Duration    int64  `json:"duration"`
+	TriggerID   int    `json:"trigger_id"`
+	Author      string `json:"author"`
+	Comment     string `json:"comment"`
+}
+
+func handleListDowntimes(svc DowntimeService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeDowntimes(w, http.StatusOK, svc.Downtimes())
+	}
+}
+
+func handleGetDowntime(svc DowntimeService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+
+		d, err := svc.Downtime(id)
+		if err != nil {
+			writeDowntimeError(w, err)
+			return
+		}
+
+		out, err := json.Marshal(toDowntimeResponse(d))
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+	}
+}
+
+func handleScheduleDowntime(svc DowntimeService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req downtimeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+		defer r.Body.Close()
+
+		typ, err := downtimeType(req)
+		if err != nil {
+			writeDowntimeError(w, err)
+			return
+		}
+
+		// downtime is fixed unless a duration is given.
+		fixed := req.Duration == 0
+		if req.Fixed != nil {
+			fixed = *req.Fixed
+		}
+
+		wait := true
+		if v := r.URL.Query().Get("wait"); v != "" {
+			if wait, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, http.StatusText(400), 400)
+				return
+			}
+		}
+
+		found, err := svc.Schedule(r.Context(), downtime.Request{
+			Type:               typ,
+			HostName:           req.Hostname,
+			ServiceDescription: req.ServiceName,
+			HostGroup:          req.Hostgroup,
+			Start:              unixTime(req.StartTime),
+			End:                unixTime(req.EndTime),
+			Fixed:              fixed,
+			Duration:           time.Duration(req.Duration) * time.Second,
+			TriggerID:          req.TriggerID,
+			Author:             req.Author,
+			Comment:            req.Comment,
+		}, wait)
+		if err != nil {
+			writeDowntimeError(w, err)
+			return
+		}
+
+		// the command has been queued, but nagios has not yet reported the
+		// new downtime.
+		if len(found) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`"ok"`))
+			return
+		}
+
+		writeDowntimes(w, http.StatusCreated, found)
+	}
+}
+
+func handleCancelDowntime(svc DowntimeService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(400), 400)
+			return
+		}
+
+		if err := svc.Cancel(id); err != nil {
+			writeDowntimeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`"ok"`))
+	}
+}
+
+// downtimeType uses the type given in the request, or infers the type from
+// the fields which have been set.
+func downtimeType(req downtimeRequest) (downtime.Type, error) {
+	switch {
+	case req.Type != "":
+		return downtime.ParseType(req.Type)
+	case req.Hostgroup != "":
+		return downtime.HostGroupHostDowntime, nil
+	case req.ServiceName != "":
+		return downtime.ServiceDowntime, nil
+	default:
+		return downtime.HostDowntime, nil
+	}
+}
+
+func unixTime(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+func toDowntimeResponse(d downtime.Downtime) downtimeResponse {
+	return downtimeResponse{
+		ID:          d.ID,
+		Hostname:    d.HostName,
+		Service:     d.ServiceDescription,
+		Author:      d.Author,
+		Comment:     d.Comment,
+		EntryTime:   d.EntryTime,
+		StartTime:   d.StartTime,
+		EndTime:     d.EndTime,
+		Fixed:       d.Fixed,
+		Duration:    d.Duration,
+		TriggeredBy: d.TriggeredBy,
+		IsInEffect:  d.IsInEffect,
+	}
+}
+
+func writeDowntimes(w http.ResponseWriter, status int, downtimes []downtime.Downtime) {
+	res := make([]downtimeResponse, 0, len(downtimes))
+	for _, d := range downtimes {
+		res = append(res, toDowntimeResponse(d))
+	}
+
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(out)
+}
+
+func writeDowntimeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, statusdata.ErrUnknownHost),
+		errors.Is(err, statusdata.ErrUnknownService),
+		errors.Is(err, statusdata.ErrUnknownDowntime):
+		http.Error(w, http.StatusText(404), 404)
+	case errors.Is(err, downtime.ErrInvalidDowntime),
+		errors.Is(err, cmd.ErrInvalidArgument),
+		errors.Is(err, cmd.ErrCommandTooLong):
+		http.Error(w, err.Error(), 400)
+	default:
+		http.Error(w, http.StatusText(500), 500)
+	}
+}
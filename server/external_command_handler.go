@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/command"
+)
+
+type ExternalCommandService interface {
+	Submit(name string, args []string) error
+}
+
+// RegisterExternalCommandService sets up the /commands route for queueing
+// allow-listed nagios external commands.
+func (s *Server) RegisterExternalCommandService(svc ExternalCommandService) {
+	s.mux.Post("/commands", handleExternalCommand(svc))
+}
+
+type externalCommandRequest struct {
+	Command string            `json:"command"`
+	Args    []json.RawMessage `json:"args"`
+}
+
+type externalCommandError struct {
+	Error    string `json:"error"`
+	Command  string `json:"command,omitempty"`
+	Argument string `json:"argument,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func handleExternalCommand(svc ExternalCommandService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req externalCommandRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeExternalCommandError(w, 400, externalCommandError{Error: "invalid request body"})
+			return
+		}
+		defer r.Body.Close()
+
+		args := make([]string, 0, len(req.Args))
+		for i, raw := range req.Args {
+			arg, err := externalCommandArg(raw)
+			if err != nil {
+				writeExternalCommandError(w, 400, externalCommandError{
+					Error:    cmd.ErrInvalidArgument.Error(),
+					Command:  req.Command,
+					Argument: fmt.Sprintf("%d", i),
+					Reason:   err.Error(),
+				})
+				return
+			}
+			args = append(args, arg)
+		}
+
+		if err := svc.Submit(req.Command, args); err != nil {
+			var argErr *cmd.ArgumentError
+			var deliveryErr *cmd.DeliveryError
+			switch {
+			case errors.As(err, &argErr):
+				writeExternalCommandError(w, 400, externalCommandError{
+					Error:    cmd.ErrInvalidArgument.Error(),
+					Command:  argErr.Command,
+					Argument: argErr.Argument,
+					Reason:   argErr.Reason,
+				})
+			case errors.Is(err, cmd.ErrUnknownCommand):
+				writeExternalCommandError(w, 400, externalCommandError{
+					Error:   cmd.ErrUnknownCommand.Error(),
+					Command: req.Command,
+				})
+			case errors.Is(err, cmd.ErrCommandTooLong):
+				writeExternalCommandError(w, 400, externalCommandError{
+					Error:   cmd.ErrCommandTooLong.Error(),
+					Command: req.Command,
+				})
+			case errors.Is(err, command.ErrNotAllowed):
+				writeExternalCommandError(w, 403, externalCommandError{
+					Error:   command.ErrNotAllowed.Error(),
+					Command: req.Command,
+				})
+			// a DeliveryError also matches the errors of each target, so is
+			// checked before them.
+			case errors.As(err, &deliveryErr):
+				writeExternalCommandError(w, 502, externalCommandError{
+					Error:   cmd.ErrDeliveryFailed.Error(),
+					Command: req.Command,
+					Reason:  deliveryErr.Error(),
+				})
+			case errors.Is(err, cmd.ErrQueueFull):
+				writeExternalCommandError(w, 503, externalCommandError{
+					Error:   cmd.ErrQueueFull.Error(),
+					Command: req.Command,
+					Reason:  "the external commands queue is full, retry later",
+				})
+			case errors.Is(err, cmd.ErrRejected):
+				http.Error(w, err.Error(), 422)
+			default:
+				http.Error(w, http.StatusText(500), 500)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`"ok"`))
+	}
+}
+
+// externalCommandArg converts a JSON string, number or bool into the form
+// used in the external commands file.
+func externalCommandArg(raw json.RawMessage) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	default:
+		return "", fmt.Errorf("must be a string, number or bool")
+	}
+}
+
+func writeExternalCommandError(w http.ResponseWriter, status int, res externalCommandError) {
+	out, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(out)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/jamesmichael/nagiosapi/service/heartbeat"
+)
+
+type HeartbeatService interface {
+	Heartbeats() []heartbeat.Heartbeat
+	Overdue() []heartbeat.Heartbeat
+	Forget(hostname, serviceName string) bool
+}
+
+type heartbeatResponse struct {
+	Hostname      string `json:"hostname"`
+	ServiceName   string `json:"service_name"`
+	Interval      int64  `json:"interval"`
+	Configured    bool   `json:"configured"`
+	LastSeenTime  int64  `json:"last_seen_time"`
+	Overdue       bool   `json:"overdue"`
+	LastAlertTime int64  `json:"last_alert_time"`
+}
+
+// RegisterHeartbeatService sets up the /heartbeats routes, for listing the
+// hosts and services which results are expected for, and those which are
+// overdue, and for no longer expecting them.
+func (s *Server) RegisterHeartbeatService(svc HeartbeatService) {
+	s.mux.Get("/heartbeats", handleListHeartbeats(svc.Heartbeats))
+	s.mux.Get("/heartbeats/overdue", handleListHeartbeats(svc.Overdue))
+	s.mux.Delete("/heartbeats/{host}", handleForgetHeartbeat(svc))
+	s.mux.Delete("/heartbeats/{host}/{service}", handleForgetHeartbeat(svc))
+}
+
+func handleListHeartbeats(list func() []heartbeat.Heartbeat) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		heartbeats := list()
+
+		res := make([]heartbeatResponse, 0, len(heartbeats))
+		for _, h := range heartbeats {
+			res = append(res, heartbeatResponse{
+				Hostname:      h.Hostname,
+				ServiceName:   h.ServiceName,
+				Interval:      int64(h.Interval / time.Second),
+				Configured:    h.Configured,
+				LastSeenTime:  timestamp(h.LastSeen),
+				Overdue:       h.Overdue,
+				LastAlertTime: timestamp(h.LastAlert),
+			})
+		}
+
+		out, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+	}
+}
+
+func handleForgetHeartbeat(svc HeartbeatService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !svc.Forget(chi.URLParam(r, "host"), chi.URLParam(r, "service")) {
+			http.Error(w, http.StatusText(404), 404)
+			return
+		}
+
+		w.Write([]byte(`"ok"`))
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"go.uber.org/zap"
+)
+
+// RegisterNRDPService sets up the /nrdp route, which accepts passive check
+// results from NRDP clients, such as NCPA and send_nrdp.
+//
+// Clients authenticate with one of the given tokens, instead of with basic
+// authentication.
+func (s *Server) RegisterNRDPService(svc PassiveCommandService, tokens []string) {
+	s.nrdp = handleNRDP(s.log, svc, tokens)
+}
+
+// nrdpCheckResults is the XMLDATA payload of a submitcheck command.
+type nrdpCheckResults struct {
+	XMLName      xml.Name          `xml:"checkresults"`
+	CheckResults []nrdpCheckResult `xml:"checkresult"`
+}
+
+type nrdpCheckResult struct {
+	Type        string `xml:"type,attr"`
+	Hostname    string `xml:"hostname"`
+	ServiceName string `xml:"servicename"`
+	State       string `xml:"state"`
+	Output      string `xml:"output"`
+	Time        string `xml:"time"`
+}
+
+// nrdpJSONCheckResults is the JSONDATA payload of a submitcheck command.
+type nrdpJSONCheckResults struct {
+	CheckResults []struct {
+		CheckResult struct {
+			Type string `json:"type"`
+		} `json:"checkresult"`
+		Hostname    string          `json:"hostname"`
+		ServiceName string          `json:"servicename"`
+		State       json.RawMessage `json:"state"`
+		Output      string          `json:"output"`
+		Time        json.RawMessage `json:"time"`
+	} `json:"checkresults"`
+}
+
+type nrdpResult struct {
+	XMLName xml.Name  `xml:"result" json:"-"`
+	Status  int       `xml:"status" json:"status"`
+	Message string    `xml:"message" json:"message"`
+	Meta    *nrdpMeta `xml:"meta,omitempty" json:"meta,omitempty"`
+}
+
+type nrdpMeta struct {
+	Output string `xml:"output" json:"output"`
+}
+
+func handleNRDP(l *zap.Logger, svc PassiveCommandService, tokens []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the response is JSON if the request was, or was asked for.
+		asJSON := r.FormValue("JSONDATA") != "" || r.FormValue("format") == "json"
+
+		if !nrdpAuthenticated(r.FormValue("token"), tokens) {
+			message := "BAD TOKEN"
+			if r.FormValue("token") == "" {
+				message = "NO TOKEN"
+			}
+			writeNRDPResult(w, asJSON, nrdpResult{Status: -1, Message: message})
+			return
+		}
+
+		command := r.FormValue("cmd")
+		if command == "" {
+			command = r.FormValue("cmdtype")
+		}
+
+		switch command {
+		case "":
+			writeNRDPResult(w, asJSON, nrdpResult{Status: -1, Message: "NO COMMAND SPECIFIED"})
+			return
+		case "hello":
+			writeNRDPResult(w, asJSON, nrdpResult{Status: 0, Message: "OK", Meta: &nrdpMeta{Output: "nagios-api"}})
+			return
+		case "submitcheck":
+		default:
+			writeNRDPResult(w, asJSON, nrdpResult{Status: -1, Message: "BAD COMMAND"})
+			return
+		}
+
+		results, err := nrdpParseCheckResults(r.FormValue("XMLDATA"), r.FormValue("JSONDATA"))
+		if err != nil {
+			writeNRDPResult(w, asJSON, nrdpResult{Status: -1, Message: err.Error()})
+			return
+		}
+
+		failed := 0
+		for _, res := range results {
+			if err := nrdpSubmit(svc, res); err != nil {
+				failed++
+				l.Warn("unable to submit NRDP check result",
+					zap.String("host", res.Hostname),
+					zap.String("service", res.ServiceName),
+					zap.Error(err),
+				)
+			}
+		}
+
+		if failed > 0 {
+			writeNRDPResult(w, asJSON, nrdpResult{
+				Status:  -1,
+				Message: fmt.Sprintf("%d OF %d CHECKS FAILED", failed, len(results)),
+				Meta:    &nrdpMeta{Output: fmt.Sprintf("%d checks processed.", len(results)-failed)},
+			})
+			return
+		}
+
+		writeNRDPResult(w, asJSON, nrdpResult{
+			Status:  0,
+			Message: "OK",
+			Meta:    &nrdpMeta{Output: fmt.Sprintf("%d checks processed.", len(results))},
+		})
+	}
+}
+
+// nrdpAuthenticated checks the token against each of the configured tokens.
+func nrdpAuthenticated(token string, tokens []string) bool {
+	if token == "" {
+		return false
+	}
+
+	ok := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// nrdpParseCheckResults parses the XMLDATA or JSONDATA of a submitcheck
+// command into check results.
+func nrdpParseCheckResults(xmlData, jsonData string) ([]nrdpCheckResult, error) {
+	switch {
+	case xmlData != "":
+		var data nrdpCheckResults
+		if err := xml.Unmarshal([]byte(xmlData), &data); err != nil {
+			return nil, fmt.Errorf("BAD XML")
+		}
+		return data.CheckResults, nil
+
+	case jsonData != "":
+		var data nrdpJSONCheckResults
+		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
+			return nil, fmt.Errorf("BAD JSON")
+		}
+
+		res := make([]nrdpCheckResult, 0, len(data.CheckResults))
+		for _, c := range data.CheckResults {
+			res = append(res, nrdpCheckResult{
+				Type:        c.CheckResult.Type,
+				Hostname:    c.Hostname,
+				ServiceName: c.ServiceName,
+				State:       nrdpString(c.State),
+				Output:      c.Output,
+				Time:        nrdpString(c.Time),
+			})
+		}
+		return res, nil
+
+	default:
+		return nil, fmt.Errorf("NO DATA")
+	}
+}
+
+// nrdpString converts a JSON string or number into a string, as NRDP clients
+// send states and times as either.
+func nrdpString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// nrdpSubmit submits a single NRDP check result.
+func nrdpSubmit(svc PassiveCommandService, res nrdpCheckResult) error {
+	var checkTime int64
+	if t := strings.TrimSpace(res.Time); t != "" {
+		var err error
+		if checkTime, err = strconv.ParseInt(t, 10, 64); err != nil {
+			return fmt.Errorf("invalid time '%s'", t)
+		}
+	}
+
+	switch strings.ToLower(res.Type) {
+	case "host":
+		state, err := xdata.ParseHostState([]byte(res.State))
+		if err != nil {
+			return fmt.Errorf("invalid host state '%s'", res.State)
+		}
+		_, err = svc.SubmitHostResult(checkTime, state, res.Hostname, res.Output)
+		return err
+
+	case "service":
+		state, err := xdata.ParseServiceState([]byte(res.State))
+		if err != nil {
+			return fmt.Errorf("invalid service state '%s'", res.State)
+		}
+		_, err = svc.SubmitResult(checkTime, state, res.Hostname, res.ServiceName, res.Output)
+		return err
+
+	default:
+		return fmt.Errorf("unknown check result type '%s'", res.Type)
+	}
+}
+
+func writeNRDPResult(w http.ResponseWriter, asJSON bool, res nrdpResult) {
+	if asJSON {
+		out, err := json.Marshal(struct {
+			Result nrdpResult `json:"result"`
+		}{res})
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+		return
+	}
+
+	out, err := xml.Marshal(res)
+	if err != nil {
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
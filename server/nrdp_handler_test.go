@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"go.uber.org/zap"
+)
+
+// passiveResult is a passive check result passed to a
+// recordingPassiveService. Host results have no ServiceName.
+type passiveResult struct {
+	Time        int64
+	Hostname    string
+	ServiceName string
+	State       int
+	Output      string
+}
+
+type recordingPassiveService struct {
+	err     error
+	results []passiveResult
+}
+
+func (s *recordingPassiveService) SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error) {
+	s.results = append(s.results, passiveResult{time, hostname, serviceName, int(state), body})
+	return nil, s.err
+}
+
+func (s *recordingPassiveService) SubmitHostResult(time int64, state xdata.HostState, hostname, body string) ([]cmd.Delivery, error) {
+	s.results = append(s.results, passiveResult{time, hostname, "", int(state), body})
+	return nil, s.err
+}
+
+func TestHandleNRDP(t *testing.T) {
+	const (
+		hostXML = `<?xml version='1.0'?>
+<checkresults>
+  <checkresult type="host">
+    <hostname>web01</hostname>
+    <state>1</state>
+    <output>PING CRITICAL - Packet loss = 100%</output>
+    <time>1600000000</time>
+  </checkresult>
+</checkresults>`
+		serviceXML = `<checkresults>
+  <checkresult type="service">
+    <hostname>web01</hostname>
+    <servicename>HTTP</servicename>
+    <state>2</state>
+    <output>down|'time'=10s</output>
+  </checkresult>
+  <checkresult type="service">
+    <hostname>web01</hostname>
+    <servicename>Load</servicename>
+    <state>WARNING</state>
+    <output>load is high</output>
+  </checkresult>
+</checkresults>`
+		hostJSON    = `{"checkresults": [{"checkresult": {"type": "host"}, "hostname": "web01", "state": "0", "output": "up", "time": 1600000000}]}`
+		serviceJSON = `{"checkresults": [{"checkresult": {"type": "service"}, "hostname": "web01", "servicename": "HTTP", "state": 2, "output": "down"}]}`
+	)
+
+	xmlResult := func(body string) string { return xml.Header + "<result>" + body + "</result>" }
+
+	tests := []struct {
+		name     string
+		form     url.Values
+		expected []passiveResult
+		body     string
+	}{
+		{
+			"host xml",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "XMLDATA": {hostXML}},
+			[]passiveResult{{1600000000, "web01", "", int(xdata.Down), "PING CRITICAL - Packet loss = 100%"}},
+			xmlResult("<status>0</status><message>OK</message><meta><output>1 checks processed.</output></meta>"),
+		},
+		{
+			"service xml",
+			url.Values{"token": {"other"}, "cmdtype": {"submitcheck"}, "XMLDATA": {serviceXML}},
+			[]passiveResult{
+				{0, "web01", "HTTP", int(xdata.Critical), "down|'time'=10s"},
+				{0, "web01", "Load", int(xdata.Warning), "load is high"},
+			},
+			xmlResult("<status>0</status><message>OK</message><meta><output>2 checks processed.</output></meta>"),
+		},
+		{
+			"host json",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "JSONDATA": {hostJSON}},
+			[]passiveResult{{1600000000, "web01", "", int(xdata.Up), "up"}},
+			`{"result":{"status":0,"message":"OK","meta":{"output":"1 checks processed."}}}`,
+		},
+		{
+			"service json",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "JSONDATA": {serviceJSON}},
+			[]passiveResult{{0, "web01", "HTTP", int(xdata.Critical), "down"}},
+			`{"result":{"status":0,"message":"OK","meta":{"output":"1 checks processed."}}}`,
+		},
+		{
+			"invalid checks",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "format": {"json"}, "XMLDATA": {
+				`<checkresults><checkresult type="host"><hostname>web01</hostname><state>OK</state></checkresult>` +
+					`<checkresult type="service"><hostname>web01</hostname><servicename>HTTP</servicename><state>0</state><time>soon</time></checkresult>` +
+					`<checkresult type="other"><hostname>web01</hostname><state>0</state></checkresult>` +
+					`<checkresult type="service"><hostname>web01</hostname><servicename>HTTP</servicename><state>0</state></checkresult></checkresults>`,
+			}},
+			[]passiveResult{{0, "web01", "HTTP", int(xdata.Ok), ""}},
+			`{"result":{"status":-1,"message":"3 OF 4 CHECKS FAILED","meta":{"output":"1 checks processed."}}}`,
+		},
+		{
+			"hello",
+			url.Values{"token": {"secret"}, "cmd": {"hello"}},
+			nil,
+			xmlResult("<status>0</status><message>OK</message><meta><output>nagios-api</output></meta>"),
+		},
+		{
+			"no token",
+			url.Values{"cmd": {"submitcheck"}, "XMLDATA": {hostXML}},
+			nil,
+			xmlResult("<status>-1</status><message>NO TOKEN</message>"),
+		},
+		{
+			"bad token",
+			url.Values{"token": {"wrong"}, "cmd": {"submitcheck"}, "JSONDATA": {hostJSON}},
+			nil,
+			`{"result":{"status":-1,"message":"BAD TOKEN"}}`,
+		},
+		{
+			"no command",
+			url.Values{"token": {"secret"}, "XMLDATA": {hostXML}},
+			nil,
+			xmlResult("<status>-1</status><message>NO COMMAND SPECIFIED</message>"),
+		},
+		{
+			"bad command",
+			url.Values{"token": {"secret"}, "cmd": {"submitcmd"}},
+			nil,
+			xmlResult("<status>-1</status><message>BAD COMMAND</message>"),
+		},
+		{
+			"no data",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}},
+			nil,
+			xmlResult("<status>-1</status><message>NO DATA</message>"),
+		},
+		{
+			"bad xml",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "XMLDATA": {"<checkresults><checkresult>"}},
+			nil,
+			xmlResult("<status>-1</status><message>BAD XML</message>"),
+		},
+		{
+			"bad json",
+			url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "JSONDATA": {`{"checkresults": [`}},
+			nil,
+			`{"result":{"status":-1,"message":"BAD JSON"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		svc := &recordingPassiveService{}
+		h := handleNRDP(zap.NewNop(), svc, []string{"secret", "other"})
+
+		r := httptest.NewRequest("POST", "/nrdp/", strings.NewReader(test.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: unexpected status, got: %d, want: %d", test.name, w.Code, http.StatusOK)
+		}
+		if body := w.Body.String(); body != test.body {
+			t.Errorf("%s: unexpected body, got: '%s', want: '%s'", test.name, body, test.body)
+		}
+
+		if len(svc.results) != len(test.expected) {
+			t.Errorf("%s: unexpected results, got: '%v', want: '%v'", test.name, svc.results, test.expected)
+			continue
+		}
+		for i, res := range svc.results {
+			if res != test.expected[i] {
+				t.Errorf("%s: unexpected result, got: '%v', want: '%v'", test.name, res, test.expected[i])
+			}
+		}
+	}
+}
+
+func TestHandleNRDP_SubmitFailed(t *testing.T) {
+	svc := &recordingPassiveService{err: cmd.ErrQueueFull}
+	h := handleNRDP(zap.NewNop(), svc, []string{"secret"})
+
+	form := url.Values{"token": {"secret"}, "cmd": {"submitcheck"}, "XMLDATA": {
+		`<checkresults><checkresult type="host"><hostname>web01</hostname><state>0</state></checkresult></checkresults>`,
+	}}
+	r := httptest.NewRequest("POST", "/nrdp/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h(w, r)
+
+	expected := xml.Header + "<result><status>-1</status><message>1 OF 1 CHECKS FAILED</message><meta><output>0 checks processed.</output></meta></result>"
+	if body := w.Body.String(); body != expected {
+		t.Errorf("unexpected body, got: '%s', want: '%s'", body, expected)
+	}
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/availability"
+	"github.com/jamesmichael/nagiosapi/service/report"
+)
+
+// maxReportSpan limits the window of an availability report, as every log
+// archive in the window is read into memory.
+const maxReportSpan = 31 * 24 * time.Hour
+
+type ReportService interface {
+	Availability(req report.AvailabilityRequest) (*report.AvailabilityReport, error)
+}
+
+// RegisterReportService sets up /reports/availability route for generating
+// availability reports from the nagios state history.
+func (s *Server) RegisterReportService(svc ReportService) {
+	s.mux.Get("/reports/availability", handleAvailabilityReport(svc))
+}
+
+type stateTimeResponse struct {
+	Time            int64   `json:"time"`
+	ScheduledTime   int64   `json:"scheduled_time"`
+	UnscheduledTime int64   `json:"unscheduled_time"`
+	Percent         float64 `json:"percent"`
+}
+
+type availabilityReportResponse struct {
+	Hostname string                       `json:"hostname"`
+	Service  string                       `json:"service,omitempty"`
+	States   map[string]stateTimeResponse `json:"states"`
+}
+
+type groupReportResponse struct {
+	Name    string                       `json:"name"`
+	Members []availabilityReportResponse `json:"members"`
+	Average map[string]float64           `json:"average"`
+}
+
+type availabilityResponse struct {
+	Start   int64                        `json:"start"`
+	End     int64                        `json:"end"`
+	Reports []availabilityReportResponse `json:"reports,omitempty"`
+	Groups  []groupReportResponse        `json:"groups,omitempty"`
+}
+
+func handleAvailabilityReport(svc ReportService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseAvailabilityRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		res, err := svc.Availability(req)
+		if err != nil {
+			if errors.Is(err, report.ErrUnknownGroup) {
+				http.Error(w, http.StatusText(404), 404)
+				return
+			}
+			if errors.Is(err, report.ErrGroupsUnavailable) {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		services := req.Type == report.Services || req.Type == report.ServiceGroups
+		if wantsCSV(r) {
+			w.Header().Add("Content-Type", "text/csv; charset=utf-8")
+			writeAvailabilityCSV(w, res, services)
+			return
+		}
+
+		out, err := json.Marshal(buildAvailabilityResponse(res, services))
+		if err != nil {
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json; charset=utf-8")
+		w.Write(out)
+	}
+}
+
+func parseAvailabilityRequest(r *http.Request) (report.AvailabilityRequest, error) {
+	q := r.URL.Query()
+
+	req := report.AvailabilityRequest{
+		HostName:           q.Get("host"),
+		ServiceDescription: q.Get("service"),
+		Options:            availability.DefaultOptions(),
+	}
+
+	switch {
+	case q.Get("type") != "":
+		t, err := report.ParseSubjectType(q.Get("type"))
+		if err != nil {
+			return req, err
+		}
+		req.Type = t
+	case q.Get("hostgroup") != "":
+		req.Type = report.HostGroups
+	case q.Get("servicegroup") != "":
+		req.Type = report.ServiceGroups
+	case req.ServiceDescription != "":
+		req.Type = report.Services
+	}
+
+	switch req.Type {
+	case report.HostGroups:
+		req.Group = q.Get("hostgroup")
+	case report.ServiceGroups:
+		req.Group = q.Get("servicegroup")
+	}
+
+	now := time.Now()
+	end, err := parseReportTime(q.Get("end"), now)
+	if err != nil {
+		return req, fmt.Errorf("invalid end: %w", err)
+	}
+	start, err := parseReportTime(q.Get("start"), end.Add(-24*time.Hour))
+	if err != nil {
+		return req, fmt.Errorf("invalid start: %w", err)
+	}
+	if end.After(now) {
+		end = now
+	}
+	if !end.After(start) {
+		return req, fmt.Errorf("end must be after start")
+	}
+	if end.Sub(start) > maxReportSpan {
+		return req, fmt.Errorf("reports must not span more than %d days", maxReportSpan/(24*time.Hour))
+	}
+	req.Start, req.End = start, end
+
+	bools := []struct {
+		name string
+		dest *bool
+	}{
+		{"include_soft_states", &req.Options.IncludeSoftStates},
+		{"assume_initial_states", &req.Options.AssumeInitialStates},
+		{"assume_state_retention", &req.Options.AssumeStateRetention},
+		{"assume_states_during_program_downtime", &req.Options.AssumeStatesDuringProgramDowntime},
+		{"downtime_as_ok", &req.Options.ScheduledDowntimeAsOK},
+	}
+	for _, b := range bools {
+		if v := q.Get(b.name); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return req, fmt.Errorf("invalid %s: %w", b.name, err)
+			}
+			*b.dest = parsed
+		}
+	}
+
+	if v := q.Get("initial_host_state"); v != "" {
+		if err := parseInitialState(v, &req, &req.Options.InitialHostState, func(b []byte) (int, error) {
+			st, err := xdata.ParseHostState(b)
+			return int(st), err
+		}); err != nil {
+			return req, fmt.Errorf("invalid initial_host_state: %w", err)
+		}
+	}
+
+	if v := q.Get("initial_service_state"); v != "" {
+		if err := parseInitialState(v, &req, &req.Options.InitialServiceState, func(b []byte) (int, error) {
+			st, err := xdata.ParseServiceState(b)
+			return int(st), err
+		}); err != nil {
+			return req, fmt.Errorf("invalid initial_service_state: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// parseInitialState handles the avail.cgi 'first assumed state' options,
+// which can be a specific state, 'unspecified' or 'current'.
+func parseInitialState(v string, req *report.AvailabilityRequest, dest *int, parse func([]byte) (int, error)) error {
+	switch strings.ToLower(v) {
+	case "unspecified":
+		*dest = availability.Undetermined
+		return nil
+	case "current":
+		req.AssumeCurrentState = true
+		return nil
+	}
+
+	st, err := parse([]byte(v))
+	if err != nil {
+		return err
+	}
+	*dest = st
+	return nil
+}
+
+// parseReportTime accepts either a unix timestamp or an RFC3339 time.
+func parseReportTime(v string, def time.Time) (time.Time, error) {
+	if v == "" {
+		return def, nil
+	}
+
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, v)
+}
+
+func wantsCSV(r *http.Request) bool {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f == "csv"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+// reportStates lists the states to report on, followed by Undetermined.
+func reportStates(services bool) []int {
+	states := availability.HostStates
+	if services {
+		states = availability.ServiceStates
+	}
+
+	res := make([]int, 0, len(states)+1)
+	res = append(res, states...)
+	return append(res, availability.Undetermined)
+}
+
+func stateName(state int, services bool) string {
+	if state == availability.Undetermined {
+		return "UNDETERMINED"
+	}
+	if services {
+		return xdata.ServiceState(state).String()
+	}
+	return xdata.HostState(state).String()
+}
+
+func buildReportResponse(rep *availability.Report, services bool) availabilityReportResponse {
+	res := availabilityReportResponse{
+		Hostname: rep.HostName,
+		Service:  rep.ServiceDescription,
+		States:   make(map[string]stateTimeResponse),
+	}
+
+	for _, st := range reportStates(services) {
+		t := rep.Time(st)
+		res.States[stateName(st, services)] = stateTimeResponse{
+			Time:            t.Total(),
+			ScheduledTime:   t.Scheduled,
+			UnscheduledTime: t.Unscheduled,
+			Percent:         rep.Percent(st),
+		}
+	}
+
+	return res
+}
+
+func buildAvailabilityResponse(rep *report.AvailabilityReport, services bool) availabilityResponse {
+	res := availabilityResponse{
+		Start: rep.Start.Unix(),
+		End:   rep.End.Unix(),
+	}
+
+	for _, r := range rep.Reports {
+		res.Reports = append(res.Reports, buildReportResponse(r, services))
+	}
+
+	for _, g := range rep.Groups {
+		group := groupReportResponse{
+			Name:    g.Name,
+			Members: make([]availabilityReportResponse, 0, len(g.Members)),
+			Average: make(map[string]float64),
+		}
+
+		for _, m := range g.Members {
+			group.Members = append(group.Members, buildReportResponse(m, services))
+		}
+
+		for _, st := range reportStates(services) {
+			var total float64
+			for _, m := range g.Members {
+				total += m.Percent(st)
+			}
+			if len(g.Members) > 0 {
+				total /= float64(len(g.Members))
+			}
+			group.Average[stateName(st, services)] = total
+		}
+
+		res.Groups = append(res.Groups, group)
+	}
+
+	return res
+}
+
+func writeAvailabilityCSV(w http.ResponseWriter, rep *report.AvailabilityReport, services bool) {
+	states := reportStates(services)
+
+	header := []string{"group", "hostname", "service", "start", "end"}
+	for _, st := range states {
+		name := strings.ToLower(stateName(st, services))
+		header = append(header,
+			name+"_time",
+			name+"_scheduled_time",
+			name+"_percent",
+		)
+	}
+
+	out := csv.NewWriter(w)
+	out.Write(header)
+
+	write := func(group string, r *availability.Report) {
+		row := []string{
+			group,
+			r.HostName,
+			r.ServiceDescription,
+			strconv.FormatInt(r.Start, 10),
+			strconv.FormatInt(r.End, 10),
+		}
+		for _, st := range states {
+			t := r.Time(st)
+			row = append(row,
+				strconv.FormatInt(t.Total(), 10),
+				strconv.FormatInt(t.Scheduled, 10),
+				strconv.FormatFloat(r.Percent(st), 'f', 3, 64),
+			)
+		}
+		out.Write(row)
+	}
+
+	for _, r := range rep.Reports {
+		write("", r)
+	}
+	for _, g := range rep.Groups {
+		for _, m := range g.Members {
+			write(g.Name, m)
+		}
+	}
+
+	out.Flush()
+}
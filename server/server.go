@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"net"
 	"net/http"
 
 	"github.com/go-chi/chi"
@@ -17,6 +19,20 @@ type Server struct {
 	passiveCmdSvc PassiveCommandService
 	statusSvc     StatusService
 	mux           chi.Router
+	httpServer    *http.Server
+
+	// requests are cancelled on shutdown, so long waits, such as for a new
+	// downtime to appear, do not hold it up.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// nrdp is served outside of the API, and its basic authentication, as
+	// NRDP clients authenticate with a token.
+	nrdp http.Handler
+
+	// hooks are served outside of the basic authentication of the API, as
+	// each hook authenticates with its own secret or signature.
+	hooks http.Handler
 }
 
 type ServerOpt func(s *Server) error
@@ -34,6 +50,14 @@ func NewServer(opts ...ServerOpt) (*Server, error) {
 		}
 	}
 
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.httpServer = &http.Server{
+		Addr: s.addr,
+		BaseContext: func(net.Listener) context.Context {
+			return s.ctx
+		},
+	}
+
 	return s, nil
 }
 
@@ -53,6 +77,7 @@ func WithLog(l *zap.Logger) ServerOpt {
 	}
 }
 
+// ServeHTTP listens for requests until Shutdown is called.
 func (s *Server) ServeHTTP() {
 	router := chi.NewRouter()
 
@@ -61,30 +86,51 @@ func (s *Server) ServeHTTP() {
 		router.Use(cors.Handler)
 	}
 
-	auth := buildBasicAuthMiddleware()
-	if auth != nil {
-		router.Use(auth)
-	}
-
 	router.Use(
 		middleware.Logger,
-		middleware.RedirectSlashes,
 		middleware.Recoverer,
 	)
 
-	router.Route("/v1", func(r chi.Router) {
-		r.Mount("/api", s.mux)
+	// NRDP clients post to /nrdp/, so it is not redirected.
+	if s.nrdp != nil {
+		router.Handle("/nrdp", s.nrdp)
+		router.Handle("/nrdp/", s.nrdp)
+	}
+
+	if s.hooks != nil {
+		router.Mount("/v1/api/hooks", s.hooks)
+	}
+
+	auth := buildBasicAuthMiddleware()
+	router.Group(func(r chi.Router) {
+		if auth != nil {
+			r.Use(auth)
+		}
+		r.Use(middleware.RedirectSlashes)
+
+		r.Route("/v1", func(r chi.Router) {
+			r.Mount("/api", s.mux)
+		})
 	})
 
 	s.log.Info("starting HTTP API",
 		zap.String("addr", s.addr),
 	)
-	if err := http.ListenAndServe(s.addr, router); err != nil {
+	s.httpServer.Handler = router
+	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		s.log.Fatal("unexpected server failure",
 			zap.Error(err))
 	}
 }
 
+// Shutdown stops accepting new requests, cancels the context of active
+// requests, and waits for them to complete, or for ctx to be done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.log.Info("stopping HTTP API")
+	s.cancel()
+	return s.httpServer.Shutdown(ctx)
+}
+
 func buildBasicAuthMiddleware() func(next http.Handler) http.Handler {
 	if !viper.GetBool("basic_auth.enabled") {
 		return nil
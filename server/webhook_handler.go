@@ -0,0 +1,56 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/webhook"
+)
+
+// maxWebhookBody limits the size of a webhook payload.
+const maxWebhookBody = 1 << 20
+
+type WebhookService interface {
+	Submit(name string, header http.Header, token string, body []byte) (webhook.Check, []cmd.Delivery, error)
+}
+
+// RegisterWebhookService sets up the /hooks/{name} route, which submits the
+// JSON payloads posted to each configured hook as passive check results.
+//
+// Hooks are not behind basic authentication, as the services posting to
+// them authenticate with the secret or signature of each hook.
+func (s *Server) RegisterWebhookService(svc WebhookService) {
+	hooks := chi.NewRouter()
+	s.hooks = hooks
+
+	hooks.Post("/{name}", func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+		if err != nil {
+			http.Error(w, http.StatusText(413), 413)
+			return
+		}
+
+		_, deliveries, err := svc.Submit(chi.URLParam(r, "name"), r.Header, r.URL.Query().Get("token"), body)
+		switch {
+		case errors.Is(err, webhook.ErrUnknownHook):
+			http.NotFound(w, r)
+			return
+		case errors.Is(err, webhook.ErrUnauthorized):
+			http.Error(w, http.StatusText(401), 401)
+			return
+		case errors.Is(err, webhook.ErrInvalidPayload):
+			writeSubmitError(w, 400, submitError{
+				Error:  "invalid request body",
+				Reason: err.Error(),
+			})
+			return
+		}
+
+		writeSubmitResult(w, deliveries, err)
+	})
+}
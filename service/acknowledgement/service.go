@@ -0,0 +1,227 @@
+package acknowledgement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+)
+
+var (
+	// ErrInvalidAcknowledgement is returned when an acknowledgement request fails validation.
+	ErrInvalidAcknowledgement = errors.New("invalid acknowledgement")
+
+	// ErrNoProblem is returned when acknowledging a host or service which is not in a problem state.
+	ErrNoProblem = errors.New("no problem to acknowledge")
+
+	// ErrNotAcknowledged is returned when removing an acknowledgement from a host or service which has not been
+	// acknowledged.
+	ErrNotAcknowledged = errors.New("problem has not been acknowledged")
+)
+
+// Request describes an acknowledgement of a host or service problem. Host
+// acknowledgements have an empty ServiceDescription.
+//
+// Normal acknowledgements are removed when the host or service changes
+// state, Sticky acknowledgements are kept until it recovers.
+type Request struct {
+	HostName           string
+	ServiceDescription string
+	Type               xdata.AcknowledgementType
+	Notify             bool
+	Persistent         bool
+	Author             string
+	Comment            string
+
+	// Expire removes the acknowledgement at the given time. It requires
+	// nagios 4.4 or newer.
+	Expire time.Time
+}
+
+// Acknowledgement represents the acknowledgement state of a host or service.
+type Acknowledgement struct {
+	HostName           string
+	ServiceDescription string
+	Type               xdata.AcknowledgementType
+	IsAcknowledged     bool
+}
+
+// Repository provides access to the current host and service states.
+type Repository interface {
+	HostStatus(host string) (*xdata.HostStatus, error)
+	ServiceStatus(host, name string) (*xdata.ServiceStatus, error)
+}
+
+// Service is used to acknowledge host and service problems through the
+// nagios external commands file.
+type Service struct {
+	externalCommandsFile io.Writer
+	repo                 Repository
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.externalCommandsFile == nil {
+		return nil, fmt.Errorf("must set external commands file")
+	}
+
+	if s.repo == nil {
+		return nil, fmt.Errorf("must set status repository")
+	}
+
+	return &s, nil
+}
+
+// Acknowledgement returns the current acknowledgement state of a host or
+// service.
+//
+// statusdata.ErrUnknownHost and statusdata.ErrUnknownService are returned if
+// the host or service do not exist.
+func (s *Service) Acknowledgement(host, service string) (Acknowledgement, error) {
+	ack := Acknowledgement{
+		HostName:           host,
+		ServiceDescription: service,
+	}
+
+	if service == "" {
+		st, err := s.repo.HostStatus(host)
+		if err != nil {
+			return ack, err
+		}
+		ack.Type = st.AcknowledgementType
+		ack.IsAcknowledged = st.ProblemHasBeenAcknowledged
+		return ack, nil
+	}
+
+	st, err := s.repo.ServiceStatus(host, service)
+	if err != nil {
+		return ack, err
+	}
+	ack.Type = st.AcknowledgementType
+	ack.IsAcknowledged = st.ProblemHasBeenAcknowledged
+	return ack, nil
+}
+
+// Acknowledge queues an ACKNOWLEDGE_HOST_PROBLEM or ACKNOWLEDGE_SVC_PROBLEM
+// command, or their _EXPIRE variants if the request has an expiry time.
+//
+// ErrNoProblem is returned if the host or service is currently UP or OK.
+func (s *Service) Acknowledge(req Request) error {
+	if err := s.validate(req); err != nil {
+		return err
+	}
+
+	switch {
+	case req.ServiceDescription == "" && req.Expire.IsZero():
+		return s.write(cmd.AcknowledgeHostProblem(req.HostName, req.Type, req.Notify, req.Persistent, req.Author, req.Comment))
+	case req.ServiceDescription == "":
+		return s.write(cmd.AcknowledgeHostProblemExpire(req.HostName, req.Type, req.Notify, req.Persistent, req.Expire, req.Author, req.Comment))
+	case req.Expire.IsZero():
+		return s.write(cmd.AcknowledgeSvcProblem(req.HostName, req.ServiceDescription, req.Type, req.Notify, req.Persistent, req.Author, req.Comment))
+	default:
+		return s.write(cmd.AcknowledgeSvcProblemExpire(req.HostName, req.ServiceDescription, req.Type, req.Notify, req.Persistent, req.Expire, req.Author, req.Comment))
+	}
+}
+
+// RemoveAcknowledgement queues a REMOVE_HOST_ACKNOWLEDGEMENT or
+// REMOVE_SVC_ACKNOWLEDGEMENT command.
+//
+// ErrNotAcknowledged is returned if the problem has not been acknowledged.
+func (s *Service) RemoveAcknowledgement(host, service string) error {
+	ack, err := s.Acknowledgement(host, service)
+	if err != nil {
+		return err
+	}
+
+	if !ack.IsAcknowledged {
+		return ErrNotAcknowledged
+	}
+
+	if service == "" {
+		return s.write(cmd.RemoveHostAcknowledgement(host))
+	}
+
+	return s.write(cmd.RemoveSvcAcknowledgement(host, service))
+}
+
+func (s *Service) validate(req Request) error {
+	if req.Type != xdata.Normal && req.Type != xdata.Sticky {
+		return fmt.Errorf("%w: type must be Normal or Sticky", ErrInvalidAcknowledgement)
+	}
+
+	if req.Author == "" {
+		return fmt.Errorf("%w: author must be set", ErrInvalidAcknowledgement)
+	}
+
+	if req.Comment == "" {
+		return fmt.Errorf("%w: comment must be set", ErrInvalidAcknowledgement)
+	}
+
+	if !req.Expire.IsZero() && !req.Expire.After(time.Now()) {
+		return fmt.Errorf("%w: expire time must be in the future", ErrInvalidAcknowledgement)
+	}
+
+	if req.ServiceDescription == "" {
+		st, err := s.repo.HostStatus(req.HostName)
+		if err != nil {
+			return err
+		}
+		if st.CurrentState == xdata.Up {
+			return ErrNoProblem
+		}
+		return nil
+	}
+
+	st, err := s.repo.ServiceStatus(req.HostName, req.ServiceDescription)
+	if err != nil {
+		return err
+	}
+	if st.CurrentState == xdata.Ok {
+		return ErrNoProblem
+	}
+	return nil
+}
+
+func (s *Service) write(command cmd.Command, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if _, err := command.WriteTo(s.externalCommandsFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithExternalCommandsWriter sets the external commands writer.
+//
+// It should be an instance of nagios/cmd, but for testing, anything which
+// implements io.Writer would work.
+func WithExternalCommandsWriter(w io.Writer) ServiceOption {
+	return func(s *Service) error {
+		s.externalCommandsFile = w
+		return nil
+	}
+}
+
+// WithRepository sets the repository used to look up host and service states.
+func WithRepository(r Repository) ServiceOption {
+	return func(s *Service) error {
+		s.repo = r
+		return nil
+	}
+}
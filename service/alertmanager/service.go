@@ -0,0 +1,252 @@
+package alertmanager
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"text/template"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/submission"
+)
+
+const (
+	// DefaultHostTemplate maps an alert to the host of its instance label.
+	DefaultHostTemplate = `{{ .Labels.instance | trimPort }}`
+
+	// DefaultServiceTemplate maps an alert to a service named after it.
+	DefaultServiceTemplate = `{{ .Labels.alertname }}`
+
+	// DefaultSeverityLabel is the label holding the severity of an alert.
+	DefaultSeverityLabel = "severity"
+)
+
+// DefaultSeverities maps the usual severity labels onto service states.
+var DefaultSeverities = map[string]xdata.ServiceState{
+	"critical": xdata.Critical,
+	"error":    xdata.Critical,
+	"warning":  xdata.Warning,
+	"info":     xdata.Ok,
+	"none":     xdata.Ok,
+}
+
+// Alert is a single alert sent by the Alertmanager webhook receiver. Status
+// is either "firing" or "resolved".
+type Alert struct {
+	Status      string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// Check is the passive service check result an alert was mapped onto.
+type Check struct {
+	Hostname    string
+	ServiceName string
+	State       xdata.ServiceState
+	Output      string
+}
+
+// Submitter submits passive service check results, such as
+// submission.Service.
+type Submitter interface {
+	SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error)
+}
+
+// Service maps Alertmanager alerts onto nagios passive service check
+// results.
+type Service struct {
+	submitter       Submitter
+	hostTemplate    *template.Template
+	serviceTemplate *template.Template
+	severityLabel   string
+	severities      map[string]xdata.ServiceState
+	defaultState    xdata.ServiceState
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{
+		hostTemplate:    template.Must(parseTemplate("host", DefaultHostTemplate)),
+		serviceTemplate: template.Must(parseTemplate("service", DefaultServiceTemplate)),
+		severityLabel:   DefaultSeverityLabel,
+		severities:      DefaultSeverities,
+		defaultState:    xdata.Warning,
+	}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.submitter == nil {
+		return nil, fmt.Errorf("must set submitter")
+	}
+
+	return &s, nil
+}
+
+// SubmitAlert maps an alert onto a passive service check result, and
+// submits it. The check is returned even if the submission fails.
+//
+// Firing alerts take the state of their severity, and resolved alerts are
+// OK. The output is the summary annotation, with the description as the
+// long output.
+//
+// A submission.FieldError is returned if the host or service can not be
+// mapped.
+func (s *Service) SubmitAlert(a Alert) (Check, []cmd.Delivery, error) {
+	check, err := s.Map(a)
+	if err != nil {
+		return check, nil, err
+	}
+
+	// like other results, the time of the check is when it is received.
+	deliveries, err := s.submitter.SubmitResult(0, check.State, check.Hostname, check.ServiceName, check.Output)
+	return check, deliveries, err
+}
+
+// Map maps an alert onto a passive service check result, without submitting
+// it.
+func (s *Service) Map(a Alert) (Check, error) {
+	var check Check
+
+	hostname, err := execute(s.hostTemplate, a)
+	if err != nil {
+		return check, &submission.FieldError{Field: "hostname", Reason: err.Error()}
+	}
+	check.Hostname = hostname
+
+	serviceName, err := execute(s.serviceTemplate, a)
+	if err != nil {
+		return check, &submission.FieldError{Field: "service_name", Reason: err.Error()}
+	}
+	check.ServiceName = serviceName
+
+	check.State = xdata.Ok
+	if a.Status != "resolved" {
+		state, ok := s.severities[strings.ToLower(a.Labels[s.severityLabel])]
+		if !ok {
+			state = s.defaultState
+		}
+		check.State = state
+	}
+
+	summary := a.Annotations["summary"]
+	if summary == "" {
+		summary = a.Labels["alertname"]
+	}
+	if a.Status == "resolved" {
+		summary = "RESOLVED: " + summary
+	}
+
+	// a '|' would be read as the start of performance data.
+	pipes := strings.NewReplacer("|", "/")
+	output, err := submission.RenderOutput(
+		pipes.Replace(summary),
+		pipes.Replace(a.Annotations["description"]),
+		nil,
+	)
+	if err != nil {
+		return check, err
+	}
+	check.Output = output
+
+	return check, nil
+}
+
+// funcs are the functions available to the host and service templates.
+var funcs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"trimPort": func(s string) string {
+		if host, _, err := net.SplitHostPort(s); err == nil {
+			return host
+		}
+		return s
+	},
+}
+
+// parseTemplate parses a host or service template. Missing labels are
+// rendered as empty strings.
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+}
+
+func execute(t *template.Template, a Alert) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, a); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithSubmitter sets where the passive check results are submitted.
+func WithSubmitter(sub Submitter) ServiceOption {
+	return func(s *Service) error {
+		s.submitter = sub
+		return nil
+	}
+}
+
+// WithHostTemplate sets the template used to map an alert onto a host. The
+// template is executed with the Alert, such as '{{ .Labels.instance }}'.
+func WithHostTemplate(text string) ServiceOption {
+	return func(s *Service) error {
+		t, err := parseTemplate("host", text)
+		if err != nil {
+			return fmt.Errorf("invalid host template: %w", err)
+		}
+		s.hostTemplate = t
+		return nil
+	}
+}
+
+// WithServiceTemplate sets the template used to map an alert onto a
+// service.
+func WithServiceTemplate(text string) ServiceOption {
+	return func(s *Service) error {
+		t, err := parseTemplate("service", text)
+		if err != nil {
+			return fmt.Errorf("invalid service template: %w", err)
+		}
+		s.serviceTemplate = t
+		return nil
+	}
+}
+
+// WithSeverityLabel sets the label holding the severity of an alert.
+func WithSeverityLabel(label string) ServiceOption {
+	return func(s *Service) error {
+		if label == "" {
+			return fmt.Errorf("severity label must be set")
+		}
+		s.severityLabel = label
+		return nil
+	}
+}
+
+// WithSeverities sets the state of firing alerts with each severity, and of
+// those with any other severity. Severities are matched case insensitively.
+func WithSeverities(severities map[string]xdata.ServiceState, defaultState xdata.ServiceState) ServiceOption {
+	return func(s *Service) error {
+		m := make(map[string]xdata.ServiceState, len(severities))
+		for severity, state := range severities {
+			if state.String() == "" {
+				return fmt.Errorf("invalid state for severity '%s'", severity)
+			}
+			m[strings.ToLower(severity)] = state
+		}
+		if defaultState.String() == "" {
+			return fmt.Errorf("invalid default state")
+		}
+
+		s.severities = m
+		s.defaultState = defaultState
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+package alertmanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/submission"
+)
+
+type nopSubmitter struct{}
+
+func (nopSubmitter) SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error) {
+	return nil, nil
+}
+
+func TestService_Map(t *testing.T) {
+	firing := func(severity string) Alert {
+		return Alert{
+			Status:      "firing",
+			Labels:      map[string]string{"alertname": "HighLoad", "instance": "web01:9100", "severity": severity},
+			Annotations: map[string]string{"summary": "load is high"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ServiceOption
+		alert    Alert
+		expected Check
+	}{
+		{"critical", nil, firing("critical"), Check{"web01", "HighLoad", xdata.Critical, "load is high"}},
+		{"error", nil, firing("error"), Check{"web01", "HighLoad", xdata.Critical, "load is high"}},
+		{"warning", nil, firing("Warning"), Check{"web01", "HighLoad", xdata.Warning, "load is high"}},
+		{"info", nil, firing("info"), Check{"web01", "HighLoad", xdata.Ok, "load is high"}},
+		{"unknown severity", nil, firing("page"), Check{"web01", "HighLoad", xdata.Warning, "load is high"}},
+		{"no severity", nil, firing(""), Check{"web01", "HighLoad", xdata.Warning, "load is high"}},
+		{
+			"default state",
+			[]ServiceOption{WithSeverities(map[string]xdata.ServiceState{"Page": xdata.Critical}, xdata.Unknown)},
+			firing("warning"),
+			Check{"web01", "HighLoad", xdata.Unknown, "load is high"},
+		},
+		{
+			"custom severities",
+			[]ServiceOption{WithSeverities(map[string]xdata.ServiceState{"Page": xdata.Critical}, xdata.Unknown)},
+			firing("page"),
+			Check{"web01", "HighLoad", xdata.Critical, "load is high"},
+		},
+		{
+			"severity label",
+			[]ServiceOption{WithSeverityLabel("priority")},
+			Alert{Status: "firing", Labels: map[string]string{"alertname": "HighLoad", "instance": "web01", "priority": "critical", "severity": "info"}},
+			Check{"web01", "HighLoad", xdata.Critical, "HighLoad"},
+		},
+		{
+			"resolved",
+			nil,
+			Alert{Status: "resolved", Labels: firing("critical").Labels, Annotations: firing("critical").Annotations},
+			Check{"web01", "HighLoad", xdata.Ok, "RESOLVED: load is high"},
+		},
+		{
+			"description",
+			nil,
+			Alert{
+				Status:      "firing",
+				Labels:      map[string]string{"alertname": "DiskFull", "instance": "db1", "severity": "critical"},
+				Annotations: map[string]string{"summary": "disk | full", "description": "/var is 99% full\nclean up /var/log"},
+			},
+			Check{"db1", "DiskFull", xdata.Critical, "disk / full\n/var is 99% full\nclean up /var/log"},
+		},
+		{
+			"templates",
+			[]ServiceOption{
+				WithHostTemplate(`{{ .Labels.cluster | upper }}-{{ .Labels.instance | trimPort | replace "." "-" }}`),
+				WithServiceTemplate(`{{ .Labels.job | lower }}: {{ .Labels.alertname }}`),
+			},
+			Alert{Status: "firing", Labels: map[string]string{"alertname": "Down", "instance": "10.0.0.1:80", "job": "HTTP", "cluster": "eu"}},
+			Check{"EU-10-0-0-1", "http: Down", xdata.Warning, "Down"},
+		},
+	}
+
+	for _, test := range tests {
+		opts := append([]ServiceOption{WithSubmitter(nopSubmitter{})}, test.opts...)
+		s, err := NewService(opts...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		check, err := s.Map(test.alert)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if check != test.expected {
+			t.Errorf("%s: unexpected check, got: '%+v', want: '%+v'", test.name, check, test.expected)
+		}
+	}
+}
+
+func TestService_Map_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []ServiceOption
+		field string
+	}{
+		{"host", []ServiceOption{WithHostTemplate(`{{ template "missing" }}`)}, "hostname"},
+		{"service", []ServiceOption{WithServiceTemplate(`{{ template "missing" }}`)}, "service_name"},
+	}
+
+	for _, test := range tests {
+		opts := append([]ServiceOption{WithSubmitter(nopSubmitter{})}, test.opts...)
+		s, err := NewService(opts...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		_, err = s.Map(Alert{Labels: map[string]string{"alertname": "HighLoad", "instance": "web01"}})
+
+		var fe *submission.FieldError
+		if !errors.As(err, &fe) || fe.Field != test.field {
+			t.Errorf("%s: unexpected error, got: '%v', want: a FieldError for '%s'", test.name, err, test.field)
+		}
+	}
+}
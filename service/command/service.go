@@ -0,0 +1,107 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+)
+
+// ErrNotAllowed is returned when a command is not in the allow-list.
+var ErrNotAllowed = errors.New("command not allowed")
+
+// Service is used to queue arbitrary nagios external commands, given by name
+// and their rendered arguments, to the external commands file.
+//
+// Only commands in the allow-list may be queued.
+type Service struct {
+	externalCommandsFile io.Writer
+	allowed              map[string]bool
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{
+		allowed: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.externalCommandsFile == nil {
+		return nil, fmt.Errorf("must set external commands file")
+	}
+
+	return &s, nil
+}
+
+// Allowed returns whether the named command may be queued.
+func (s *Service) Allowed(name string) bool {
+	return s.allowed[normalize(name)]
+}
+
+// Submit validates the arguments of the named command against the command
+// catalogue, and queues the command.
+//
+// cmd.ErrUnknownCommand is returned for commands which are not in the
+// catalogue, ErrNotAllowed for commands which are not in the allow-list and
+// cmd.ErrInvalidArgument if the arguments fail validation.
+func (s *Service) Submit(name string, args []string) error {
+	name = normalize(name)
+	if _, ok := cmd.Lookup(name); !ok {
+		return fmt.Errorf("%w '%s'", cmd.ErrUnknownCommand, name)
+	}
+
+	if !s.allowed[name] {
+		return fmt.Errorf("%w '%s'", ErrNotAllowed, name)
+	}
+
+	command, err := cmd.Parse(name, args)
+	if err != nil {
+		return err
+	}
+
+	if _, err := command.WriteTo(s.externalCommandsFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func normalize(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithExternalCommandsWriter sets the external commands writer.
+//
+// It should be an instance of nagios/cmd, but for testing, anything which
+// implements io.Writer would work.
+func WithExternalCommandsWriter(w io.Writer) ServiceOption {
+	return func(s *Service) error {
+		s.externalCommandsFile = w
+		return nil
+	}
+}
+
+// WithAllowedCommands sets the names of the commands which may be queued.
+//
+// An error is returned if a name is not in the command catalogue.
+func WithAllowedCommands(names []string) ServiceOption {
+	return func(s *Service) error {
+		for _, name := range names {
+			name = normalize(name)
+			if _, ok := cmd.Lookup(name); !ok {
+				return fmt.Errorf("%w '%s'", cmd.ErrUnknownCommand, name)
+			}
+			s.allowed[name] = true
+		}
+		return nil
+	}
+}
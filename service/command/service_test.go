@@ -0,0 +1,120 @@
+package command
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+)
+
+// recordingWriter records the commands written to it. Like a cmd.Writer, it
+// refuses commands longer than max.
+type recordingWriter struct {
+	max      int
+	commands []string
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.max > 0 && len(b) > w.max {
+		return 0, cmd.ErrCommandTooLong
+	}
+	w.commands = append(w.commands, string(b))
+	return len(b), nil
+}
+
+// timestamp matches the timestamp of a rendered command.
+var timestamp = regexp.MustCompile(`^\[\d+\] `)
+
+func TestService_Submit(t *testing.T) {
+	w := &recordingWriter{}
+	s, err := NewService(
+		WithExternalCommandsWriter(w),
+		WithAllowedCommands([]string{"disable_host_check", "ADD_HOST_COMMENT"}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"DISABLE_HOST_CHECK", []string{"web01"}, "DISABLE_HOST_CHECK;web01"},
+		{" disable_host_check ", []string{"web01"}, "DISABLE_HOST_CHECK;web01"},
+		{"ADD_HOST_COMMENT", []string{"web01", "1", "admin", "looking"}, "ADD_HOST_COMMENT;web01;1;admin;looking"},
+	}
+
+	for _, test := range tests {
+		if !s.Allowed(test.name) {
+			t.Errorf("expected '%s' to be allowed", test.name)
+		}
+
+		w.commands = nil
+		if err := s.Submit(test.name, test.args); err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if len(w.commands) != 1 || timestamp.ReplaceAllString(w.commands[0], "") != test.expected {
+			t.Errorf("%s: unexpected commands, got: '%v', want: '%s'", test.name, w.commands, test.expected)
+		}
+	}
+}
+
+func TestService_Submit_Invalid(t *testing.T) {
+	w := &recordingWriter{max: 64}
+	s, err := NewService(
+		WithExternalCommandsWriter(w),
+		WithAllowedCommands([]string{"DISABLE_HOST_CHECK", "ADD_HOST_COMMENT"}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		command  string
+		args     []string
+		err      error
+		argument string
+	}{
+		{"not allowed", "ENABLE_HOST_CHECK", []string{"web01"}, ErrNotAllowed, ""},
+		{"unknown command", "REBOOT_HOST", []string{"web01"}, cmd.ErrUnknownCommand, ""},
+		{"missing argument", "ADD_HOST_COMMENT", []string{"web01", "1", "admin"}, cmd.ErrInvalidArgument, ""},
+		{"bad argument", "ADD_HOST_COMMENT", []string{"web01", "yes", "admin", "looking"}, cmd.ErrInvalidArgument, "persistent"},
+		{"empty host name", "DISABLE_HOST_CHECK", []string{" "}, cmd.ErrInvalidArgument, "host_name"},
+		{"too long", "ADD_HOST_COMMENT", []string{"web01", "1", "admin", strings.Repeat("x", 64)}, cmd.ErrCommandTooLong, ""},
+	}
+
+	for _, test := range tests {
+		w.commands = nil
+		err := s.Submit(test.command, test.args)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, test.err)
+			continue
+		}
+
+		var argErr *cmd.ArgumentError
+		if test.argument != "" && (!errors.As(err, &argErr) || argErr.Argument != test.argument) {
+			t.Errorf("%s: unexpected argument, got: '%v', want: '%s'", test.name, err, test.argument)
+		}
+
+		if len(w.commands) != 0 {
+			t.Errorf("%s: unexpected commands, got: '%v'", test.name, w.commands)
+		}
+	}
+}
+
+func TestWithAllowedCommands_Unknown(t *testing.T) {
+	_, err := NewService(WithExternalCommandsWriter(&recordingWriter{}), WithAllowedCommands([]string{"REBOOT_HOST"}))
+	if !errors.Is(err, cmd.ErrUnknownCommand) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, cmd.ErrUnknownCommand)
+	}
+
+	if _, err := NewService(); err == nil {
+		t.Errorf("expected error without a writer")
+	}
+}
@@ -0,0 +1,255 @@
+package comment
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
+)
+
+var (
+	// ErrEmptyAuthor is returned when adding a comment without an author.
+	ErrEmptyAuthor = errors.New("author must be set")
+
+	// ErrEmptyComment is returned when adding a comment without any text.
+	ErrEmptyComment = errors.New("comment must be set")
+)
+
+// Comment represents a host or service comment. Host comments have an empty
+// ServiceDescription.
+type Comment struct {
+	ID                 int
+	HostName           string
+	ServiceDescription string
+	Author             string
+	Data               string
+	EntryTime          int64
+	EntryType          xdata.CommentEntryType
+	Source             xdata.CommentSource
+	Persistent         bool
+	Expires            bool
+	ExpireTime         int64
+}
+
+// Repository provides access to the comments in the nagios status file.
+type Repository interface {
+	HostComments(host string) ([]*xdata.HostComment, error)
+	ServiceComments(host, name string) ([]*xdata.ServiceComment, error)
+	HostComment(id int) (*xdata.HostComment, error)
+	ServiceComment(id int) (*xdata.ServiceComment, error)
+}
+
+// Service is used to read host and service comments, and to add and delete
+// them through the nagios external commands file.
+type Service struct {
+	externalCommandsFile io.Writer
+	repo                 Repository
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.externalCommandsFile == nil {
+		return nil, fmt.Errorf("must set external commands file")
+	}
+
+	if s.repo == nil {
+		return nil, fmt.Errorf("must set status repository")
+	}
+
+	return &s, nil
+}
+
+// Comments returns every host and service comment.
+func (s *Service) Comments() ([]Comment, error) {
+	hostComments, err := s.repo.HostComments("")
+	if err != nil {
+		return nil, err
+	}
+
+	serviceComments, err := s.repo.ServiceComments("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(fromHostComments(hostComments), fromServiceComments(serviceComments)...), nil
+}
+
+// HostComments returns the comments for a host.
+//
+// statusdata.ErrUnknownHost is returned if the host does not exist.
+func (s *Service) HostComments(host string) ([]Comment, error) {
+	comments, err := s.repo.HostComments(host)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromHostComments(comments), nil
+}
+
+// ServiceComments returns the comments for a service.
+//
+// statusdata.ErrUnknownHost and statusdata.ErrUnknownService are returned if
+// the host or service do not exist.
+func (s *Service) ServiceComments(host, service string) ([]Comment, error) {
+	comments, err := s.repo.ServiceComments(host, service)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromServiceComments(comments), nil
+}
+
+// AddHostComment queues an ADD_HOST_COMMENT command.
+func (s *Service) AddHostComment(host string, persistent bool, author, comment string) error {
+	if err := validate(author, comment); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.HostComments(host); err != nil {
+		return err
+	}
+
+	return s.write(cmd.AddHostComment(host, persistent, author, comment))
+}
+
+// AddServiceComment queues an ADD_SVC_COMMENT command.
+func (s *Service) AddServiceComment(host, service string, persistent bool, author, comment string) error {
+	if err := validate(author, comment); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.ServiceComments(host, service); err != nil {
+		return err
+	}
+
+	return s.write(cmd.AddSvcComment(host, service, persistent, author, comment))
+}
+
+// DeleteHostComment queues a DEL_HOST_COMMENT command.
+//
+// statusdata.ErrUnknownComment is returned if the host has no comment with
+// the given id.
+func (s *Service) DeleteHostComment(host string, id int) error {
+	c, err := s.repo.HostComment(id)
+	if err != nil {
+		return err
+	}
+
+	if c.HostName != host {
+		return statusdata.ErrUnknownComment
+	}
+
+	return s.write(cmd.DelHostComment(id))
+}
+
+// DeleteServiceComment queues a DEL_SVC_COMMENT command.
+//
+// statusdata.ErrUnknownComment is returned if the service has no comment with
+// the given id.
+func (s *Service) DeleteServiceComment(host, service string, id int) error {
+	c, err := s.repo.ServiceComment(id)
+	if err != nil {
+		return err
+	}
+
+	if c.HostName != host || c.ServiceDescription != service {
+		return statusdata.ErrUnknownComment
+	}
+
+	return s.write(cmd.DelSvcComment(id))
+}
+
+func (s *Service) write(command cmd.Command, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if _, err := command.WriteTo(s.externalCommandsFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validate(author, comment string) error {
+	if author == "" {
+		return ErrEmptyAuthor
+	}
+
+	if comment == "" {
+		return ErrEmptyComment
+	}
+
+	return nil
+}
+
+func fromHostComments(comments []*xdata.HostComment) []Comment {
+	res := make([]Comment, 0, len(comments))
+	for _, c := range comments {
+		res = append(res, Comment{
+			ID:         c.CommentID,
+			HostName:   c.HostName,
+			Author:     c.Author,
+			Data:       c.CommentData,
+			EntryTime:  int64(c.EntryTime),
+			EntryType:  c.EntryType,
+			Source:     c.Source,
+			Persistent: c.Persistent,
+			Expires:    c.Expires,
+			ExpireTime: int64(c.ExpireTime),
+		})
+	}
+	return res
+}
+
+func fromServiceComments(comments []*xdata.ServiceComment) []Comment {
+	res := make([]Comment, 0, len(comments))
+	for _, c := range comments {
+		res = append(res, Comment{
+			ID:                 c.CommentID,
+			HostName:           c.HostName,
+			ServiceDescription: c.ServiceDescription,
+			Author:             c.Author,
+			Data:               c.CommentData,
+			EntryTime:          int64(c.EntryTime),
+			EntryType:          c.EntryType,
+			Source:             c.Source,
+			Persistent:         c.Persistent,
+			Expires:            c.Expires,
+			ExpireTime:         int64(c.ExpireTime),
+		})
+	}
+	return res
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithExternalCommandsWriter sets the external commands writer.
+//
+// It should be an instance of nagios/cmd, but for testing, anything which
+// implements io.Writer would work.
+func WithExternalCommandsWriter(w io.Writer) ServiceOption {
+	return func(s *Service) error {
+		s.externalCommandsFile = w
+		return nil
+	}
+}
+
+// WithRepository sets the repository used to look up comments.
+func WithRepository(r Repository) ServiceOption {
+	return func(s *Service) error {
+		s.repo = r
+		return nil
+	}
+}
@@ -0,0 +1,473 @@
+package downtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/objects"
+)
+
+var (
+	// ErrInvalidDowntime is returned when a downtime request fails validation.
+	ErrInvalidDowntime = errors.New("invalid downtime")
+)
+
+// Type identifies what a downtime is scheduled for.
+type Type int
+
+const (
+	// HostDowntime schedules downtime for a single host.
+	HostDowntime Type = iota
+
+	// ServiceDowntime schedules downtime for a single service.
+	ServiceDowntime
+
+	// HostServicesDowntime schedules downtime for every service on a host.
+	HostServicesDowntime
+
+	// HostGroupHostDowntime schedules downtime for every host in a hostgroup.
+	HostGroupHostDowntime
+
+	// HostGroupServiceDowntime schedules downtime for every service on every
+	// host in a hostgroup.
+	HostGroupServiceDowntime
+)
+
+// ParseType converts a string into a Type.
+func ParseType(s string) (Type, error) {
+	switch s {
+	case "host":
+		return HostDowntime, nil
+	case "service":
+		return ServiceDowntime, nil
+	case "host_services":
+		return HostServicesDowntime, nil
+	case "hostgroup_hosts":
+		return HostGroupHostDowntime, nil
+	case "hostgroup_services":
+		return HostGroupServiceDowntime, nil
+	default:
+		return HostDowntime, fmt.Errorf("%w: unknown type '%s'", ErrInvalidDowntime, s)
+	}
+}
+
+// Request describes a downtime to be scheduled.
+//
+// Fixed downtime lasts from Start to End. Flexible downtime starts when the
+// host or service first enters a problem state between Start and End, and
+// lasts for Duration.
+type Request struct {
+	Type               Type
+	HostName           string
+	ServiceDescription string
+	HostGroup          string
+	Start              time.Time
+	End                time.Time
+	Fixed              bool
+	Duration           time.Duration
+	TriggerID          int
+	Author             string
+	Comment            string
+}
+
+// Downtime represents a scheduled host or service downtime. Host downtimes
+// have an empty ServiceDescription.
+type Downtime struct {
+	ID                 int
+	HostName           string
+	ServiceDescription string
+	Author             string
+	Comment            string
+	CommentID          int
+	EntryTime          int64
+	StartTime          int64
+	EndTime            int64
+	Fixed              bool
+	Duration           int64
+	TriggeredBy        int
+	IsInEffect         bool
+}
+
+// Repository provides access to the downtimes in the nagios status file.
+type Repository interface {
+	HostStatus(host string) (*xdata.HostStatus, error)
+	ServiceStatus(host, name string) (*xdata.ServiceStatus, error)
+	HostDowntimes() []*xdata.HostDowntime
+	ServiceDowntimes() []*xdata.ServiceDowntime
+	HostDowntime(id int) (*xdata.HostDowntime, error)
+	ServiceDowntime(id int) (*xdata.ServiceDowntime, error)
+	Updated() <-chan struct{}
+}
+
+// Service is used to list scheduled downtime, and to schedule and cancel
+// downtime through the nagios external commands file.
+type Service struct {
+	externalCommandsFile io.Writer
+	repo                 Repository
+	objectsFile          string
+	waitTimeout          time.Duration
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{
+		waitTimeout: 70 * time.Second,
+	}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.externalCommandsFile == nil {
+		return nil, fmt.Errorf("must set external commands file")
+	}
+
+	if s.repo == nil {
+		return nil, fmt.Errorf("must set status repository")
+	}
+
+	return &s, nil
+}
+
+// Downtimes returns every scheduled host and service downtime, ordered by id.
+func (s *Service) Downtimes() []Downtime {
+	res := make([]Downtime, 0)
+	for _, d := range s.repo.HostDowntimes() {
+		res = append(res, fromHostDowntime(d))
+	}
+	for _, d := range s.repo.ServiceDowntimes() {
+		res = append(res, fromServiceDowntime(d))
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res
+}
+
+// Downtime looks up a scheduled downtime by id.
+//
+// statusdata.ErrUnknownDowntime is returned if no downtime exists with the
+// given id.
+func (s *Service) Downtime(id int) (Downtime, error) {
+	if d, err := s.repo.HostDowntime(id); err == nil {
+		return fromHostDowntime(d), nil
+	}
+
+	d, err := s.repo.ServiceDowntime(id)
+	if err != nil {
+		return Downtime{}, err
+	}
+
+	return fromServiceDowntime(d), nil
+}
+
+// Schedule queues the command to schedule a downtime.
+//
+// If wait is set, Schedule then waits for the downtime to appear in the
+// nagios status file and returns it. If the downtime has not appeared
+// within the wait timeout, no downtimes and no error are returned.
+//
+// Hostgroup downtimes are found through the members of the hostgroup, so
+// are only waited for if the objects cache file can be read.
+func (s *Service) Schedule(ctx context.Context, req Request, wait bool) ([]Downtime, error) {
+	command, err := s.scheduleCommand(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]bool
+	if wait && (req.Type == HostGroupHostDowntime || req.Type == HostGroupServiceDowntime) {
+		members, err = s.hostGroupMembers(req.HostGroup)
+		switch {
+		case errors.Is(err, ErrInvalidDowntime):
+			return nil, err
+		case err != nil:
+			wait = false
+		}
+	}
+
+	// the update channel must be retrieved before writing the command,
+	// otherwise a reload could be missed.
+	updated := s.repo.Updated()
+	submitted := time.Now().Unix()
+
+	if _, err := command.WriteTo(s.externalCommandsFile); err != nil {
+		return nil, err
+	}
+
+	if !wait {
+		return nil, nil
+	}
+
+	timeout := time.NewTimer(s.waitTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-timeout.C:
+			return nil, nil
+		case <-updated:
+		}
+
+		updated = s.repo.Updated()
+		if found := s.find(req, submitted, members); len(found) != 0 {
+			return found, nil
+		}
+	}
+}
+
+// Cancel queues the command to delete a scheduled downtime.
+//
+// statusdata.ErrUnknownDowntime is returned if no downtime exists with the
+// given id.
+func (s *Service) Cancel(id int) error {
+	d, err := s.Downtime(id)
+	if err != nil {
+		return err
+	}
+
+	command, err := cmd.DelHostDowntime(id)
+	if d.ServiceDescription != "" {
+		command, err = cmd.DelSvcDowntime(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := command.WriteTo(s.externalCommandsFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) validate(req Request) error {
+	switch req.Type {
+	case HostDowntime, HostServicesDowntime:
+		if req.HostName == "" {
+			return fmt.Errorf("%w: host must be set", ErrInvalidDowntime)
+		}
+		if _, err := s.repo.HostStatus(req.HostName); err != nil {
+			return err
+		}
+
+	case ServiceDowntime:
+		if req.HostName == "" || req.ServiceDescription == "" {
+			return fmt.Errorf("%w: host and service must be set", ErrInvalidDowntime)
+		}
+		if _, err := s.repo.ServiceStatus(req.HostName, req.ServiceDescription); err != nil {
+			return err
+		}
+
+	case HostGroupHostDowntime, HostGroupServiceDowntime:
+		if req.HostGroup == "" {
+			return fmt.Errorf("%w: hostgroup must be set", ErrInvalidDowntime)
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown type", ErrInvalidDowntime)
+	}
+
+	if req.Start.IsZero() || req.End.IsZero() {
+		return fmt.Errorf("%w: start and end must be set", ErrInvalidDowntime)
+	}
+
+	if !req.End.After(req.Start) {
+		return fmt.Errorf("%w: end must be after start", ErrInvalidDowntime)
+	}
+
+	if !req.End.After(time.Now()) {
+		return fmt.Errorf("%w: end must be in the future", ErrInvalidDowntime)
+	}
+
+	if !req.Fixed && req.Duration <= 0 {
+		return fmt.Errorf("%w: flexible downtime must have a duration", ErrInvalidDowntime)
+	}
+
+	if req.Duration < 0 {
+		return fmt.Errorf("%w: duration must not be negative", ErrInvalidDowntime)
+	}
+
+	if req.Author == "" {
+		return fmt.Errorf("%w: author must be set", ErrInvalidDowntime)
+	}
+
+	if req.Comment == "" {
+		return fmt.Errorf("%w: comment must be set", ErrInvalidDowntime)
+	}
+
+	return nil
+}
+
+func (s *Service) scheduleCommand(req Request) (cmd.Command, error) {
+	if err := s.validate(req); err != nil {
+		return cmd.Command{}, err
+	}
+
+	duration := req.Duration
+	if req.Fixed {
+		duration = req.End.Sub(req.Start)
+	}
+
+	switch req.Type {
+	case HostDowntime:
+		return cmd.ScheduleHostDowntime(req.HostName, req.Start, req.End, req.Fixed, req.TriggerID, duration, req.Author, req.Comment)
+	case ServiceDowntime:
+		return cmd.ScheduleSvcDowntime(req.HostName, req.ServiceDescription, req.Start, req.End, req.Fixed, req.TriggerID, duration, req.Author, req.Comment)
+	case HostServicesDowntime:
+		return cmd.ScheduleHostSvcDowntime(req.HostName, req.Start, req.End, req.Fixed, req.TriggerID, duration, req.Author, req.Comment)
+	case HostGroupHostDowntime:
+		return cmd.ScheduleHostgroupHostDowntime(req.HostGroup, req.Start, req.End, req.Fixed, req.TriggerID, duration, req.Author, req.Comment)
+	default:
+		return cmd.ScheduleHostgroupSvcDowntime(req.HostGroup, req.Start, req.End, req.Fixed, req.TriggerID, duration, req.Author, req.Comment)
+	}
+}
+
+// hostGroupMembers returns the names of the hosts in a hostgroup, as defined
+// in the objects cache file.
+func (s *Service) hostGroupMembers(name string) (map[string]bool, error) {
+	g, err := objects.LoadGroups(s.objectsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, ok := g.HostGroups[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown hostgroup '%s'", ErrInvalidDowntime, name)
+	}
+
+	members := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		members[host] = true
+	}
+	return members, nil
+}
+
+// find returns the downtimes created by a request submitted at the given
+// time. Hostgroup downtimes are matched on the hosts in members.
+func (s *Service) find(req Request, submitted int64, members map[string]bool) []Downtime {
+	found := make([]Downtime, 0)
+	for _, d := range s.Downtimes() {
+		if d.EntryTime < submitted-1 ||
+			d.StartTime != req.Start.Unix() ||
+			d.EndTime != req.End.Unix() ||
+			d.Fixed != req.Fixed ||
+			d.Author != cmd.Sanitize(req.Author) ||
+			d.Comment != cmd.Sanitize(req.Comment) {
+			continue
+		}
+
+		switch req.Type {
+		case HostDowntime:
+			if d.HostName != req.HostName || d.ServiceDescription != "" {
+				continue
+			}
+		case ServiceDowntime:
+			if d.HostName != req.HostName || d.ServiceDescription != req.ServiceDescription {
+				continue
+			}
+		case HostServicesDowntime:
+			if d.HostName != req.HostName || d.ServiceDescription == "" {
+				continue
+			}
+		case HostGroupHostDowntime:
+			if !members[d.HostName] || d.ServiceDescription != "" {
+				continue
+			}
+		case HostGroupServiceDowntime:
+			if !members[d.HostName] || d.ServiceDescription == "" {
+				continue
+			}
+		}
+
+		found = append(found, d)
+	}
+
+	return found
+}
+
+func fromHostDowntime(d *xdata.HostDowntime) Downtime {
+	return Downtime{
+		ID:          d.DowntimeID,
+		HostName:    d.HostName,
+		Author:      d.Author,
+		Comment:     d.Comment,
+		CommentID:   d.CommentID,
+		EntryTime:   int64(d.EntryTime),
+		StartTime:   int64(d.StartTime),
+		EndTime:     int64(d.EndTime),
+		Fixed:       d.Fixed,
+		Duration:    int64(d.Duration),
+		TriggeredBy: d.TriggeredBy,
+		IsInEffect:  d.IsInEffect,
+	}
+}
+
+func fromServiceDowntime(d *xdata.ServiceDowntime) Downtime {
+	return Downtime{
+		ID:                 d.DowntimeID,
+		HostName:           d.HostName,
+		ServiceDescription: d.ServiceDescription,
+		Author:             d.Author,
+		Comment:            d.Comment,
+		CommentID:          d.CommentID,
+		EntryTime:          int64(d.EntryTime),
+		StartTime:          int64(d.StartTime),
+		EndTime:            int64(d.EndTime),
+		Fixed:              d.Fixed,
+		Duration:           int64(d.Duration),
+		TriggeredBy:        d.TriggeredBy,
+		IsInEffect:         d.IsInEffect,
+	}
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithExternalCommandsWriter sets the external commands writer.
+//
+// It should be an instance of nagios/cmd, but for testing, anything which
+// implements io.Writer would work.
+func WithExternalCommandsWriter(w io.Writer) ServiceOption {
+	return func(s *Service) error {
+		s.externalCommandsFile = w
+		return nil
+	}
+}
+
+// WithRepository sets the repository used to look up downtimes.
+func WithRepository(r Repository) ServiceOption {
+	return func(s *Service) error {
+		s.repo = r
+		return nil
+	}
+}
+
+// WithObjectsCacheFile sets the path to the nagios objects.cache file, which
+// is required to wait for hostgroup downtimes.
+func WithObjectsCacheFile(filename string) ServiceOption {
+	return func(s *Service) error {
+		s.objectsFile = filename
+		return nil
+	}
+}
+
+// WithWaitTimeout sets how long Schedule waits for a new downtime to appear
+// in the nagios status file.
+func WithWaitTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) error {
+		s.waitTimeout = d
+		return nil
+	}
+}
@@ -0,0 +1,249 @@
+package downtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
+)
+
+// fakeRepository holds a fixed set of hosts, services and downtimes.
+type fakeRepository struct {
+	hosts            map[string]bool
+	services         map[string]bool
+	hostDowntimes    []*xdata.HostDowntime
+	serviceDowntimes []*xdata.ServiceDowntime
+}
+
+func (r *fakeRepository) HostStatus(host string) (*xdata.HostStatus, error) {
+	if !r.hosts[host] {
+		return nil, statusdata.ErrUnknownHost
+	}
+	return &xdata.HostStatus{HostName: host}, nil
+}
+
+func (r *fakeRepository) ServiceStatus(host, name string) (*xdata.ServiceStatus, error) {
+	if !r.services[host+"/"+name] {
+		return nil, statusdata.ErrUnknownService
+	}
+	return &xdata.ServiceStatus{HostName: host, ServiceDescription: name}, nil
+}
+
+func (r *fakeRepository) HostDowntimes() []*xdata.HostDowntime       { return r.hostDowntimes }
+func (r *fakeRepository) ServiceDowntimes() []*xdata.ServiceDowntime { return r.serviceDowntimes }
+
+func (r *fakeRepository) HostDowntime(id int) (*xdata.HostDowntime, error) {
+	return nil, statusdata.ErrUnknownDowntime
+}
+
+func (r *fakeRepository) ServiceDowntime(id int) (*xdata.ServiceDowntime, error) {
+	return nil, statusdata.ErrUnknownDowntime
+}
+
+func (r *fakeRepository) Updated() <-chan struct{} { return make(chan struct{}) }
+
+func newTestService(t *testing.T, repo Repository, opts ...ServiceOption) *Service {
+	opts = append([]ServiceOption{WithExternalCommandsWriter(&bytes.Buffer{}), WithRepository(repo)}, opts...)
+	s, err := NewService(opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return s
+}
+
+func TestService_validate(t *testing.T) {
+	s := newTestService(t, &fakeRepository{
+		hosts:    map[string]bool{"web01": true},
+		services: map[string]bool{"web01/HTTP": true},
+	})
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(2 * time.Hour)
+	valid := func(typ Type, fixed bool, duration time.Duration) Request {
+		return Request{
+			Type:               typ,
+			HostName:           "web01",
+			ServiceDescription: "HTTP",
+			HostGroup:          "web",
+			Start:              start,
+			End:                end,
+			Fixed:              fixed,
+			Duration:           duration,
+			Author:             "admin",
+			Comment:            "patching",
+		}
+	}
+	with := func(req Request, f func(*Request)) Request {
+		f(&req)
+		return req
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		err  error
+	}{
+		{"fixed host", valid(HostDowntime, true, 0), nil},
+		{"flexible host", valid(HostDowntime, false, 30*time.Minute), nil},
+		{"fixed service", valid(ServiceDowntime, true, 0), nil},
+		{"host services", valid(HostServicesDowntime, false, time.Minute), nil},
+		{"hostgroup hosts", valid(HostGroupHostDowntime, true, 0), nil},
+		{"hostgroup services", valid(HostGroupServiceDowntime, false, time.Hour), nil},
+		{"flexible without duration", valid(HostDowntime, false, 0), ErrInvalidDowntime},
+		{"negative duration", valid(HostDowntime, true, -time.Minute), ErrInvalidDowntime},
+		{"unknown type", valid(Type(10), true, 0), ErrInvalidDowntime},
+		{"no host", with(valid(HostDowntime, true, 0), func(r *Request) { r.HostName = "" }), ErrInvalidDowntime},
+		{"unknown host", with(valid(HostServicesDowntime, true, 0), func(r *Request) { r.HostName = "web02" }), statusdata.ErrUnknownHost},
+		{"no service", with(valid(ServiceDowntime, true, 0), func(r *Request) { r.ServiceDescription = "" }), ErrInvalidDowntime},
+		{"unknown service", with(valid(ServiceDowntime, true, 0), func(r *Request) { r.ServiceDescription = "SSH" }), statusdata.ErrUnknownService},
+		{"no hostgroup", with(valid(HostGroupServiceDowntime, true, 0), func(r *Request) { r.HostGroup = "" }), ErrInvalidDowntime},
+		{"no start", with(valid(HostDowntime, true, 0), func(r *Request) { r.Start = time.Time{} }), ErrInvalidDowntime},
+		{"end before start", with(valid(HostDowntime, true, 0), func(r *Request) { r.End = start }), ErrInvalidDowntime},
+		{"ended", with(valid(HostDowntime, true, 0), func(r *Request) { r.Start, r.End = start.Add(-4*time.Hour), start.Add(-2*time.Hour) }), ErrInvalidDowntime},
+		{"no author", with(valid(HostDowntime, true, 0), func(r *Request) { r.Author = "" }), ErrInvalidDowntime},
+		{"no comment", with(valid(HostDowntime, true, 0), func(r *Request) { r.Comment = "" }), ErrInvalidDowntime},
+	}
+
+	for _, test := range tests {
+		err := s.validate(test.req)
+		if test.err == nil && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, test.err)
+		}
+	}
+}
+
+func TestService_scheduleCommand(t *testing.T) {
+	s := newTestService(t, &fakeRepository{
+		hosts:    map[string]bool{"web01": true},
+		services: map[string]bool{"web01/HTTP": true},
+	})
+
+	now := time.Unix(1600000000, 0)
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+
+	// fixed downtime lasts from start to end, whatever its duration.
+	window := fmt.Sprintf("%d;%d", start.Unix(), end.Unix())
+	tests := []struct {
+		name     string
+		req      Request
+		expected string
+	}{
+		{
+			"fixed host",
+			Request{Type: HostDowntime, HostName: "web01", Start: start, End: end, Fixed: true, Duration: time.Minute, Author: "admin", Comment: "patching"},
+			"[1600000000] SCHEDULE_HOST_DOWNTIME;web01;" + window + ";1;0;7200;admin;patching",
+		},
+		{
+			"flexible host",
+			Request{Type: HostDowntime, HostName: "web01", Start: start, End: end, Duration: 30 * time.Minute, TriggerID: 4, Author: "admin", Comment: "patching"},
+			"[1600000000] SCHEDULE_HOST_DOWNTIME;web01;" + window + ";0;4;1800;admin;patching",
+		},
+		{
+			"service",
+			Request{Type: ServiceDowntime, HostName: "web01", ServiceDescription: "HTTP", Start: start, End: end, Fixed: true, Author: "admin", Comment: "patching"},
+			"[1600000000] SCHEDULE_SVC_DOWNTIME;web01;HTTP;" + window + ";1;0;7200;admin;patching",
+		},
+		{
+			"host services",
+			Request{Type: HostServicesDowntime, HostName: "web01", Start: start, End: end, Duration: time.Hour, Author: "admin", Comment: "patching"},
+			"[1600000000] SCHEDULE_HOST_SVC_DOWNTIME;web01;" + window + ";0;0;3600;admin;patching",
+		},
+		{
+			"hostgroup hosts",
+			Request{Type: HostGroupHostDowntime, HostGroup: "web", Start: start, End: end, Fixed: true, Author: "admin", Comment: "patching"},
+			"[1600000000] SCHEDULE_HOSTGROUP_HOST_DOWNTIME;web;" + window + ";1;0;7200;admin;patching",
+		},
+		{
+			"hostgroup services",
+			Request{Type: HostGroupServiceDowntime, HostGroup: "web", Start: start, End: end, Duration: 10 * time.Minute, Author: "admin", Comment: "patching"},
+			"[1600000000] SCHEDULE_HOSTGROUP_SVC_DOWNTIME;web;" + window + ";0;0;600;admin;patching",
+		},
+	}
+
+	for _, test := range tests {
+		c, err := s.scheduleCommand(test.req)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if out := c.At(now).String(); out != test.expected {
+			t.Errorf("%s: unexpected command, got: '%s', want: '%s'", test.name, out, test.expected)
+		}
+	}
+
+	if _, err := s.scheduleCommand(Request{Type: HostDowntime, HostName: "web01", Start: start, End: end}); !errors.Is(err, ErrInvalidDowntime) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrInvalidDowntime)
+	}
+}
+
+func TestService_find_HostGroup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "downtime")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	objectsFile := filepath.Join(dir, "objects.cache")
+	objectsCache := "define hostgroup {\n\thostgroup_name\tweb\n\tmembers\tweb01,web02\n\t}\n"
+	if err := ioutil.WriteFile(objectsFile, []byte(objectsCache), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	submitted := int64(1600000000)
+	start := time.Unix(submitted+3600, 0)
+	end := start.Add(time.Hour)
+	host := func(id int, hostname string) *xdata.HostDowntime {
+		return &xdata.HostDowntime{DowntimeID: id, HostName: hostname, EntryTime: int(submitted), StartTime: int(start.Unix()), EndTime: int(end.Unix()), Fixed: true, Author: "admin", Comment: "patching"}
+	}
+	service := func(id int, hostname string) *xdata.ServiceDowntime {
+		return &xdata.ServiceDowntime{DowntimeID: id, HostName: hostname, ServiceDescription: "HTTP", EntryTime: int(submitted), StartTime: int(start.Unix()), EndTime: int(end.Unix()), Fixed: true, Author: "admin", Comment: "patching"}
+	}
+
+	// the same downtime scheduled for a host outside of the hostgroup is
+	// not matched.
+	s := newTestService(t, &fakeRepository{
+		hostDowntimes:    []*xdata.HostDowntime{host(1, "web01"), host(2, "web02"), host(3, "db1")},
+		serviceDowntimes: []*xdata.ServiceDowntime{service(4, "web01"), service(5, "db1")},
+	}, WithObjectsCacheFile(objectsFile))
+
+	tests := []struct {
+		typ      Type
+		expected []int
+	}{
+		{HostGroupHostDowntime, []int{1, 2}},
+		{HostGroupServiceDowntime, []int{4}},
+	}
+
+	for _, test := range tests {
+		req := Request{Type: test.typ, HostGroup: "web", Start: start, End: end, Fixed: true, Author: "admin", Comment: "patching"}
+		members, err := s.hostGroupMembers(req.HostGroup)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		found := s.find(req, submitted, members)
+		ids := make([]int, 0, len(found))
+		for _, d := range found {
+			ids = append(ids, d.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(test.expected) {
+			t.Errorf("unexpected downtimes for type %d, got: '%v', want: '%v'", test.typ, ids, test.expected)
+		}
+	}
+
+	if _, err := s.hostGroupMembers("db"); !errors.Is(err, ErrInvalidDowntime) {
+		t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrInvalidDowntime)
+	}
+}
@@ -0,0 +1,349 @@
+package heartbeat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"go.uber.org/zap"
+)
+
+// MinInterval is the shortest interval a heartbeat may be expected at.
+const MinInterval = time.Minute
+
+// DefaultCheckInterval is how often heartbeats are checked.
+const DefaultCheckInterval = 10 * time.Second
+
+// Heartbeat is a host, or host and service, which results are expected for
+// at least every Interval. Host heartbeats have an empty ServiceName.
+//
+// Configured heartbeats are set up when the Service is constructed, others
+// are declared by the results submitted for them. Declared heartbeats are
+// only kept in memory, so are lost on restart until the next result
+// declares them again.
+type Heartbeat struct {
+	Hostname    string
+	ServiceName string
+	Interval    time.Duration
+	Configured  bool
+
+	// LastSeen is when the last result was submitted, or when the heartbeat
+	// was set up.
+	LastSeen time.Time
+
+	// Overdue is set once no result has been submitted for Interval, and
+	// cleared by the next result. LastAlert is when the Service last
+	// submitted a result itself.
+	Overdue   bool
+	LastAlert time.Time
+}
+
+// Submitter submits passive check results, such as submission.Service.
+type Submitter interface {
+	SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error)
+	SubmitHostResult(time int64, state xdata.HostState, hostname, body string) ([]cmd.Delivery, error)
+}
+
+// Service watches for hosts and services which stop submitting passive
+// results, and submits a CRITICAL, or DOWN, result for them once they are
+// overdue.
+//
+// Service wraps a Submitter, recording each result submitted through it.
+type Service struct {
+	submitter     Submitter
+	checkInterval time.Duration
+	logger        *zap.Logger
+
+	mu         sync.Mutex
+	heartbeats map[string]*Heartbeat
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewService constructs an instance of Service, and starts checking the
+// heartbeats in the background until Close is called.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{
+		checkInterval: DefaultCheckInterval,
+		logger:        zap.NewNop(),
+		heartbeats:    make(map[string]*Heartbeat),
+		stop:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.submitter == nil {
+		return nil, fmt.Errorf("must set submitter")
+	}
+
+	go s.run()
+
+	return &s, nil
+}
+
+// SubmitResult submits a passive service check result, and records it
+// against the heartbeat of the service, if any.
+func (s *Service) SubmitResult(
+	checkTime int64,
+	state xdata.ServiceState,
+	hostname string,
+	serviceName string,
+	body string,
+) ([]cmd.Delivery, error) {
+	deliveries, err := s.submitter.SubmitResult(checkTime, state, hostname, serviceName, body)
+	if err == nil {
+		s.seen(hostname, serviceName)
+	}
+	return deliveries, err
+}
+
+// SubmitHostResult submits a passive host check result, and records it
+// against the heartbeat of the host, if any.
+func (s *Service) SubmitHostResult(
+	checkTime int64,
+	state xdata.HostState,
+	hostname string,
+	body string,
+) ([]cmd.Delivery, error) {
+	deliveries, err := s.submitter.SubmitHostResult(checkTime, state, hostname, body)
+	if err == nil {
+		s.seen(hostname, "")
+	}
+	return deliveries, err
+}
+
+// Expect sets the interval a host, or host and service, is expected to
+// submit results at. The heartbeat is treated as seen now. The interval of a
+// configured heartbeat is not changed.
+//
+// Heartbeats declared by Expect are not persisted. A host which stops
+// submitting results while the Service is restarting is not noticed, as the
+// heartbeat is only declared again by its next result.
+//
+// Intervals shorter than MinInterval are rejected by the caller.
+func (s *Service) Expect(hostname, serviceName string, every time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(hostname, serviceName)
+	h, ok := s.heartbeats[k]
+	if !ok {
+		h = &Heartbeat{
+			Hostname:    hostname,
+			ServiceName: serviceName,
+		}
+		s.heartbeats[k] = h
+	}
+
+	if !h.Configured {
+		h.Interval = every
+	}
+	h.LastSeen = time.Now()
+	h.Overdue = false
+}
+
+// Forget stops expecting results for a host, or host and service. It
+// returns false if there was no such heartbeat.
+func (s *Service) Forget(hostname, serviceName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(hostname, serviceName)
+	if _, ok := s.heartbeats[k]; !ok {
+		return false
+	}
+	delete(s.heartbeats, k)
+	return true
+}
+
+// Heartbeats returns every heartbeat, ordered by host and service.
+func (s *Service) Heartbeats() []Heartbeat {
+	return s.list(false)
+}
+
+// Overdue returns the heartbeats which are overdue, ordered by host and
+// service.
+func (s *Service) Overdue() []Heartbeat {
+	return s.list(true)
+}
+
+// Close stops checking the heartbeats.
+func (s *Service) Close() {
+	close(s.stop)
+	<-s.stopped
+}
+
+func (s *Service) list(overdue bool) []Heartbeat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]Heartbeat, 0, len(s.heartbeats))
+	for _, h := range s.heartbeats {
+		if overdue && !h.Overdue {
+			continue
+		}
+		res = append(res, *h)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Hostname != res[j].Hostname {
+			return res[i].Hostname < res[j].Hostname
+		}
+		return res[i].ServiceName < res[j].ServiceName
+	})
+
+	return res
+}
+
+// seen records a result for a heartbeat.
+func (s *Service) seen(hostname, serviceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if h, ok := s.heartbeats[key(hostname, serviceName)]; ok {
+		h.LastSeen = time.Now()
+		h.Overdue = false
+	}
+}
+
+func (s *Service) run() {
+	defer close(s.stopped)
+
+	t := time.NewTicker(s.checkInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-t.C:
+			s.check(now)
+		}
+	}
+}
+
+// check submits a result for each heartbeat which has become overdue, and
+// again every interval for as long as it stays overdue.
+func (s *Service) check(now time.Time) {
+	s.mu.Lock()
+	var due []Heartbeat
+	for _, h := range s.heartbeats {
+		if now.Sub(h.LastSeen) < h.Interval {
+			continue
+		}
+		if h.Overdue && now.Sub(h.LastAlert) < h.Interval {
+			continue
+		}
+		h.Overdue = true
+		h.LastAlert = now
+		due = append(due, *h)
+	}
+	s.mu.Unlock()
+
+	// results are submitted without the lock, and directly to the
+	// submitter, so they do not count as being seen.
+	for _, h := range due {
+		body := fmt.Sprintf("no result received for %s", duration(now.Sub(h.LastSeen)))
+
+		var err error
+		if h.ServiceName == "" {
+			_, err = s.submitter.SubmitHostResult(0, xdata.Down, h.Hostname, body)
+		} else {
+			_, err = s.submitter.SubmitResult(0, xdata.Critical, h.Hostname, h.ServiceName, body)
+		}
+
+		if err != nil {
+			s.logger.Warn("unable to submit overdue heartbeat",
+				zap.String("host", h.Hostname),
+				zap.String("service", h.ServiceName),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		s.logger.Info("heartbeat overdue",
+			zap.String("host", h.Hostname),
+			zap.String("service", h.ServiceName),
+			zap.Duration("interval", h.Interval),
+			zap.Time("last seen", h.LastSeen),
+		)
+	}
+}
+
+func key(hostname, serviceName string) string {
+	return hostname + ";" + serviceName
+}
+
+// duration formats a duration to the second, without trailing zero units,
+// such as "7m" rather than "7m0s".
+func duration(d time.Duration) string {
+	s := d.Truncate(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithSubmitter sets where passive check results are submitted.
+func WithSubmitter(sub Submitter) ServiceOption {
+	return func(s *Service) error {
+		s.submitter = sub
+		return nil
+	}
+}
+
+// WithHeartbeat sets up a configured heartbeat, treated as seen when the
+// Service is constructed.
+func WithHeartbeat(hostname, serviceName string, every time.Duration) ServiceOption {
+	return func(s *Service) error {
+		if strings.TrimSpace(hostname) == "" {
+			return fmt.Errorf("heartbeat must have a host")
+		}
+		if every < MinInterval {
+			return fmt.Errorf("heartbeat for '%s' must be expected at least every %s", key(hostname, serviceName), MinInterval)
+		}
+
+		s.heartbeats[key(hostname, serviceName)] = &Heartbeat{
+			Hostname:    hostname,
+			ServiceName: serviceName,
+			Interval:    every,
+			Configured:  true,
+			LastSeen:    time.Now(),
+		}
+		return nil
+	}
+}
+
+// WithCheckInterval sets how often heartbeats are checked.
+func WithCheckInterval(d time.Duration) ServiceOption {
+	return func(s *Service) error {
+		if d <= 0 {
+			return fmt.Errorf("check interval must be positive")
+		}
+		s.checkInterval = d
+		return nil
+	}
+}
+
+// WithLogger passes in a zap Logger to NewService().
+func WithLogger(l *zap.Logger) ServiceOption {
+	return func(s *Service) error {
+		s.logger = l
+		return nil
+	}
+}
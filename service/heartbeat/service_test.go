@@ -0,0 +1,254 @@
+package heartbeat
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+)
+
+// result is a passive check result passed to a recordingSubmitter. Host
+// results have no ServiceName.
+type result struct {
+	Hostname    string
+	ServiceName string
+	State       int
+	Output      string
+}
+
+type recordingSubmitter struct {
+	mu      sync.Mutex
+	err     error
+	results []result
+}
+
+func (s *recordingSubmitter) SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, result{hostname, serviceName, int(state), body})
+	return nil, s.err
+}
+
+func (s *recordingSubmitter) SubmitHostResult(time int64, state xdata.HostState, hostname, body string) ([]cmd.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, result{hostname, "", int(state), body})
+	return nil, s.err
+}
+
+// take returns the results submitted since it was last called.
+func (s *recordingSubmitter) take() []result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := s.results
+	s.results = nil
+	return res
+}
+
+// newTestService constructs a Service which is only checked when the test
+// calls check, with each heartbeat last seen at base.
+func newTestService(t *testing.T, sub Submitter, base time.Time, opts ...ServiceOption) *Service {
+	opts = append([]ServiceOption{WithSubmitter(sub), WithCheckInterval(time.Hour)}, opts...)
+	s, err := NewService(opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, h := range s.heartbeats {
+		h.LastSeen = base
+	}
+	return s
+}
+
+func TestService_check(t *testing.T) {
+	base := time.Unix(1600000000, 0)
+	sub := &recordingSubmitter{}
+	s := newTestService(t, sub, base,
+		WithHeartbeat("db1", "backup", 10*time.Minute),
+		WithHeartbeat("edge1", "", 5*time.Minute),
+	)
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		offset   time.Duration
+		expected []result
+		overdue  int
+	}{
+		{"not due", 4 * time.Minute, nil, 0},
+		{"host overdue", 5 * time.Minute, []result{
+			{"edge1", "", int(xdata.Down), "no result received for 5m"},
+		}, 1},
+		{"not repeated", 9 * time.Minute, nil, 1},
+		{"host repeated and service overdue", 10*time.Minute + 30*time.Second, []result{
+			{"db1", "backup", int(xdata.Critical), "no result received for 10m30s"},
+			{"edge1", "", int(xdata.Down), "no result received for 10m30s"},
+		}, 2},
+		{"still overdue", 15 * time.Minute, nil, 2},
+		{"repeated", 2 * time.Hour, []result{
+			{"db1", "backup", int(xdata.Critical), "no result received for 2h"},
+			{"edge1", "", int(xdata.Down), "no result received for 2h"},
+		}, 2},
+	}
+
+	for _, test := range tests {
+		s.check(base.Add(test.offset))
+
+		results := sub.take()
+		if len(results) != len(test.expected) {
+			t.Errorf("%s: unexpected results, got: '%v', want: '%v'", test.name, results, test.expected)
+			continue
+		}
+		// heartbeats are checked in no particular order.
+		for _, expected := range test.expected {
+			found := false
+			for _, r := range results {
+				found = found || r == expected
+			}
+			if !found {
+				t.Errorf("%s: missing result, got: '%v', want: '%v'", test.name, results, expected)
+			}
+		}
+
+		if overdue := s.Overdue(); len(overdue) != test.overdue {
+			t.Errorf("%s: unexpected overdue heartbeats, got: '%v', want: %d", test.name, overdue, test.overdue)
+		}
+	}
+
+	// the results submitted for overdue heartbeats do not count as seen.
+	for _, h := range s.Heartbeats() {
+		if !h.LastSeen.Equal(base) {
+			t.Errorf("unexpected last seen for '%s', got: '%s', want: '%s'", key(h.Hostname, h.ServiceName), h.LastSeen, base)
+		}
+	}
+}
+
+func TestService_check_Seen(t *testing.T) {
+	sub := &recordingSubmitter{}
+	s := newTestService(t, sub, time.Time{}, WithHeartbeat("db1", "backup", 10*time.Minute))
+	defer s.Close()
+
+	now := time.Now()
+	s.check(now)
+	if len(sub.take()) != 1 || len(s.Overdue()) != 1 {
+		t.Fatalf("expected heartbeat to be overdue")
+	}
+
+	// a result clears the overdue heartbeat, and restarts the interval.
+	if _, err := s.SubmitResult(0, xdata.Ok, "db1", "backup", "ok"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sub.take()
+
+	if overdue := s.Overdue(); len(overdue) != 0 {
+		t.Errorf("unexpected overdue heartbeats, got: '%v'", overdue)
+	}
+
+	s.check(time.Now().Add(9 * time.Minute))
+	if results := sub.take(); len(results) != 0 {
+		t.Errorf("unexpected results, got: '%v'", results)
+	}
+
+	s.check(time.Now().Add(11 * time.Minute))
+	if results := sub.take(); len(results) != 1 {
+		t.Errorf("unexpected results, got: '%v'", results)
+	}
+}
+
+func TestService_check_FailedResult(t *testing.T) {
+	sub := &recordingSubmitter{}
+	s := newTestService(t, sub, time.Time{}, WithHeartbeat("db1", "backup", 10*time.Minute))
+	defer s.Close()
+
+	// results which could not be submitted are not seen.
+	sub.err = cmd.ErrQueueFull
+	if _, err := s.SubmitResult(0, xdata.Ok, "db1", "backup", "ok"); !errors.Is(err, cmd.ErrQueueFull) {
+		t.Fatalf("unexpected error, got: '%v', want: '%v'", err, cmd.ErrQueueFull)
+	}
+	sub.take()
+
+	s.check(time.Now())
+	if len(s.Overdue()) != 1 {
+		t.Errorf("expected heartbeat to be overdue")
+	}
+}
+
+func TestService_Expect(t *testing.T) {
+	sub := &recordingSubmitter{}
+	base := time.Now()
+	s := newTestService(t, sub, base, WithHeartbeat("db1", "backup", 10*time.Minute))
+	defer s.Close()
+
+	// results only declare heartbeats through Expect.
+	if _, err := s.SubmitHostResult(0, xdata.Up, "web01", "ok"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sub.take()
+	if heartbeats := s.Heartbeats(); len(heartbeats) != 1 {
+		t.Errorf("unexpected heartbeats, got: '%v'", heartbeats)
+	}
+
+	s.Expect("web01", "", 5*time.Minute)
+	s.Expect("db1", "backup", 20*time.Minute)
+	s.Expect("web01", "", 4*time.Minute)
+
+	heartbeats := s.Heartbeats()
+	if len(heartbeats) != 2 {
+		t.Fatalf("unexpected heartbeats, got: '%v'", heartbeats)
+	}
+
+	// results change the interval of declared heartbeats, but not of
+	// configured ones.
+	expected := []Heartbeat{
+		{Hostname: "db1", ServiceName: "backup", Interval: 10 * time.Minute, Configured: true},
+		{Hostname: "web01", Interval: 4 * time.Minute},
+	}
+	for i, h := range heartbeats {
+		if h.Hostname != expected[i].Hostname || h.ServiceName != expected[i].ServiceName ||
+			h.Interval != expected[i].Interval || h.Configured != expected[i].Configured {
+			t.Errorf("unexpected heartbeat, got: '%+v', want: '%+v'", h, expected[i])
+		}
+	}
+
+	// declared heartbeats are checked the same as configured ones.
+	s.check(time.Now().Add(6 * time.Minute))
+	results := sub.take()
+	if len(results) != 1 || results[0] != (result{"web01", "", int(xdata.Down), "no result received for 6m"}) {
+		t.Errorf("unexpected results, got: '%v'", results)
+	}
+
+	s.check(time.Now().Add(11 * time.Minute))
+	results = sub.take()
+	if len(results) != 2 {
+		t.Errorf("unexpected results, got: '%v'", results)
+	}
+
+	if !s.Forget("web01", "") || !s.Forget("db1", "backup") {
+		t.Errorf("expected heartbeats to be forgotten")
+	}
+	if s.Forget("web01", "") {
+		t.Errorf("expected no heartbeat to forget")
+	}
+	if heartbeats := s.Heartbeats(); len(heartbeats) != 0 {
+		t.Errorf("unexpected heartbeats, got: '%v'", heartbeats)
+	}
+}
+
+func TestWithHeartbeat_Invalid(t *testing.T) {
+	for _, opt := range []ServiceOption{
+		WithHeartbeat("", "backup", time.Hour),
+		WithHeartbeat("db1", "backup", time.Second),
+		WithCheckInterval(0),
+	} {
+		if _, err := NewService(WithSubmitter(&recordingSubmitter{}), opt); err == nil {
+			t.Errorf("expected error")
+		}
+	}
+
+	if _, err := NewService(); err == nil {
+		t.Errorf("expected error without a submitter")
+	}
+}
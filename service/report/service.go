@@ -0,0 +1,321 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/availability"
+	"github.com/jamesmichael/nagiosapi/nagios/history"
+	"github.com/jamesmichael/nagiosapi/nagios/objects"
+)
+
+var (
+	// ErrUnknownGroup is returned when a report is requested for a host or
+	// service group which is not defined in the objects cache.
+	ErrUnknownGroup = errors.New("unknown group")
+
+	// ErrGroupsUnavailable is returned when a group report is requested, but
+	// no objects cache file has been configured.
+	ErrGroupsUnavailable = errors.New("group reports require an objects cache file")
+)
+
+// SubjectType selects what an availability report is generated for.
+type SubjectType int
+
+const (
+	Hosts SubjectType = iota
+	Services
+	HostGroups
+	ServiceGroups
+)
+
+// ParseSubjectType converts a string into a SubjectType.
+func ParseSubjectType(s string) (SubjectType, error) {
+	switch s {
+	case "host", "hosts":
+		return Hosts, nil
+	case "service", "services":
+		return Services, nil
+	case "hostgroup", "hostgroups":
+		return HostGroups, nil
+	case "servicegroup", "servicegroups":
+		return ServiceGroups, nil
+	default:
+		return Hosts, fmt.Errorf("unknown report type '%s'", s)
+	}
+}
+
+// AvailabilityRequest describes the subjects and time window of an
+// availability report.
+//
+// Empty HostName, ServiceDescription or Group fields match every host,
+// service or group respectively.
+type AvailabilityRequest struct {
+	Type               SubjectType
+	HostName           string
+	ServiceDescription string
+	Group              string
+	Start              time.Time
+	End                time.Time
+	Options            availability.Options
+
+	// AssumeCurrentState uses the current state of each host or service as
+	// its initial state. It requires a status repository.
+	AssumeCurrentState bool
+}
+
+// GroupReport holds the reports for every member of a host or service group.
+type GroupReport struct {
+	Name    string
+	Members []*availability.Report
+}
+
+// AvailabilityReport is the result of an availability report.
+//
+// Groups is only set for host and service group reports; Reports holds the
+// individual host or service reports otherwise.
+type AvailabilityReport struct {
+	Type    SubjectType
+	Start   time.Time
+	End     time.Time
+	Reports []*availability.Report
+	Groups  []*GroupReport
+}
+
+// StateHistory provides the nagios state history.
+type StateHistory interface {
+	Events(start, end time.Time) ([]history.Event, error)
+}
+
+// StatusRepository provides the current state of hosts and services.
+type StatusRepository interface {
+	HostStatus(host string) (*xdata.HostStatus, error)
+	ServiceStatus(host, name string) (*xdata.ServiceStatus, error)
+}
+
+// Service generates availability reports from the nagios state history.
+type Service struct {
+	history     StateHistory
+	objectsFile string
+	status      StatusRepository
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.history == nil {
+		return nil, fmt.Errorf("must set state history")
+	}
+
+	return &s, nil
+}
+
+// Availability generates an availability report.
+func (s *Service) Availability(req AvailabilityRequest) (*AvailabilityReport, error) {
+	if !req.End.After(req.Start) {
+		return nil, fmt.Errorf("report end must be after start")
+	}
+
+	if req.AssumeCurrentState {
+		if s.status == nil {
+			return nil, fmt.Errorf("current state is unavailable")
+		}
+		req.Options.CurrentState = s.currentState
+	}
+
+	events, err := s.history.Events(req.Start, req.End)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AvailabilityReport{
+		Type:  req.Type,
+		Start: req.Start,
+		End:   req.End,
+	}
+
+	start, end := req.Start.Unix(), req.End.Unix()
+	switch req.Type {
+	case Hosts, Services:
+		subjects := filterSubjects(findSubjects(events, req.Type == Services), req)
+		res.Reports = availability.Calculate(events, subjects, start, end, req.Options)
+
+	case HostGroups, ServiceGroups:
+		groups, err := s.groups(req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range sortedKeys(groups) {
+			res.Groups = append(res.Groups, &GroupReport{
+				Name:    name,
+				Members: availability.Calculate(events, groups[name], start, end, req.Options),
+			})
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Service) currentState(subject availability.Subject) (int, bool) {
+	if subject.IsService() {
+		st, err := s.status.ServiceStatus(subject.HostName, subject.ServiceDescription)
+		if err != nil {
+			return availability.Undetermined, false
+		}
+		return int(st.CurrentState), true
+	}
+
+	st, err := s.status.HostStatus(subject.HostName)
+	if err != nil {
+		return availability.Undetermined, false
+	}
+	return int(st.CurrentState), true
+}
+
+func (s *Service) groups(req AvailabilityRequest) (map[string][]availability.Subject, error) {
+	if s.objectsFile == "" {
+		return nil, ErrGroupsUnavailable
+	}
+
+	g, err := objects.LoadGroups(s.objectsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]availability.Subject)
+	if req.Type == HostGroups {
+		for name, members := range g.HostGroups {
+			subjects := make([]availability.Subject, 0, len(members))
+			for _, host := range members {
+				subjects = append(subjects, availability.Subject{HostName: host})
+			}
+			groups[name] = subjects
+		}
+	} else {
+		for name, members := range g.ServiceGroups {
+			subjects := make([]availability.Subject, 0, len(members))
+			for _, ref := range members {
+				subjects = append(subjects, availability.Subject{
+					HostName:           ref.HostName,
+					ServiceDescription: ref.ServiceDescription,
+				})
+			}
+			groups[name] = subjects
+		}
+	}
+
+	if req.Group == "" {
+		return groups, nil
+	}
+
+	members, ok := groups[req.Group]
+	if !ok {
+		return nil, ErrUnknownGroup
+	}
+
+	return map[string][]availability.Subject{req.Group: members}, nil
+}
+
+// findSubjects returns every host or service mentioned in the state history.
+func findSubjects(events []history.Event, services bool) []availability.Subject {
+	seen := make(map[availability.Subject]bool)
+	subjects := make([]availability.Subject, 0)
+	for i := range events {
+		e := &events[i]
+		if e.Type != history.StateChange || e.IsServiceEvent() != services {
+			continue
+		}
+
+		s := availability.Subject{
+			HostName:           e.HostName,
+			ServiceDescription: e.ServiceDescription,
+		}
+		if !seen[s] {
+			seen[s] = true
+			subjects = append(subjects, s)
+		}
+	}
+
+	sort.Slice(subjects, func(i, j int) bool {
+		if subjects[i].HostName != subjects[j].HostName {
+			return subjects[i].HostName < subjects[j].HostName
+		}
+		return subjects[i].ServiceDescription < subjects[j].ServiceDescription
+	})
+
+	return subjects
+}
+
+func filterSubjects(subjects []availability.Subject, req AvailabilityRequest) []availability.Subject {
+	res := make([]availability.Subject, 0, len(subjects))
+	for _, s := range subjects {
+		if req.HostName != "" && s.HostName != req.HostName {
+			continue
+		}
+		if req.ServiceDescription != "" && s.ServiceDescription != req.ServiceDescription {
+			continue
+		}
+		res = append(res, s)
+	}
+
+	// a single host or service is always reported on, even if nothing has
+	// been logged for it.
+	if len(res) == 0 && req.HostName != "" && (req.Type == Hosts || req.ServiceDescription != "") {
+		res = append(res, availability.Subject{
+			HostName:           req.HostName,
+			ServiceDescription: req.ServiceDescription,
+		})
+	}
+
+	return res
+}
+
+func sortedKeys(m map[string][]availability.Subject) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithStateHistory sets the source of state history events.
+//
+// It should be an instance of history.Archive, but for testing, anything
+// which implements StateHistory would work.
+func WithStateHistory(h StateHistory) ServiceOption {
+	return func(s *Service) error {
+		s.history = h
+		return nil
+	}
+}
+
+// WithObjectsCacheFile sets the path to the nagios objects.cache file, which
+// is required for host and service group reports.
+func WithObjectsCacheFile(filename string) ServiceOption {
+	return func(s *Service) error {
+		s.objectsFile = filename
+		return nil
+	}
+}
+
+// WithStatusRepository sets the repository used to look up current states.
+func WithStatusRepository(r StatusRepository) ServiceOption {
+	return func(s *Service) error {
+		s.status = r
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+package submission
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Perfdata is a single performance data metric of a check result.
+//
+// Warn and Crit are nagios threshold ranges, such as "10", "10:20" or
+// "@~:5". Min and Max are numbers. Each of them may be left empty.
+type Perfdata struct {
+	Label string
+	Value float64
+	UOM   string
+	Warn  string
+	Crit  string
+	Min   string
+	Max   string
+}
+
+var (
+	// rangePattern matches a nagios threshold range, '[@]start:end', where
+	// start may be '~' for negative infinity, and either end may be left off.
+	rangePattern = regexp.MustCompile(`^@?(~|-?[0-9]+(\.[0-9]+)?)?(:(-?[0-9]+(\.[0-9]+)?)?)?$`)
+
+	// uomPattern matches a unit of measurement, such as 's', '%', 'KB' or
+	// 'c'.
+	uomPattern = regexp.MustCompile(`^[a-zA-Z%]*$`)
+)
+
+// RenderOutput renders the plugin output of a check result, in the form
+// 'output|perfdata\nlong_output' which nagios expects.
+//
+// Labels are quoted. The output is not sanitized, that is left to the
+// command it is submitted with. A FieldError is returned if the output
+// would be misread by nagios.
+func RenderOutput(output, longOutput string, perfdata []Perfdata) (string, error) {
+	if len(perfdata) == 0 && longOutput == "" {
+		return output, nil
+	}
+
+	// everything after a '|' is read as performance data.
+	if strings.Contains(output, "|") {
+		return "", &FieldError{Field: "body", Reason: "must not contain '|' when perfdata or long_output is set"}
+	}
+	if strings.Contains(longOutput, "|") {
+		return "", &FieldError{Field: "long_output", Reason: "must not contain '|'"}
+	}
+
+	metrics := make([]string, 0, len(perfdata))
+	for i, p := range perfdata {
+		m, err := p.render()
+		if err != nil {
+			err.Field = fmt.Sprintf("perfdata[%d].%s", i, err.Field)
+			return "", err
+		}
+		metrics = append(metrics, m)
+	}
+
+	var b strings.Builder
+	b.WriteString(output)
+	if len(metrics) > 0 {
+		b.WriteByte('|')
+		b.WriteString(strings.Join(metrics, " "))
+	}
+	if longOutput != "" {
+		b.WriteByte('\n')
+		b.WriteString(longOutput)
+	}
+
+	return b.String(), nil
+}
+
+// render formats the metric as 'label'=value[uom];[warn];[crit];[min];[max],
+// leaving off trailing empty fields.
+func (p Perfdata) render() (string, *FieldError) {
+	if strings.TrimSpace(p.Label) == "" {
+		return "", &FieldError{Field: "label", Reason: "must not be empty"}
+	}
+	if strings.ContainsAny(p.Label, "=\n") {
+		return "", &FieldError{Field: "label", Reason: "must not contain '=' or new-lines"}
+	}
+
+	if !uomPattern.MatchString(p.UOM) {
+		return "", &FieldError{Field: "uom", Reason: "must only contain letters or '%'"}
+	}
+
+	for _, r := range []struct {
+		field string
+		value string
+	}{{"warn", p.Warn}, {"crit", p.Crit}} {
+		if r.value != "" && !rangePattern.MatchString(r.value) {
+			return "", &FieldError{Field: r.field, Reason: "must be a nagios threshold range"}
+		}
+	}
+
+	for _, n := range []struct {
+		field string
+		value string
+	}{{"min", p.Min}, {"max", p.Max}} {
+		if n.value == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(n.value, 64); err != nil {
+			return "", &FieldError{Field: n.field, Reason: "must be a number"}
+		}
+	}
+
+	// single quotes in a quoted label are escaped by doubling them.
+	label := "'" + strings.Replace(p.Label, "'", "''", -1) + "'"
+	value := strconv.FormatFloat(p.Value, 'f', -1, 64) + p.UOM
+
+	fields := []string{label + "=" + value, p.Warn, p.Crit, p.Min, p.Max}
+	for len(fields) > 1 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+
+	return strings.Join(fields, ";"), nil
+}
@@ -0,0 +1,137 @@
+package submission
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPerfdata_render(t *testing.T) {
+	tests := []struct {
+		input    Perfdata
+		expected string
+	}{
+		{Perfdata{Label: "load1", Value: 0.5}, "'load1'=0.5"},
+		{Perfdata{Label: "time", Value: 12, UOM: "ms"}, "'time'=12ms"},
+		{Perfdata{Label: "disk /", Value: 80, UOM: "%", Warn: "90", Crit: "95", Min: "0", Max: "100"}, "'disk /'=80%;90;95;0;100"},
+		{Perfdata{Label: "it's", Value: 1}, "'it''s'=1"},
+		{Perfdata{Label: "'quoted'", Value: 1}, "'''quoted'''=1"},
+		{Perfdata{Label: "users", Value: 3, Warn: "5"}, "'users'=3;5"},
+		{Perfdata{Label: "users", Value: 3, Crit: "10"}, "'users'=3;;10"},
+		{Perfdata{Label: "users", Value: 3, Max: "20"}, "'users'=3;;;;20"},
+		{Perfdata{Label: "temp", Value: -4.25, Warn: "@~:5", Crit: "-10:"}, "'temp'=-4.25;@~:5;-10:"},
+		{Perfdata{Label: "temp", Value: 20, Warn: "10:20", Crit: ":30.5"}, "'temp'=20;10:20;:30.5"},
+		{Perfdata{Label: "bytes", Value: 1e9, UOM: "B"}, "'bytes'=1000000000B"},
+		{Perfdata{Label: "requests", Value: 100, UOM: "c", Min: "-1.5"}, "'requests'=100c;;;-1.5"},
+	}
+
+	for _, test := range tests {
+		s, err := test.input.render()
+		if err != nil {
+			t.Errorf("unable to render perfdata '%v': %s", test.input, err)
+			continue
+		}
+
+		if s != test.expected {
+			t.Errorf("render returned incorrect output, got: '%s', want: '%s'", s, test.expected)
+		}
+	}
+}
+
+func TestPerfdata_render_Invalid(t *testing.T) {
+	tests := []struct {
+		input Perfdata
+		field string
+	}{
+		{Perfdata{Label: ""}, "label"},
+		{Perfdata{Label: "  "}, "label"},
+		{Perfdata{Label: "a=b"}, "label"},
+		{Perfdata{Label: "a\nb"}, "label"},
+		{Perfdata{Label: "time", UOM: "m s"}, "uom"},
+		{Perfdata{Label: "time", UOM: "1s"}, "uom"},
+		{Perfdata{Label: "time", UOM: "s;"}, "uom"},
+		{Perfdata{Label: "time", Warn: "abc"}, "warn"},
+		{Perfdata{Label: "time", Warn: "1:2:3"}, "warn"},
+		{Perfdata{Label: "time", Warn: "@@1"}, "warn"},
+		{Perfdata{Label: "time", Crit: "~1"}, "crit"},
+		{Perfdata{Label: "time", Crit: "1;2"}, "crit"},
+		{Perfdata{Label: "time", Min: "zero"}, "min"},
+		{Perfdata{Label: "time", Max: "10%"}, "max"},
+	}
+
+	for _, test := range tests {
+		_, err := test.input.render()
+		if err == nil {
+			t.Errorf("expected error when rendering '%v'", test.input)
+			continue
+		}
+
+		if err.Field != test.field {
+			t.Errorf("unexpected field for '%v', got: '%s', want: '%s'", test.input, err.Field, test.field)
+		}
+	}
+}
+
+func TestRenderOutput(t *testing.T) {
+	tests := []struct {
+		output     string
+		longOutput string
+		perfdata   []Perfdata
+		expected   string
+	}{
+		{"OK", "", nil, "OK"},
+		{"OK | not perfdata", "", nil, "OK | not perfdata"},
+		{"OK", "line 1\nline 2", nil, "OK\nline 1\nline 2"},
+		{"OK", "", []Perfdata{{Label: "a", Value: 1}}, "OK|'a'=1"},
+		{
+			"OK - 2 users",
+			"user1\nuser2",
+			[]Perfdata{{Label: "users", Value: 2, Warn: "5", Crit: "10"}, {Label: "load", Value: 0.25}},
+			"OK - 2 users|'users'=2;5;10 'load'=0.25\nuser1\nuser2",
+		},
+		{"", "", []Perfdata{{Label: "a", Value: 1}}, "|'a'=1"},
+	}
+
+	for _, test := range tests {
+		s, err := RenderOutput(test.output, test.longOutput, test.perfdata)
+		if err != nil {
+			t.Errorf("unable to render output '%s': %s", test.output, err)
+			continue
+		}
+
+		if s != test.expected {
+			t.Errorf("render returned incorrect output, got: '%s', want: '%s'", s, test.expected)
+		}
+	}
+}
+
+func TestRenderOutput_Invalid(t *testing.T) {
+	tests := []struct {
+		output     string
+		longOutput string
+		perfdata   []Perfdata
+		field      string
+	}{
+		{"OK|", "", []Perfdata{{Label: "a"}}, "body"},
+		{"OK|", "long", nil, "body"},
+		{"OK", "a|b", nil, "long_output"},
+		{"OK", "", []Perfdata{{Label: "a"}, {Label: "b", UOM: "?"}}, "perfdata[1].uom"},
+		{"OK", "", []Perfdata{{Label: "a=b"}}, "perfdata[0].label"},
+	}
+
+	for _, test := range tests {
+		_, err := RenderOutput(test.output, test.longOutput, test.perfdata)
+
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			t.Errorf("unexpected error for '%s', got: '%v', want: a FieldError", test.output, err)
+			continue
+		}
+
+		if fe.Field != test.field {
+			t.Errorf("unexpected field for '%s', got: '%s', want: '%s'", test.output, fe.Field, test.field)
+		}
+		if !errors.Is(err, ErrInvalidResult) {
+			t.Errorf("unexpected error, got: '%v', want: '%v'", err, ErrInvalidResult)
+		}
+	}
+}
@@ -1,22 +1,44 @@
 package submission
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
 	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/nagios/statusdata"
 )
 
+// Repository provides access to the hosts and services in the nagios status
+// file.
+type Repository interface {
+	HostStatus(host string) (*xdata.HostStatus, error)
+	ServiceStatus(host, name string) (*xdata.ServiceStatus, error)
+}
+
 // Service is used to write passive results to the nagios external commands
 // file.
 type Service struct {
 	externalCommandsFile io.Writer
+	maxClockSkew         time.Duration
+	maxOutputLength      int
+	maxCommandLength     int
+
+	repo     Repository
+	unknown  UnknownTargetPolicy
+	unknowns *unknownTargets
 }
 
 // NewService constructs an instance of Service.
 func NewService(opts ...ServiceOption) (*Service, error) {
-	s := Service{}
+	s := Service{
+		maxClockSkew:    DefaultMaxClockSkew,
+		maxOutputLength: DefaultMaxOutputLength,
+		unknowns:        newUnknownTargets(DefaultUnknownTargetSize),
+	}
 	for _, opt := range opts {
 		if err := opt(&s); err != nil {
 			return nil, err
@@ -27,38 +49,158 @@ func NewService(opts ...ServiceOption) (*Service, error) {
 		return nil, fmt.Errorf("must set external commands file")
 	}
 
+	if s.unknown != UnknownAllow && s.repo == nil {
+		return nil, fmt.Errorf("must set status repository to check for unknown targets")
+	}
+
 	return &s, nil
 }
 
+// deliverer is implemented by writers which report the result of writing a
+// command to each of their targets, such as cmd.FanOut.
+type deliverer interface {
+	Deliver(cmd []byte) ([]cmd.Delivery, error)
+}
+
 // SubmitPassiveResult constructs a nagios command for submitting a passive
 // service check result and queues the command for writing to the nagios
 // external commands file.
 //
 // If the ServiceResult does not have a time set, the current unix timestamp
 // is used instead.
+//
+// If the external commands writer has several targets, the result of the
+// delivery to each target is returned, otherwise the deliveries are nil.
+//
+// A FieldError is returned if the result fails validation.
 func (s *Service) SubmitResult(
 	checkTime int64,
-	statusCode uint,
+	state xdata.ServiceState,
 	hostname string,
 	serviceName string,
 	body string,
-) error {
-	if checkTime == 0 {
-		checkTime = time.Now().Unix()
+) ([]cmd.Delivery, error) {
+	if err := s.validate(checkTime, hostname, body); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(serviceName) == "" {
+		return nil, &FieldError{Field: "service_name", Reason: "must not be empty"}
+	}
+
+	if state.String() == "" {
+		return nil, &FieldError{Field: "status", Reason: "must be between 0 and 3, or OK, WARNING, CRITICAL or UNKNOWN"}
+	}
+
+	if err := s.checkTarget(hostname, serviceName); err != nil {
+		return nil, err
+	}
+
+	command, err := cmd.ProcessServiceCheckResult(hostname, serviceName, state, body)
+	if err != nil {
+		return nil, fieldError(err)
+	}
+
+	if checkTime != 0 {
+		command = command.At(time.Unix(checkTime, 0))
+	}
+
+	if err := s.checkLength(command); err != nil {
+		return nil, err
+	}
+
+	return s.write(command)
+}
+
+// SubmitHostResult constructs a nagios command for submitting a passive host
+// check result and queues the command for writing to the nagios external
+// commands file.
+//
+// If checkTime is not set, the current unix timestamp is used instead.
+//
+// A FieldError is returned if the result fails validation.
+func (s *Service) SubmitHostResult(
+	checkTime int64,
+	state xdata.HostState,
+	hostname string,
+	body string,
+) ([]cmd.Delivery, error) {
+	if err := s.validate(checkTime, hostname, body); err != nil {
+		return nil, err
+	}
+
+	if state.String() == "" {
+		return nil, &FieldError{Field: "status", Reason: "must be between 0 and 2, or UP, DOWN or UNREACHABLE"}
+	}
+
+	if err := s.checkTarget(hostname, ""); err != nil {
+		return nil, err
+	}
+
+	command, err := cmd.ProcessHostCheckResult(hostname, state, body)
+	if err != nil {
+		return nil, fieldError(err)
+	}
+
+	if checkTime != 0 {
+		command = command.At(time.Unix(checkTime, 0))
+	}
+
+	if err := s.checkLength(command); err != nil {
+		return nil, err
+	}
+
+	return s.write(command)
+}
+
+// UnknownTargets returns the hosts and services which results were submitted
+// for, but which are not in the nagios status file, most recently seen
+// first.
+func (s *Service) UnknownTargets() []UnknownTarget {
+	return s.unknowns.list()
+}
+
+// checkTarget looks up the host, or host and service, of a result in the
+// nagios status file, according to the unknown target policy.
+//
+// Hosts and services which were added since the status file was last read
+// are reported as unknown.
+func (s *Service) checkTarget(hostname, serviceName string) error {
+	if s.unknown == UnknownAllow {
+		return nil
+	}
+
+	var err error
+	if serviceName == "" {
+		_, err = s.repo.HostStatus(hostname)
+	} else {
+		_, err = s.repo.ServiceStatus(hostname, serviceName)
+	}
+
+	if errors.Is(err, statusdata.ErrUnknownHost) || errors.Is(err, statusdata.ErrUnknownService) {
+		s.unknowns.add(hostname, serviceName, s.unknown == UnknownReject)
+		if s.unknown == UnknownReject {
+			return err
+		}
+		return nil
+	}
+
+	return err
+}
+
+// write writes a command to the external commands writer, returning the
+// result of the delivery to each target if there are several.
+func (s *Service) write(command cmd.Command) ([]cmd.Delivery, error) {
+	if d, ok := s.externalCommandsFile.(deliverer); ok {
+		deliveries, err := d.Deliver([]byte(command.String()))
+		return deliveries, fieldError(err)
 	}
 
-	command := fmt.Sprintf("[%d] PROCESS_SERVICE_CHECK_RESULT;%s;%s;%d;%s",
-		checkTime,
-		cmd.Sanitize(hostname),
-		cmd.Sanitize(serviceName),
-		statusCode,
-		cmd.Sanitize(body),
-	)
-	if _, err := s.externalCommandsFile.Write([]byte(command)); err != nil {
-		return err
+	if _, err := command.WriteTo(s.externalCommandsFile); err != nil {
+		return nil, fieldError(err)
 	}
 
-	return nil
+	return nil, nil
 }
 
 // ServiceOption passes parameters to NewService().
@@ -74,3 +216,71 @@ func WithExternalCommandsWriter(w io.Writer) ServiceOption {
 		return nil
 	}
 }
+
+// WithMaxClockSkew sets how far the time of a result may be from the current
+// time. Zero allows any time.
+func WithMaxClockSkew(d time.Duration) ServiceOption {
+	return func(s *Service) error {
+		if d < 0 {
+			return fmt.Errorf("max clock skew must not be negative")
+		}
+		s.maxClockSkew = d
+		return nil
+	}
+}
+
+// WithMaxOutputLength sets the maximum length of the output of a result, in
+// bytes. Zero allows any length.
+func WithMaxOutputLength(n int) ServiceOption {
+	return func(s *Service) error {
+		if n < 0 {
+			return fmt.Errorf("max output length must not be negative")
+		}
+		s.maxOutputLength = n
+		return nil
+	}
+}
+
+// WithMaxCommandLength sets the maximum length of a rendered command,
+// including its new-line, such as cmd.PipeBuf for the external commands
+// file. Results which would exceed it fail validation on their body. Zero
+// allows any length.
+func WithMaxCommandLength(n int) ServiceOption {
+	return func(s *Service) error {
+		if n < 0 {
+			return fmt.Errorf("max command length must not be negative")
+		}
+		s.maxCommandLength = n
+		return nil
+	}
+}
+
+// WithRepository sets the repository used to look up hosts and services.
+func WithRepository(r Repository) ServiceOption {
+	return func(s *Service) error {
+		s.repo = r
+		return nil
+	}
+}
+
+// WithUnknownTargetPolicy sets what happens to results for hosts and
+// services which are not in the nagios status file. A repository must also
+// be set, unless the policy is UnknownAllow.
+func WithUnknownTargetPolicy(p UnknownTargetPolicy) ServiceOption {
+	return func(s *Service) error {
+		s.unknown = p
+		return nil
+	}
+}
+
+// WithUnknownTargetSize sets the number of unknown hosts and services which
+// are remembered.
+func WithUnknownTargetSize(n int) ServiceOption {
+	return func(s *Service) error {
+		if n <= 0 {
+			return fmt.Errorf("unknown target size must be positive")
+		}
+		s.unknowns = newUnknownTargets(n)
+		return nil
+	}
+}
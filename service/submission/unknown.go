@@ -0,0 +1,129 @@
+package submission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUnknownTargetSize is the number of unknown hosts and services which
+// are remembered.
+const DefaultUnknownTargetSize = 1000
+
+// UnknownTargetPolicy decides what happens to results for hosts and services
+// which are not in the nagios status file.
+type UnknownTargetPolicy int
+
+const (
+	// UnknownAllow submits results without checking the status file.
+	UnknownAllow UnknownTargetPolicy = iota
+
+	// UnknownWarn submits results for unknown targets, and records them.
+	UnknownWarn
+
+	// UnknownReject records results for unknown targets, and returns
+	// statusdata.ErrUnknownHost or statusdata.ErrUnknownService instead of
+	// submitting them.
+	UnknownReject
+)
+
+// ParseUnknownTargetPolicy converts the name of a policy, as used in the
+// config file, into an UnknownTargetPolicy.
+func ParseUnknownTargetPolicy(s string) (UnknownTargetPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "allow":
+		return UnknownAllow, nil
+	case "warn":
+		return UnknownWarn, nil
+	case "reject":
+		return UnknownReject, nil
+	default:
+		return UnknownAllow, fmt.Errorf("unknown target policy '%s'", s)
+	}
+}
+
+// UnknownTarget is a host, or host and service, which results were submitted
+// for but which is not in the nagios status file. Host results have an empty
+// ServiceName.
+type UnknownTarget struct {
+	Hostname    string
+	ServiceName string
+	Count       int
+	Rejected    int
+	FirstSeen   time.Time
+	LastSeen    time.Time
+}
+
+// unknownTargets remembers the most recently seen unknown targets.
+type unknownTargets struct {
+	mu      sync.Mutex
+	size    int
+	targets map[string]*UnknownTarget
+}
+
+func newUnknownTargets(size int) *unknownTargets {
+	return &unknownTargets{
+		size:    size,
+		targets: make(map[string]*UnknownTarget),
+	}
+}
+
+// add records a result for an unknown target, forgetting the least recently
+// seen target if the list is full.
+func (u *unknownTargets) add(hostname, serviceName string, rejected bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	key := hostname + ";" + serviceName
+
+	t, ok := u.targets[key]
+	if !ok {
+		if len(u.targets) >= u.size {
+			u.evict()
+		}
+		t = &UnknownTarget{
+			Hostname:    hostname,
+			ServiceName: serviceName,
+			FirstSeen:   now,
+		}
+		u.targets[key] = t
+	}
+
+	t.Count++
+	if rejected {
+		t.Rejected++
+	}
+	t.LastSeen = now
+}
+
+// evict forgets the least recently seen target. The caller must hold the
+// lock.
+func (u *unknownTargets) evict() {
+	var oldest string
+	for key, t := range u.targets {
+		if oldest == "" || t.LastSeen.Before(u.targets[oldest].LastSeen) {
+			oldest = key
+		}
+	}
+	delete(u.targets, oldest)
+}
+
+// list returns the unknown targets, most recently seen first.
+func (u *unknownTargets) list() []UnknownTarget {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	res := make([]UnknownTarget, 0, len(u.targets))
+	for _, t := range u.targets {
+		res = append(res, *t)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LastSeen.After(res[j].LastSeen)
+	})
+
+	return res
+}
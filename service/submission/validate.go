@@ -0,0 +1,117 @@
+package submission
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+)
+
+// DefaultMaxClockSkew is how far the time of a result may be from the
+// current time.
+const DefaultMaxClockSkew = time.Hour
+
+// DefaultMaxOutputLength is the nagios limit on the length of plugin output.
+const DefaultMaxOutputLength = 8192
+
+// ErrInvalidResult is returned when a passive check result fails
+// validation.
+var ErrInvalidResult = errors.New("invalid result")
+
+// FieldError describes which field of a passive check result failed
+// validation, and why.
+//
+// Field is one of "time", "hostname", "service_name", "status" or "body".
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid field '%s': %s", e.Field, e.Reason)
+}
+
+// Unwrap allows errors.Is to match ErrInvalidResult.
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidResult
+}
+
+// fields maps the arguments of the check result commands to the fields of
+// a result.
+var fields = map[string]string{
+	"host_name":           "hostname",
+	"service_description": "service_name",
+	"return_code":         "status",
+	"status_code":         "status",
+	"plugin_output":       "body",
+}
+
+// validate checks the fields shared by host and service results.
+func (s *Service) validate(checkTime int64, hostname, body string) error {
+	if strings.TrimSpace(hostname) == "" {
+		return &FieldError{Field: "hostname", Reason: "must not be empty"}
+	}
+
+	if checkTime != 0 && s.maxClockSkew > 0 {
+		skew := time.Since(time.Unix(checkTime, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+		if checkTime < 0 || skew > s.maxClockSkew {
+			return &FieldError{
+				Field:  "time",
+				Reason: fmt.Sprintf("must be within %s of the current time", s.maxClockSkew),
+			}
+		}
+	}
+
+	if s.maxOutputLength > 0 && len(body) > s.maxOutputLength {
+		return &FieldError{
+			Field:  "body",
+			Reason: fmt.Sprintf("must be at most %d bytes", s.maxOutputLength),
+		}
+	}
+
+	return nil
+}
+
+// checkLength checks that the rendered command fits in a single write to the
+// external commands file, as the writer rejects longer commands. The limit
+// on the output depends on the length of the host and service names.
+func (s *Service) checkLength(command cmd.Command) error {
+	if s.maxCommandLength <= 0 || len(command.Args) == 0 {
+		return nil
+	}
+
+	// the writer adds a new-line to each command.
+	line := command.String()
+	if len(line)+1 <= s.maxCommandLength {
+		return nil
+	}
+
+	// the plugin output is the last argument of the check result commands.
+	overhead := len(line) - len(command.Args[len(command.Args)-1]) + 1
+	return &FieldError{
+		Field:  "body",
+		Reason: fmt.Sprintf("must be at most %d bytes, once escaped, for this host and service", s.maxCommandLength-overhead),
+	}
+}
+
+// fieldError converts the errors from building and writing a command into
+// a FieldError, where they are caused by a field of the result.
+func fieldError(err error) error {
+	var argErr *cmd.ArgumentError
+	if errors.As(err, &argErr) {
+		if field, ok := fields[argErr.Argument]; ok {
+			return &FieldError{Field: field, Reason: argErr.Reason}
+		}
+	}
+
+	if errors.Is(err, cmd.ErrCommandTooLong) {
+		return &FieldError{Field: "body", Reason: "too long for the external commands file"}
+	}
+
+	return err
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// DefaultSecretHeader is the header holding the shared secret of a hook.
+const DefaultSecretHeader = "X-Webhook-Secret"
+
+// Signature describes the HMAC signature of a hook's request body, such as
+// the X-Hub-Signature-256 header sent by GitHub.
+type Signature struct {
+	// Secret is the HMAC key. Signatures are not checked when it is empty.
+	Secret string
+
+	// Header holds the hex encoded signature, after Prefix, such as
+	// 'sha256='.
+	Header string
+	Prefix string
+
+	// Algorithm is one of sha1, sha256 or sha512.
+	Algorithm string
+}
+
+// algorithms are the hash functions which may be used for signatures.
+var algorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// authenticator checks the shared secret and signature of a request.
+type authenticator struct {
+	secret       string
+	secretHeader string
+
+	signature Signature
+	algorithm func() hash.Hash
+}
+
+func newAuthenticator(h Hook) (*authenticator, error) {
+	a := authenticator{
+		secret:       h.Secret,
+		secretHeader: h.SecretHeader,
+		signature:    h.Signature,
+	}
+	if a.secretHeader == "" {
+		a.secretHeader = DefaultSecretHeader
+	}
+
+	// hooks are not behind basic authentication, so anyone could submit
+	// results to a hook without a secret or signature.
+	if a.secret == "" && a.signature.Secret == "" {
+		return nil, fmt.Errorf("must set a secret or signature")
+	}
+
+	if a.signature.Secret != "" {
+		if a.signature.Header == "" {
+			return nil, fmt.Errorf("must set signature header")
+		}
+
+		alg, ok := algorithms[strings.ToLower(a.signature.Algorithm)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported signature algorithm '%s'", a.signature.Algorithm)
+		}
+		a.algorithm = alg
+	}
+
+	return &a, nil
+}
+
+// authenticate checks the shared secret, which may be sent either in the
+// secret header or as the token query parameter, and the signature of the
+// body.
+func (a *authenticator) authenticate(header http.Header, token string, body []byte) error {
+	if a.secret != "" {
+		got := header.Get(a.secretHeader)
+		if got == "" {
+			got = token
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(a.secret)) != 1 {
+			return fmt.Errorf("%w: bad secret", ErrUnauthorized)
+		}
+	}
+
+	if a.signature.Secret != "" {
+		got := strings.TrimPrefix(strings.TrimSpace(header.Get(a.signature.Header)), a.signature.Prefix)
+		sig, err := hex.DecodeString(got)
+		if err != nil {
+			return fmt.Errorf("%w: bad signature", ErrUnauthorized)
+		}
+
+		mac := hmac.New(a.algorithm, []byte(a.signature.Secret))
+		mac.Write(body)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return fmt.Errorf("%w: bad signature", ErrUnauthorized)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	body := []byte(`{"host": "web01"}`)
+
+	// hex encoded hmacs of body, keyed with "changeme".
+	const (
+		sha1Sig   = "33d158f3142188fb34bfc30792db722a933b90fa"
+		sha256Sig = "f5327aa29d9d363b09779f35a68941f3299c11eb8c22cd3bd1036f497ad8f5bb"
+	)
+
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	secret := Hook{Secret: "changeme"}
+	customHeader := Hook{Secret: "changeme", SecretHeader: "X-Token"}
+	signed := Hook{Signature: Signature{Secret: "changeme", Header: "X-Hub-Signature-256", Prefix: "sha256=", Algorithm: "sha256"}}
+	signedSHA1 := Hook{Signature: Signature{Secret: "changeme", Header: "X-Signature", Algorithm: "SHA1"}}
+	both := Hook{Secret: "token", Signature: signed.Signature}
+
+	tests := []struct {
+		name   string
+		hook   Hook
+		header http.Header
+		token  string
+		ok     bool
+	}{
+		{"secret header", secret, header(DefaultSecretHeader, "changeme"), "", true},
+		{"secret token", secret, header(), "changeme", true},
+		{"secret header preferred", secret, header(DefaultSecretHeader, "wrong"), "changeme", false},
+		{"wrong secret", secret, header(DefaultSecretHeader, "wrong"), "", false},
+		{"missing secret", secret, header(), "", false},
+		{"custom header", customHeader, header("X-Token", "changeme"), "", true},
+		{"default header ignored", customHeader, header(DefaultSecretHeader, "changeme"), "", false},
+		{"signature", signed, header("X-Hub-Signature-256", "sha256="+sha256Sig), "", true},
+		{"signature without prefix", signed, header("X-Hub-Signature-256", sha256Sig), "", true},
+		{"wrong signature", signed, header("X-Hub-Signature-256", "sha256="+sha1Sig), "", false},
+		{"bad signature", signed, header("X-Hub-Signature-256", "sha256=zz"), "", false},
+		{"missing signature", signed, header(), "", false},
+		{"sha1 signature", signedSHA1, header("X-Signature", sha1Sig), "", true},
+		{"secret and signature", both, header("X-Hub-Signature-256", "sha256="+sha256Sig), "token", true},
+		{"signature without secret", both, header("X-Hub-Signature-256", "sha256="+sha256Sig), "", false},
+	}
+
+	for _, test := range tests {
+		a, err := newAuthenticator(test.hook)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		err = a.authenticate(test.header, test.token, body)
+		if test.ok && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		}
+		if !test.ok && !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, ErrUnauthorized)
+		}
+	}
+}
+
+func TestNewAuthenticator_NoSecret(t *testing.T) {
+	// hooks are not behind basic authentication, so must authenticate
+	// requests themselves.
+	for _, h := range []Hook{
+		{},
+		{SecretHeader: "X-Token"},
+		{Signature: Signature{Header: "X-Signature", Algorithm: "sha256"}},
+	} {
+		if _, err := newAuthenticator(h); err == nil {
+			t.Errorf("expected error for '%+v'", h)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/jamesmichael/nagiosapi/encoding/jsonpath"
+)
+
+// expression extracts a field of a check result from a decoded payload.
+type expression interface {
+	evaluate(payload interface{}) (string, error)
+}
+
+// parseExpression parses either a JSONPath, starting with '$', or a go
+// template. An empty expression always evaluates to an empty string.
+func parseExpression(name, text string) (expression, error) {
+	switch {
+	case strings.TrimSpace(text) == "":
+		return emptyExpression{}, nil
+	case strings.HasPrefix(strings.TrimSpace(text), "$"):
+		p, err := jsonpath.Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		return pathExpression{p}, nil
+	default:
+		// missing keys are errors, rather than rendered as '<no value>'.
+		t, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		return templateExpression{t}, nil
+	}
+}
+
+// funcs are the functions available to templates. They accept any JSON
+// value, as a status may be sent as a number.
+var funcs = template.FuncMap{
+	"lower":   func(v interface{}) string { return strings.ToLower(mustString(v)) },
+	"upper":   func(v interface{}) string { return strings.ToUpper(mustString(v)) },
+	"replace": func(old, new string, v interface{}) string { return strings.Replace(mustString(v), old, new, -1) },
+}
+
+type emptyExpression struct{}
+
+func (emptyExpression) evaluate(payload interface{}) (string, error) {
+	return "", nil
+}
+
+type pathExpression struct {
+	path *jsonpath.Path
+}
+
+func (e pathExpression) evaluate(payload interface{}) (string, error) {
+	v, err := e.path.Get(payload)
+	if err != nil {
+		return "", err
+	}
+	return toString(v)
+}
+
+type templateExpression struct {
+	template *template.Template
+}
+
+func (e templateExpression) evaluate(payload interface{}) (string, error) {
+	var b strings.Builder
+	if err := e.template.Execute(&b, payload); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// toString converts a JSON value into a string. Objects and arrays are
+// encoded as JSON.
+func toString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// mustString converts a JSON value into a string, ignoring errors.
+func mustString(v interface{}) string {
+	s, _ := toString(v)
+	return s
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/submission"
+)
+
+var (
+	// ErrUnknownHook is returned when a request is made to a hook which is
+	// not configured.
+	ErrUnknownHook = errors.New("unknown hook")
+
+	// ErrUnauthorized is returned when the shared secret or signature of a
+	// request does not match.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrInvalidPayload is returned when the request body is not JSON.
+	ErrInvalidPayload = errors.New("invalid payload")
+)
+
+// Hook maps the JSON payloads posted to a webhook onto passive check
+// results.
+//
+// Host, Service, Status and Output are either JSONPath expressions, such as
+// '$.check.host', or go templates executed with the payload, such as
+// '{{ .check.host }}'. Hooks without a Service submit host results.
+//
+// States maps the extracted status onto the name or code of a nagios state.
+// Statuses which are not in States must be a nagios state themselves.
+type Hook struct {
+	Name    string
+	Host    string
+	Service string
+	Status  string
+	Output  string
+	States  map[string]string
+
+	// Secret is compared with the SecretHeader of a request, or its token
+	// query parameter. It is not checked when empty, but either it or the
+	// Signature secret must be set.
+	Secret       string
+	SecretHeader string
+
+	Signature Signature
+}
+
+// Check is the passive check result a payload was mapped onto. Host results
+// have an empty ServiceName.
+type Check struct {
+	Hostname    string
+	ServiceName string
+	Status      string
+	Output      string
+}
+
+// Submitter submits passive check results, such as submission.Service.
+type Submitter interface {
+	SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error)
+	SubmitHostResult(time int64, state xdata.HostState, hostname, body string) ([]cmd.Delivery, error)
+}
+
+// hook is a Hook with its expressions parsed.
+type hook struct {
+	host    expression
+	service expression
+	status  expression
+	output  expression
+	states  map[string]string
+	auth    *authenticator
+
+	isHost bool
+}
+
+// Service submits the payloads posted to webhooks as passive check results.
+type Service struct {
+	submitter Submitter
+	hooks     map[string]*hook
+}
+
+// NewService constructs an instance of Service.
+func NewService(opts ...ServiceOption) (*Service, error) {
+	s := Service{
+		hooks: make(map[string]*hook),
+	}
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.submitter == nil {
+		return nil, fmt.Errorf("must set submitter")
+	}
+
+	return &s, nil
+}
+
+// Submit authenticates a request to the named hook, maps its payload onto a
+// passive check result, and submits it. The token is the token query
+// parameter of the request, if any.
+//
+// ErrUnknownHook, ErrUnauthorized or ErrInvalidPayload are returned if the
+// request is rejected, and a submission.FieldError if a field of the result
+// can not be extracted from the payload.
+func (s *Service) Submit(name string, header http.Header, token string, body []byte) (Check, []cmd.Delivery, error) {
+	h, ok := s.hooks[name]
+	if !ok {
+		return Check{}, nil, ErrUnknownHook
+	}
+
+	if err := h.auth.authenticate(header, token, body); err != nil {
+		return Check{}, nil, err
+	}
+
+	// numbers are kept as they were sent, so a status of 2 is not "2e+00".
+	var payload interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return Check{}, nil, fmt.Errorf("%w: %s", ErrInvalidPayload, err)
+	}
+
+	check, err := h.extract(payload)
+	if err != nil {
+		return check, nil, err
+	}
+
+	// the time of the check is when it is received.
+	if h.isHost {
+		state, err := h.hostState(check.Status)
+		if err != nil {
+			return check, nil, err
+		}
+		deliveries, err := s.submitter.SubmitHostResult(0, state, check.Hostname, check.Output)
+		return check, deliveries, err
+	}
+
+	state, err := h.serviceState(check.Status)
+	if err != nil {
+		return check, nil, err
+	}
+	deliveries, err := s.submitter.SubmitResult(0, state, check.Hostname, check.ServiceName, check.Output)
+	return check, deliveries, err
+}
+
+// extract evaluates the expressions of the hook against the payload.
+func (h *hook) extract(payload interface{}) (Check, error) {
+	var check Check
+	for _, f := range []struct {
+		field string
+		expr  expression
+		dest  *string
+	}{
+		{"hostname", h.host, &check.Hostname},
+		{"service_name", h.service, &check.ServiceName},
+		{"status", h.status, &check.Status},
+		{"body", h.output, &check.Output},
+	} {
+		v, err := f.expr.evaluate(payload)
+		if err != nil {
+			return check, &submission.FieldError{Field: f.field, Reason: err.Error()}
+		}
+		*f.dest = v
+	}
+
+	return check, nil
+}
+
+// state looks up the status in the states of the hook, falling back to the
+// status itself.
+func (h *hook) state(status string) string {
+	if state, ok := h.states[strings.ToLower(strings.TrimSpace(status))]; ok {
+		return state
+	}
+	return status
+}
+
+func (h *hook) hostState(status string) (xdata.HostState, error) {
+	state, err := xdata.ParseHostState([]byte(h.state(status)))
+	if err != nil {
+		return state, &submission.FieldError{
+			Field:  "status",
+			Reason: fmt.Sprintf("'%s' is not a host state", status),
+		}
+	}
+	return state, nil
+}
+
+func (h *hook) serviceState(status string) (xdata.ServiceState, error) {
+	state, err := xdata.ParseServiceState([]byte(h.state(status)))
+	if err != nil {
+		return state, &submission.FieldError{
+			Field:  "status",
+			Reason: fmt.Sprintf("'%s' is not a service state", status),
+		}
+	}
+	return state, nil
+}
+
+// ServiceOption passes parameters to NewService().
+type ServiceOption func(s *Service) error
+
+// WithSubmitter sets where the passive check results are submitted.
+func WithSubmitter(sub Submitter) ServiceOption {
+	return func(s *Service) error {
+		s.submitter = sub
+		return nil
+	}
+}
+
+// WithHook adds a hook. Each hook must have a unique name, at least a Host
+// and Status, and a Secret or Signature.
+func WithHook(h Hook) ServiceOption {
+	return func(s *Service) error {
+		if h.Name == "" {
+			return fmt.Errorf("hook must have a name")
+		}
+		if _, ok := s.hooks[h.Name]; ok {
+			return fmt.Errorf("duplicate hook '%s'", h.Name)
+		}
+		if strings.TrimSpace(h.Host) == "" || strings.TrimSpace(h.Status) == "" {
+			return fmt.Errorf("hook '%s' must set host and status", h.Name)
+		}
+
+		parsed := hook{
+			states: make(map[string]string, len(h.States)),
+			isHost: strings.TrimSpace(h.Service) == "",
+		}
+
+		var err error
+		for _, e := range []struct {
+			field string
+			text  string
+			dest  *expression
+		}{
+			{"host", h.Host, &parsed.host},
+			{"service", h.Service, &parsed.service},
+			{"status", h.Status, &parsed.status},
+			{"output", h.Output, &parsed.output},
+		} {
+			if *e.dest, err = parseExpression(e.field, e.text); err != nil {
+				return fmt.Errorf("hook '%s' has an invalid %s: %w", h.Name, e.field, err)
+			}
+		}
+
+		for status, state := range h.States {
+			if parsed.isHost {
+				_, err = xdata.ParseHostState([]byte(state))
+			} else {
+				_, err = xdata.ParseServiceState([]byte(state))
+			}
+			if err != nil {
+				return fmt.Errorf("hook '%s' maps '%s' onto an unknown state '%s'", h.Name, status, state)
+			}
+			parsed.states[strings.ToLower(status)] = state
+		}
+
+		if parsed.auth, err = newAuthenticator(h); err != nil {
+			return fmt.Errorf("hook '%s': %w", h.Name, err)
+		}
+
+		s.hooks[h.Name] = &parsed
+		return nil
+	}
+}
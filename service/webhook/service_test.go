@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jamesmichael/nagiosapi/encoding/xdata"
+	"github.com/jamesmichael/nagiosapi/nagios/cmd"
+	"github.com/jamesmichael/nagiosapi/service/submission"
+)
+
+// result is a passive check result passed to a recordingSubmitter. Host
+// results have no ServiceName and a HostState.
+type result struct {
+	Hostname     string
+	ServiceName  string
+	HostState    xdata.HostState
+	ServiceState xdata.ServiceState
+	Output       string
+}
+
+type recordingSubmitter struct {
+	results []result
+}
+
+func (s *recordingSubmitter) SubmitResult(time int64, state xdata.ServiceState, hostname, serviceName, body string) ([]cmd.Delivery, error) {
+	s.results = append(s.results, result{Hostname: hostname, ServiceName: serviceName, ServiceState: state, Output: body})
+	return nil, nil
+}
+
+func (s *recordingSubmitter) SubmitHostResult(time int64, state xdata.HostState, hostname, body string) ([]cmd.Delivery, error) {
+	s.results = append(s.results, result{Hostname: hostname, HostState: state, Output: body})
+	return nil, nil
+}
+
+func TestService_Submit(t *testing.T) {
+	tests := []struct {
+		name     string
+		hook     Hook
+		payload  string
+		expected result
+	}{
+		{
+			"jsonpath",
+			Hook{Host: "$.check.host", Service: "$.check.name", Status: "$.check.state", Output: "$.check.message"},
+			`{"check": {"host": "web01", "name": "HTTP", "state": "critical", "message": "down"}}`,
+			result{Hostname: "web01", ServiceName: "HTTP", ServiceState: xdata.Critical, Output: "down"},
+		},
+		{
+			"template",
+			Hook{
+				Host:    "{{ .monitor.host | lower }}",
+				Service: "{{ .monitor.name }}",
+				Status:  "{{ .monitor.status }}",
+				Output:  "{{ .monitor.name }} is {{ .monitor.status | upper }}",
+			},
+			`{"monitor": {"host": "WEB01", "name": "HTTP", "status": "warning"}}`,
+			result{Hostname: "web01", ServiceName: "HTTP", ServiceState: xdata.Warning, Output: "HTTP is WARNING"},
+		},
+		{
+			"numeric status",
+			Hook{Host: "$.host", Service: "$.service", Status: "$.status"},
+			`{"host": "web01", "service": "HTTP", "status": 2}`,
+			result{Hostname: "web01", ServiceName: "HTTP", ServiceState: xdata.Critical},
+		},
+		{
+			"states",
+			Hook{Host: "$.host", Service: "$.service", Status: "$.status", States: map[string]string{"Up": "ok", "down": "2"}},
+			`{"host": "web01", "service": "HTTP", "status": "UP"}`,
+			result{Hostname: "web01", ServiceName: "HTTP", ServiceState: xdata.Ok},
+		},
+		{
+			"states by code",
+			Hook{Host: "$.host", Service: "$.service", Status: "$.status", States: map[string]string{"up": "ok", "down": "2"}},
+			`{"host": "web01", "service": "HTTP", "status": "down"}`,
+			result{Hostname: "web01", ServiceName: "HTTP", ServiceState: xdata.Critical},
+		},
+		{
+			"host",
+			Hook{Host: "$.host", Status: "{{ if .up }}UP{{ else }}DOWN{{ end }}", Output: "{{ replace \"-\" \" \" .reason }}"},
+			`{"host": "web01", "up": false, "reason": "no-route-to-host"}`,
+			result{Hostname: "web01", HostState: xdata.Down, Output: "no route to host"},
+		},
+		{
+			"object output",
+			Hook{Host: "$.host", Status: "$.status", Output: "$.labels"},
+			`{"host": "web01", "status": "up", "labels": {"team": "ops"}}`,
+			result{Hostname: "web01", HostState: xdata.Up, Output: `{"team":"ops"}`},
+		},
+	}
+
+	for _, test := range tests {
+		sub := &recordingSubmitter{}
+		test.hook.Name = "test"
+		test.hook.Secret = "changeme"
+		svc, err := NewService(WithSubmitter(sub), WithHook(test.hook))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if _, _, err := svc.Submit("test", http.Header{}, "changeme", []byte(test.payload)); err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if len(sub.results) != 1 || sub.results[0] != test.expected {
+			t.Errorf("%s: unexpected results, got: '%v', want: '%v'", test.name, sub.results, test.expected)
+		}
+	}
+}
+
+func TestService_Submit_Invalid(t *testing.T) {
+	hook := Hook{
+		Name:    "test",
+		Host:    "$.host",
+		Service: "{{ .service }}",
+		Status:  "$.status",
+		States:  map[string]string{"up": "ok"},
+		Secret:  "changeme",
+	}
+
+	tests := []struct {
+		name    string
+		hook    string
+		token   string
+		payload string
+		err     error
+		field   string
+	}{
+		{"unknown hook", "other", "changeme", `{}`, ErrUnknownHook, ""},
+		{"bad token", "test", "wrong", `{"host": "web01", "service": "HTTP", "status": "up"}`, ErrUnauthorized, ""},
+		{"not json", "test", "changeme", `{"host":`, ErrInvalidPayload, ""},
+		{"missing path", "test", "changeme", `{"service": "HTTP", "status": "up"}`, submission.ErrInvalidResult, "hostname"},
+		{"missing key", "test", "changeme", `{"host": "web01", "status": "up"}`, submission.ErrInvalidResult, "service_name"},
+		{"unknown state", "test", "changeme", `{"host": "web01", "service": "HTTP", "status": "sideways"}`, submission.ErrInvalidResult, "status"},
+		{"host state", "test", "changeme", `{"host": "web01", "service": "HTTP", "status": "DOWN"}`, submission.ErrInvalidResult, "status"},
+	}
+
+	for _, test := range tests {
+		sub := &recordingSubmitter{}
+		svc, err := NewService(WithSubmitter(sub), WithHook(hook))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		_, _, err = svc.Submit(test.hook, http.Header{}, test.token, []byte(test.payload))
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: unexpected error, got: '%v', want: '%v'", test.name, err, test.err)
+			continue
+		}
+
+		var fe *submission.FieldError
+		if test.field != "" && (!errors.As(err, &fe) || fe.Field != test.field) {
+			t.Errorf("%s: unexpected field, got: '%v', want: '%s'", test.name, err, test.field)
+		}
+
+		if len(sub.results) != 0 {
+			t.Errorf("%s: unexpected results, got: '%v'", test.name, sub.results)
+		}
+	}
+}
+
+func TestWithHook_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		hook Hook
+	}{
+		{"no name", Hook{Host: "$.host", Status: "$.status", Secret: "s"}},
+		{"no host", Hook{Name: "test", Status: "$.status", Secret: "s"}},
+		{"no status", Hook{Name: "test", Host: "$.host", Secret: "s"}},
+		{"bad path", Hook{Name: "test", Host: "$.host[", Status: "$.status", Secret: "s"}},
+		{"bad template", Hook{Name: "test", Host: "{{ .host", Status: "$.status", Secret: "s"}},
+		{"unknown func", Hook{Name: "test", Host: "{{ .host | title }}", Status: "$.status", Secret: "s"}},
+		{"unknown state", Hook{Name: "test", Host: "$.host", Service: "$.service", Status: "$.status", States: map[string]string{"up": "UP"}, Secret: "s"}},
+		{"unknown host state", Hook{Name: "test", Host: "$.host", Status: "$.status", States: map[string]string{"bad": "CRITICAL"}, Secret: "s"}},
+		{"no signature header", Hook{Name: "test", Host: "$.host", Status: "$.status", Signature: Signature{Secret: "s", Algorithm: "sha256"}}},
+		{"no secret or signature", Hook{Name: "test", Host: "$.host", Status: "$.status"}},
+		{"unknown algorithm", Hook{Name: "test", Host: "$.host", Status: "$.status", Signature: Signature{Secret: "s", Header: "X-Sig", Algorithm: "md5"}}},
+	}
+
+	for _, test := range tests {
+		if _, err := NewService(WithSubmitter(&recordingSubmitter{}), WithHook(test.hook)); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+
+	hook := Hook{Name: "test", Host: "$.host", Status: "$.status", Secret: "s"}
+	if _, err := NewService(WithSubmitter(&recordingSubmitter{}), WithHook(hook), WithHook(hook)); err == nil {
+		t.Errorf("expected error for duplicate hook")
+	}
+}